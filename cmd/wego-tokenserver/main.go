@@ -0,0 +1,116 @@
+// wego-tokenserver 中控服务器
+//
+// 从JSON配置文件启动 tokenserver.Server，使用文件存储并在后台主动刷新token：
+//
+//	wego-tokenserver -config tokenserver.json
+//
+// 配置示例：
+//
+//	{
+//	  "listen": ":8080",
+//	  "prefix": "/wego",
+//	  "storage_dir": "/var/lib/wego",
+//	  "official_accounts": [{"app_id": "wx123", "app_secret": "SECRET"}],
+//	  "open_platform": {"component_appid": "wx456", "component_appsecret": "SECRET"},
+//	  "clients": [{"id": "order-service", "secret": "CLIENT_SECRET", "app_ids": ["wx123"]}]
+//	}
+//
+// 第三方平台的component_verify_ticket由接收推送的服务写入同一存储目录。
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/openplatform"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/tokenserver"
+)
+
+// config 配置文件
+type config struct {
+	Listen           string                     `json:"listen"`
+	Prefix           string                     `json:"prefix"`
+	StorageDir       string                     `json:"storage_dir"`
+	OfficialAccounts []*official_account.Config `json:"official_accounts"`
+	OpenPlatform     *openplatform.Config       `json:"open_platform"`
+	Clients          []struct {
+		ID     string   `json:"id"`
+		Secret string   `json:"secret"`
+		AppIDs []string `json:"app_ids"`
+	} `json:"clients"`
+}
+
+func main() {
+	configPath := flag.String("config", "tokenserver.json", "配置文件路径")
+	flag.Parse()
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		log.Fatalf("读取配置文件失败: %v", err)
+	}
+	cfg := config{Listen: ":8080", StorageDir: "./wego_storage"}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Fatalf("解析配置文件失败: %v", err)
+	}
+
+	store, err := storage.NewFileStorage(cfg.StorageDir)
+	if err != nil {
+		log.Fatalf("创建存储失败: %v", err)
+	}
+
+	server := tokenserver.NewServer()
+	var sources []core.RefreshSource
+	for _, accountConfig := range cfg.OfficialAccounts {
+		if err := accountConfig.Validate(); err != nil {
+			log.Fatalf("公众号配置错误: %v", err)
+		}
+		client := official_account.NewMPClientWithStorage(accountConfig, store)
+		server.AddOfficialAccount(client)
+		sources = append(sources, client)
+	}
+	if cfg.OpenPlatform != nil {
+		if err := cfg.OpenPlatform.Validate(); err != nil {
+			log.Fatalf("第三方平台配置错误: %v", err)
+		}
+		client := openplatform.NewClientWithStorage(cfg.OpenPlatform, store)
+		server.SetOpenPlatform(client)
+		sources = append(sources, client)
+	}
+	for _, c := range cfg.Clients {
+		server.AddClient(c.ID, c.Secret, c.AppIDs...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	refresher := core.NewRefresher(func(failure *core.RefreshFailure) {
+		log.Printf("主动刷新 %s %s 失败: %v", failure.Kind, failure.AppID, failure.Err)
+	}, sources...)
+	if err := refresher.Start(ctx); err != nil {
+		log.Fatalf("启动主动刷新失败: %v", err)
+	}
+	defer refresher.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(strings.TrimRight(cfg.Prefix, "/")+"/", server)
+	httpServer := &http.Server{Addr: cfg.Listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("中控服务器监听 %s", cfg.Listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("中控服务器退出: %v", err)
+	}
+}
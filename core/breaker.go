@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送到微信
+var ErrCircuitOpen = errors.New("接口熔断中")
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭：正常放行请求
+	CircuitOpen                         // 打开：直接拒绝请求
+	CircuitHalfOpen                     // 半开：放行一个探测请求，成功后关闭，失败后重新打开
+)
+
+// String 返回状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitOpenError 熔断错误，可通过 errors.Is 匹配 ErrCircuitOpen
+type CircuitOpenError struct {
+	Endpoint   string        // 接口地址
+	RetryAfter time.Duration // 距离进入半开状态的时间
+}
+
+// Error 实现error接口
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s，%s 后重试", ErrCircuitOpen.Error(), e.Endpoint, e.RetryAfter)
+}
+
+// Is 支持 errors.Is 匹配 ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitStateFunc 熔断器状态变化回调
+type CircuitStateFunc func(endpoint string, from, to CircuitState)
+
+// CircuitBreaker 按接口熔断的熔断器
+// 同一接口连续出现网络错误、5xx响应或系统繁忙达到阈值后打开，冷却时间过后进入半开状态进行探测
+// 微信返回的业务错误码（如参数错误）不计入失败
+type CircuitBreaker struct {
+	mu            sync.Mutex
+	threshold     int
+	cooldown      time.Duration
+	onStateChange CircuitStateFunc
+	circuits      map[string]*circuit
+}
+
+// circuit 单个接口的熔断状态
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker 创建熔断器
+// @param threshold int 连续失败多少次后打开，默认5
+// @param cooldown time.Duration 打开后多久进入半开状态，默认30s
+// @param onStateChange CircuitStateFunc 状态变化回调，可为nil
+// @return *CircuitBreaker 熔断器
+func NewCircuitBreaker(threshold int, cooldown time.Duration, onStateChange CircuitStateFunc) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		threshold:     threshold,
+		cooldown:      cooldown,
+		onStateChange: onStateChange,
+		circuits:      make(map[string]*circuit),
+	}
+}
+
+// Allow 判断是否放行请求
+// @param endpoint string 接口地址
+// @return error 熔断时返回 *CircuitOpenError
+func (b *CircuitBreaker) Allow(endpoint string) error {
+	b.mu.Lock()
+	c := b.circuit(endpoint)
+
+	var changed bool
+	switch c.state {
+	case CircuitOpen:
+		if wait := b.cooldown - time.Since(c.openedAt); wait > 0 {
+			b.mu.Unlock()
+			return &CircuitOpenError{Endpoint: endpoint, RetryAfter: wait}
+		}
+		c.state = CircuitHalfOpen
+		c.probing = true
+		changed = true
+	case CircuitHalfOpen:
+		if c.probing {
+			b.mu.Unlock()
+			return &CircuitOpenError{Endpoint: endpoint}
+		}
+		c.probing = true
+	}
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(endpoint, CircuitOpen, CircuitHalfOpen)
+	}
+	return nil
+}
+
+// Record 记录请求结果，仅网络错误、5xx响应和系统繁忙计为失败
+// 每次 Allow 放行的请求都需要调用 Record，否则半开状态下不会再放行探测请求
+// @param endpoint string 接口地址
+// @param err error 请求结果
+func (b *CircuitBreaker) Record(endpoint string, err error) {
+	failed := isTransientError(err)
+
+	b.mu.Lock()
+	c := b.circuit(endpoint)
+	from := c.state
+	c.probing = false
+	if errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, context.Canceled) {
+		// 被本地限流拒绝或被调用方取消的请求不影响熔断状态
+		b.mu.Unlock()
+		return
+	}
+	if failed {
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= b.threshold {
+			c.state = CircuitOpen
+			c.openedAt = time.Now()
+		}
+	} else {
+		c.failures = 0
+		c.state = CircuitClosed
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(endpoint, from, to)
+	}
+}
+
+// State 获取接口当前的熔断状态
+func (b *CircuitBreaker) State(endpoint string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[endpoint]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// circuit 获取接口的熔断状态，调用方需持有锁
+func (b *CircuitBreaker) circuit(endpoint string) *circuit {
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	return c
+}
+
+// notify 触发状态变化回调
+func (b *CircuitBreaker) notify(endpoint string, from, to CircuitState) {
+	if b.onStateChange != nil {
+		b.onStateChange(endpoint, from, to)
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// 携带token的查询参数名
+const (
+	ParamAccessToken          = "access_token"           // 公众号或授权方的access_token
+	ParamComponentAccessToken = "component_access_token" // 第三方平台的component_access_token
+)
+
+// Caller 接口调用方，负责提供token和发送请求
+//
+// 公众号客户端提供公众号的access_token，第三方平台客户端提供component_access_token，
+// 授权方客户端提供授权方的access_token，同一个 Endpoint 可以通过不同的调用方调用
+type Caller interface {
+	// Token 返回查询参数 param 对应的token，param 取值见 ParamAccessToken 等常量
+	Token(ctx context.Context, param string) (string, error)
+
+	// Make 发送请求，应用调用方配置的中间件、限流、重试、熔断、指标和链路追踪
+	Make(ctx context.Context, options *ReqMakeOpt) error
+}
+
+// Downloader 支持下载媒体文件的接口调用方，供 CallDownload 使用
+// 公众号客户端、第三方平台客户端和授权方客户端均已实现
+type Downloader interface {
+	Caller
+
+	// Download 发送请求并以流的方式返回响应内容，处理方式见 Request.Download
+	Download(ctx context.Context, options *ReqMakeOpt) (*Download, error)
+}
+
+// NoParams 表示接口没有请求参数，POST请求不发送请求体
+type NoParams struct{}
+
+// Endpoint 接口描述，Req 为请求参数类型，Resp 为响应类型
+//
+// 请求参数的处理方式：
+//   - GET请求的参数转换为查询参数
+//   - POST请求的参数序列化为JSON请求体
+//   - 参数为 *Multipart 时以multipart/form-data上传
+//   - 参数为 NoParams 时不发送参数
+//
+// 调用未封装的接口：
+//
+//	endpoint := core.Endpoint[MyRequest, MyResponse]{Method: "POST", URL: core.BaseAPIURL + "/cgi-bin/xxx", Token: core.ParamAccessToken}
+//	resp, err := core.Call(ctx, client, endpoint, request)
+type Endpoint[Req, Resp any] struct {
+	Method string // HTTP方法，为空时使用GET
+	URL    string // 接口地址，可以包含固定的查询参数
+	Token  string // 携带token的查询参数名，为空表示接口不需要token
+}
+
+// WithQuery 返回附加了查询参数的接口描述，用于请求体之外还需要查询参数的接口
+func (e Endpoint[Req, Resp]) WithQuery(params map[string]string) Endpoint[Req, Resp] {
+	parsedURL, err := url.Parse(e.URL)
+	if err != nil {
+		return e
+	}
+	q := parsedURL.Query()
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	parsedURL.RawQuery = q.Encode()
+	e.URL = parsedURL.String()
+	return e
+}
+
+// Call 调用接口
+// 按接口描述获取token并附加到查询参数，微信错误码检查、token失效重试、重试、限流和指标由调用方的 Make 统一处理
+// @param ctx context.Context 请求上下文
+// @param caller Caller 接口调用方，如公众号客户端、第三方平台客户端
+// @param endpoint Endpoint[Req, Resp] 接口描述
+// @param req Req 请求参数
+// @return *Resp 响应结果
+// @return error 获取token失败或请求失败，微信返回非0错误码时为 *Error
+func Call[Req, Resp any](ctx context.Context, caller Caller, endpoint Endpoint[Req, Resp], req Req) (*Resp, error) {
+	options, err := callOptions(ctx, caller, endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Resp
+	options.Result = &result
+	if err := caller.Make(ctx, options); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CallDownload 调用下载媒体文件的接口
+// 按接口描述获取token并附加到查询参数，请求参数的处理方式与 Call 相同，响应以流的方式返回
+// @param ctx context.Context 请求上下文
+// @param caller Downloader 接口调用方，如公众号客户端、授权方客户端
+// @param endpoint Endpoint[Req, Download] 接口描述
+// @param req Req 请求参数
+// @return *Download 下载结果，使用完毕后必须关闭
+// @return error 获取token失败或请求失败，微信返回非0错误码时为 *Error
+func CallDownload[Req any](ctx context.Context, caller Downloader, endpoint Endpoint[Req, Download], req Req) (*Download, error) {
+	options, err := callOptions(ctx, caller, endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+	return caller.Download(ctx, options)
+}
+
+// callOptions 按接口描述构建请求参数，获取token并附加到查询参数
+func callOptions[Req, Resp any](ctx context.Context, caller Caller, endpoint Endpoint[Req, Resp], req Req) (*ReqMakeOpt, error) {
+	method := endpoint.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	query := make(map[string]string)
+	options := &ReqMakeOpt{Method: method, URL: endpoint.URL, Query: query}
+	if endpoint.Token != "" {
+		token, err := caller.Token(ctx, endpoint.Token)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("%s为空", endpoint.Token)
+		}
+		query[endpoint.Token] = token
+	}
+
+	switch params := any(req).(type) {
+	case nil, NoParams:
+	case *Multipart:
+		options.Multipart = params
+	default:
+		if method == http.MethodGet {
+			if err := addQuery(query, params); err != nil {
+				return nil, err
+			}
+		} else {
+			options.Body = params
+		}
+	}
+
+	return options, nil
+}
+
+// addQuery 将请求参数按JSON字段名转换为查询参数，忽略值为null的字段
+func addQuery(query map[string]string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("序列化查询参数失败: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return fmt.Errorf("查询参数必须是结构体或map: %w", err)
+	}
+	for key, value := range fields {
+		if value != nil {
+			query[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+// testCaller 固定返回token的调用方
+type testCaller struct {
+	*Request
+	tokens map[string]string
+}
+
+func (c *testCaller) Token(ctx context.Context, param string) (string, error) {
+	token, ok := c.tokens[param]
+	if !ok {
+		return "", fmt.Errorf("不支持 %s", param)
+	}
+	return token, nil
+}
+
+type callQuery struct {
+	OpenID string `json:"openid"`
+	Count  int    `json:"count"`
+	Next   *string
+}
+
+type callResult struct {
+	APIResponse
+	Echo string `json:"echo"`
+}
+
+func TestCallInjectsTokenAndQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("access_token") != "tok" || q.Get("lang") != "zh_CN" || q.Get("openid") != "o1" || q.Get("count") != "10" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		if q.Has("Next") {
+			t.Errorf("nil field should be omitted: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":0,"echo":"` + q.Get("openid") + `"}`))
+	}))
+	defer srv.Close()
+
+	caller := &testCaller{
+		Request: NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface()),
+		tokens:  map[string]string{ParamAccessToken: "tok"},
+	}
+	endpoint := Endpoint[callQuery, callResult]{URL: srv.URL + "/cgi-bin/user/info", Token: ParamAccessToken}.
+		WithQuery(map[string]string{"lang": "zh_CN"})
+
+	result, err := Call(context.Background(), caller, endpoint, callQuery{OpenID: "o1", Count: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Echo != "o1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// 调用方不支持的token直接返回错误，不发送请求
+	other := Endpoint[NoParams, callResult]{Method: "POST", URL: srv.URL, Token: ParamComponentAccessToken}
+	if _, err := Call(context.Background(), caller, other, NoParams{}); err == nil {
+		t.Fatal("expected token error")
+	}
+}
+
+func TestCallReturnsTypedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":48001,"errmsg":"api unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	caller := &testCaller{
+		Request: NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface()),
+		tokens:  map[string]string{ParamAccessToken: "tok"},
+	}
+	endpoint := Endpoint[map[string]string, callResult]{Method: "POST", URL: srv.URL, Token: ParamAccessToken}
+
+	_, err := Call(context.Background(), caller, endpoint, map[string]string{"touser": "o1"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.ErrCode != 48001 {
+		t.Fatalf("expected errcode 48001, got: %v", err)
+	}
+}
+
+func TestCallDownloadInjectsTokenAndQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("access_token") != "tok" || q.Get("media_id") != "m 1" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "audio/amr")
+		_, _ = w.Write([]byte("voice"))
+	}))
+	defer srv.Close()
+
+	caller := &testCaller{
+		Request: NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface()),
+		tokens:  map[string]string{ParamAccessToken: "tok"},
+	}
+	endpoint := Endpoint[map[string]string, Download]{URL: srv.URL + "/cgi-bin/media/get", Token: ParamAccessToken}
+
+	download, err := CallDownload(context.Background(), caller, endpoint, map[string]string{"media_id": "m 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer download.Close()
+
+	content, _ := io.ReadAll(download)
+	if string(content) != "voice" || download.ContentType != "audio/amr" {
+		t.Fatalf("unexpected download: %+v %q", download, content)
+	}
+}
@@ -3,6 +3,7 @@ package core
 // API相关公共常量
 const (
 	// 基础错误码
+	ErrCodeSystemBusy        = -1
 	ErrCodeSuccess           = 0
 	ErrCodeInvalidCredential = 40001
 	ErrCodeInvalidGrantType  = 40002
@@ -15,6 +16,11 @@ const (
 	ErrCodeAccessDenied      = 48004
 	ErrCodeAPIQuotaExceeded  = 45009
 
+	ErrCodeInvalidAccessToken      = 40014 // 不合法的access_token
+	ErrCodeAccessTokenExpired      = 42001 // access_token超时
+	ErrCodeAPIMinuteQuotaExceeded  = 45011 // API调用太频繁
+	ErrCodeOutOfResponseCountLimit = 45047 // 客服接口下行条数超过上限
+
 	// 基础API域名
 	BaseAPIURL       = "https://api.weixin.qq.com"
 	OpenBaseURL      = "https://open.weixin.qq.com"
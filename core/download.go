@@ -0,0 +1,186 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxJSONDownloadSize 下载接口返回JSON时，用于识别错误码和下载地址读取的最大长度
+// 超出该长度的JSON是图文素材等内容，不做识别，以流的方式返回完整内容
+const maxJSONDownloadSize = 1 << 20
+
+// Download 媒体文件下载结果
+// 内容以流的方式返回，使用完毕后必须调用 Close 关闭
+type Download struct {
+	Body        io.ReadCloser // 文件内容
+	ContentType string        // 内容类型，如 image/jpeg
+	FileName    string        // 文件名，从Content-Disposition解析，可能为空
+	Size        int64         // 内容长度，未知时为-1
+}
+
+// Read 读取文件内容
+func (d *Download) Read(p []byte) (int, error) {
+	return d.Body.Read(p)
+}
+
+// Close 关闭文件内容
+func (d *Download) Close() error {
+	return d.Body.Close()
+}
+
+// IsJSON 判断下载内容是否为JSON，如图文素材返回的 news_item
+func (d *Download) IsJSON() bool {
+	return isJSONContentType(d.ContentType)
+}
+
+// downloadURLResponse 视频素材返回的下载地址
+type downloadURLResponse struct {
+	DownURL  string `json:"down_url"`  // 永久视频素材
+	VideoURL string `json:"video_url"` // 临时视频素材
+}
+
+// Download 下载媒体文件，以流的方式返回内容
+//
+// 参数:
+//   - ctx: 请求上下文
+//   - options: 请求配置选项，Result 会被忽略
+//
+// 返回:
+//   - *Download: 下载结果，调用方负责关闭
+//   - error: 微信返回JSON错误时为 *Error
+//
+// 功能:
+//   - 响应为JSON时识别微信错误码，返回 *Error，token失效时自动刷新并重试一次
+//   - 视频素材返回 down_url 或 video_url 时自动跟随下载地址
+//   - 其他JSON内容（如图文素材）原样返回
+func (r *Request) Download(ctx context.Context, options *ReqMakeOpt) (*Download, error) {
+	requestURL, err := buildURL(options)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, options, requestURL)
+	var download *Download
+	err = r.withTokenRetry(ctx, requestURL, options, func(requestURL string) error {
+		return r.withRetry(ctx, requestURL, options, func() error {
+			return r.withFailover(ctx, requestURL, options, func(requestURL string) error {
+				download, err = r.download(ctx, requestURL, options)
+				return err
+			})
+		})
+	})
+	endSpan(span, err)
+	r.observe(options, requestURL, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return download, nil
+}
+
+// download 执行一次下载请求
+func (r *Request) download(ctx context.Context, requestURL string, options *ReqMakeOpt) (*Download, error) {
+	resp, err := r.send(ctx, requestURL, options)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && !isJSONContentType(contentType) {
+		r.logger.Info(fmt.Sprintf("HTTP响应 - 状态码: %d, 内容类型: %s, 内容长度: %d", resp.StatusCode, contentType, resp.ContentLength), map[string]interface{}{
+			"status_code":     resp.StatusCode,
+			"content_type":    contentType,
+			"response_length": resp.ContentLength,
+		})
+		return newDownload(resp), nil
+	}
+
+	// JSON响应：错误信息、视频下载地址或图文素材内容
+	probe, err := io.ReadAll(io.LimitReader(resp.Body, maxJSONDownloadSize+1))
+	if err != nil {
+		r.closeBody(resp.Body)
+		return nil, fmt.Errorf("读取响应体失败: %v", err)
+	}
+	if len(probe) > maxJSONDownloadSize && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		// 错误信息和下载地址不会这么长，已读取的部分与剩余内容一起返回
+		r.logger.Info(fmt.Sprintf("HTTP响应 - 状态码: %d, 内容类型: %s, 内容长度: %d", resp.StatusCode, contentType, resp.ContentLength), map[string]interface{}{
+			"status_code":     resp.StatusCode,
+			"content_type":    contentType,
+			"response_length": resp.ContentLength,
+		})
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(probe), resp.Body), resp.Body}
+		return newDownload(resp), nil
+	}
+
+	defer r.closeBody(resp.Body)
+	if len(probe) > maxJSONDownloadSize {
+		probe = probe[:maxJSONDownloadSize]
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(probe))
+	respBody, err := r.readBody(resp, requestURL)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.checkResponse(resp.StatusCode, respBody, requestURL); err != nil {
+		return nil, err
+	}
+
+	var urls downloadURLResponse
+	if json.Unmarshal(respBody, &urls) == nil {
+		if downURL := urls.DownURL + urls.VideoURL; downURL != "" {
+			return r.followDownload(ctx, downURL)
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	download := newDownload(resp)
+	download.Size = int64(len(respBody))
+	return download, nil
+}
+
+// followDownload 跟随视频素材的下载地址，下载地址不需要携带access_token
+func (r *Request) followDownload(ctx context.Context, downURL string) (*Download, error) {
+	resp, err := r.send(ctx, downURL, &ReqMakeOpt{Method: http.MethodGet, URL: downURL})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer r.closeBody(resp.Body)
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxJSONDownloadSize))
+		return nil, r.checkResponse(resp.StatusCode, respBody, downURL)
+	}
+	return newDownload(resp), nil
+}
+
+// newDownload 从HTTP响应构建下载结果
+func newDownload(resp *http.Response) *Download {
+	download := &Download{
+		Body:        resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		download.FileName = params["filename"]
+	}
+	return download
+}
+
+// isJSONContentType 判断内容类型是否为JSON
+// 部分微信接口返回错误时使用 text/plain，同样按JSON处理
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(mediaType, "json") || mediaType == "text/plain"
+}
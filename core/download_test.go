@@ -0,0 +1,82 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestDownloadFollowsDownURL(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cgi-bin/material/get_material":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"title":"t","down_url":"` + srv.URL + `/video.mp4"}`))
+		case "/video.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("Content-Disposition", `attachment; filename="m1.mp4"`)
+			_, _ = w.Write([]byte("video"))
+		}
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	download, err := req.Download(context.Background(), &ReqMakeOpt{
+		Method: "POST",
+		URL:    srv.URL + "/cgi-bin/material/get_material?access_token=t",
+		Body:   map[string]string{"media_id": "m1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer download.Close()
+
+	content, _ := io.ReadAll(download)
+	if string(content) != "video" || download.FileName != "m1.mp4" || download.ContentType != "video/mp4" || download.Size != 5 {
+		t.Fatalf("unexpected download: %+v %q", download, content)
+	}
+}
+
+func TestDownloadJSONError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(`{"errcode":40007,"errmsg":"invalid media_id"}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	_, err := req.Download(context.Background(), &ReqMakeOpt{Method: "GET", URL: srv.URL + "/cgi-bin/media/get"})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.ErrCode != 40007 {
+		t.Fatalf("expected *Error with errcode 40007, got: %v", err)
+	}
+}
+
+func TestDownloadLargeJSONNotTruncated(t *testing.T) {
+	body := []byte(`{"news_item":[{"content":"` + string(bytes.Repeat([]byte("a"), maxJSONDownloadSize)) + `"}]}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	download, err := req.Download(context.Background(), &ReqMakeOpt{Method: "POST", URL: srv.URL + "/cgi-bin/material/get_material"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer download.Close()
+
+	content, _ := io.ReadAll(download)
+	if !bytes.Equal(content, body) || !download.IsJSON() {
+		t.Fatalf("expected the full %d byte body, got %d bytes", len(body), len(content))
+	}
+}
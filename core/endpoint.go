@@ -0,0 +1,248 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackupAPIURLs 微信公布的备用API域名，按故障转移顺序排列
+var BackupAPIURLs = []string{
+	"https://api2.weixin.qq.com",
+	"https://sh.api.weixin.qq.com",
+	"https://sz.api.weixin.qq.com",
+	"https://hk.api.weixin.qq.com",
+}
+
+// EndpointResolver 接口地址解析器，用于替换请求域名和故障转移
+type EndpointResolver interface {
+	// Resolve 返回请求可使用的基础地址（如 https://api.weixin.qq.com），按优先级排列
+	// 返回空列表表示不处理该请求，按原URL发送
+	Resolve(requestURL *url.URL) []string
+
+	// Report 报告使用某个基础地址的请求结果，用于切换和恢复域名
+	Report(baseURL string, err error)
+}
+
+// DomainSwitchFunc 域名切换回调
+type DomainSwitchFunc func(from, to string)
+
+// DomainResolver 微信API域名解析器
+// 将发往 BaseAPIURL 的请求改写到当前可用域名；连接失败或5xx时依次尝试备用域名并切换，
+// 切换后定期探测主域名，恢复后切回主域名
+//
+// 也可用于测试，将所有客户端的请求指向本地模拟服务器：
+//
+//	resolver := core.NewDomainResolver(fakeServer.URL)
+type DomainResolver struct {
+	mu            sync.Mutex
+	origin        string   // 需要改写的原始地址
+	baseURLs      []string // 主域名和备用域名
+	active        int      // 当前使用的域名下标
+	switchedAt    time.Time
+	probing       bool
+	probeInterval time.Duration
+	httpClient    HTTPClient
+	onSwitch      DomainSwitchFunc
+}
+
+// NewDomainResolver 创建域名解析器
+// @param baseURL string 主域名，为空时使用 BaseAPIURL
+// @param backupURLs ...string 备用域名，按故障转移顺序排列，可传入 BackupAPIURLs...
+// @return *DomainResolver 域名解析器
+func NewDomainResolver(baseURL string, backupURLs ...string) *DomainResolver {
+	if baseURL == "" {
+		baseURL = BaseAPIURL
+	}
+	baseURLs := []string{strings.TrimRight(baseURL, "/")}
+	for _, backup := range backupURLs {
+		baseURLs = append(baseURLs, strings.TrimRight(backup, "/"))
+	}
+	return &DomainResolver{
+		origin:        BaseAPIURL,
+		baseURLs:      baseURLs,
+		probeInterval: 30 * time.Second,
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetProbeInterval 设置切换到备用域名后探测主域名的间隔，默认30s
+// @return *DomainResolver 域名解析器，便于链式调用
+func (d *DomainResolver) SetProbeInterval(interval time.Duration) *DomainResolver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.probeInterval = interval
+	return d
+}
+
+// SetHTTPClient 设置探测主域名使用的HTTP客户端
+// @return *DomainResolver 域名解析器，便于链式调用
+func (d *DomainResolver) SetHTTPClient(client HTTPClient) *DomainResolver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.httpClient = client
+	return d
+}
+
+// OnSwitch 设置域名切换回调
+// @return *DomainResolver 域名解析器，便于链式调用
+func (d *DomainResolver) OnSwitch(fn DomainSwitchFunc) *DomainResolver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onSwitch = fn
+	return d
+}
+
+// Active 返回当前使用的域名
+func (d *DomainResolver) Active() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.baseURLs[d.active]
+}
+
+// BaseURLs 返回主域名和备用域名
+func (d *DomainResolver) BaseURLs() []string {
+	return append([]string(nil), d.baseURLs...)
+}
+
+// Resolve 实现 EndpointResolver 接口，当前域名排在首位
+func (d *DomainResolver) Resolve(requestURL *url.URL) []string {
+	if requestURL.Scheme+"://"+requestURL.Host != d.origin {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.active != 0 && !d.probing && time.Since(d.switchedAt) >= d.probeInterval {
+		d.probing = true
+		go d.probePrimary()
+	}
+
+	candidates := make([]string, 0, len(d.baseURLs))
+	candidates = append(candidates, d.baseURLs[d.active])
+	for i, baseURL := range d.baseURLs {
+		if i != d.active {
+			candidates = append(candidates, baseURL)
+		}
+	}
+	return candidates
+}
+
+// Report 实现 EndpointResolver 接口
+// 当前域名故障时切换到下一个域名，备用域名请求成功时固定使用该域名
+func (d *DomainResolver) Report(baseURL string, err error) {
+	d.mu.Lock()
+	index := d.indexOf(baseURL)
+	if index < 0 {
+		d.mu.Unlock()
+		return
+	}
+
+	from := d.baseURLs[d.active]
+	switch {
+	case err != nil && IsFailoverError(err) && index == d.active:
+		d.active = (d.active + 1) % len(d.baseURLs)
+	case err == nil && index != d.active:
+		d.active = index
+	default:
+		d.mu.Unlock()
+		return
+	}
+	d.switchedAt = time.Now()
+	to := d.baseURLs[d.active]
+	onSwitch := d.onSwitch
+	d.mu.Unlock()
+
+	if onSwitch != nil && from != to {
+		onSwitch(from, to)
+	}
+}
+
+// probePrimary 探测主域名是否恢复
+// 任何非5xx的HTTP响应都说明主域名可用，不需要携带access_token
+func (d *DomainResolver) probePrimary() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURLs[0]+"/cgi-bin/get_api_domain_ip", nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = d.httpClient.Do(req); err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode >= 500 {
+				err = &Error{HTTPStatus: resp.StatusCode, Endpoint: d.baseURLs[0]}
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.probing = false
+	d.switchedAt = time.Now()
+	d.mu.Unlock()
+
+	if err == nil {
+		d.Report(d.baseURLs[0], nil)
+	}
+}
+
+// indexOf 查找域名下标，调用方需持有锁
+func (d *DomainResolver) indexOf(baseURL string) int {
+	for i, u := range d.baseURLs {
+		if u == baseURL {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsFailoverError 判断错误是否需要切换域名：网络错误或5xx响应
+func IsFailoverError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrCode == 0 && apiErr.HTTPStatus >= 500
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// isDialError 判断是否为建立连接失败，此时请求未发送到服务器
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// rebase 将请求URL的协议和域名替换为指定的基础地址
+func rebase(requestURL *url.URL, baseURL string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	rebased := *requestURL
+	rebased.Scheme = base.Scheme
+	rebased.Host = base.Host
+	rebased.Path = strings.TrimRight(base.Path, "/") + requestURL.Path
+	if requestURL.RawPath != "" {
+		rebased.RawPath = strings.TrimRight(base.Path, "/") + requestURL.RawPath
+	}
+	return rebased.String(), nil
+}
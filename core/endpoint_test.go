@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestDomainResolverFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var path string
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.RequestURI()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer backup.Close()
+
+	var switched []string
+	resolver := NewDomainResolver(down.URL, backup.URL).
+		SetProbeInterval(time.Hour).
+		OnSwitch(func(from, to string) { switched = append(switched, to) })
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetEndpointResolver(resolver)
+
+	// 连接失败时非GET请求同样可以切换域名
+	err := req.Make(context.Background(), &ReqMakeOpt{
+		Method: "POST",
+		URL:    BaseAPIURL + "/cgi-bin/message/custom/send?access_token=TOKEN",
+		Body:   map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("expected failover to succeed, got: %v", err)
+	}
+	if path != "/cgi-bin/message/custom/send?access_token=TOKEN" {
+		t.Fatalf("unexpected rewritten path: %s", path)
+	}
+	if resolver.Active() != backup.URL || len(switched) != 1 || switched[0] != backup.URL {
+		t.Fatalf("expected switch to backup, active=%s switched=%v", resolver.Active(), switched)
+	}
+
+	// 其他域名的请求不改写
+	foreign, _ := url.Parse(backup.URL + "/x")
+	if candidates := resolver.Resolve(foreign); candidates != nil {
+		t.Fatalf("foreign host should not be resolved: %v", candidates)
+	}
+}
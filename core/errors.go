@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// 错误分类，配合 errors.Is 使用，例如：
+//
+//	if errors.Is(err, core.ErrTokenInvalid) { ... }
+var (
+	ErrTokenInvalid  = errors.New("access_token无效或已过期")
+	ErrQuotaExceeded = errors.New("接口调用次数超过限制")
+	ErrRateLimited   = errors.New("接口调用频率超过限制")
+	ErrInvalidOpenID = errors.New("不合法的OpenID")
+	ErrSystemBusy    = errors.New("微信系统繁忙")
+)
+
+// ridPattern 从errmsg中提取微信返回的rid，如 "invalid credential rid: 64f1c2a3-1b2c3d4e-5f6a7b8c"
+var ridPattern = regexp.MustCompile(`rid:\s*([0-9A-Za-z\-]+)`)
+
+// Error 微信API错误
+//
+// 所有客户端在微信返回非0错误码或HTTP状态码异常时返回该类型，
+// 调用方可通过 errors.As 获取错误详情，或通过 errors.Is 判断错误分类。
+type Error struct {
+	ErrCode    int    // 微信错误码
+	ErrMsg     string // 微信错误描述
+	Rid        string // 微信请求ID，用于向微信反馈问题
+	Endpoint   string // 接口地址，不包含查询参数
+	HTTPStatus int    // HTTP状态码
+}
+
+// NewError 根据API响应创建错误
+// @param resp API响应
+// @param endpoint 接口地址，查询参数会被去除以免泄露access_token
+// @param httpStatus HTTP状态码
+// @return *Error 微信API错误
+func NewError(resp *APIResponse, endpoint string, httpStatus int) *Error {
+	e := &Error{
+		ErrCode:    resp.ErrCode,
+		ErrMsg:     resp.ErrMsg,
+		Endpoint:   stripQuery(endpoint),
+		HTTPStatus: httpStatus,
+	}
+	if m := ridPattern.FindStringSubmatch(resp.ErrMsg); len(m) == 2 {
+		e.Rid = m[1]
+	}
+	return e
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("微信API错误[%d]: %s", e.ErrCode, e.ErrMsg)
+	if e.ErrCode == 0 && e.HTTPStatus != 0 {
+		msg = fmt.Sprintf("HTTP请求失败 - 状态码: %d, 响应: %s", e.HTTPStatus, e.ErrMsg)
+	}
+	if e.Endpoint != "" {
+		msg += fmt.Sprintf(" (接口: %s)", e.Endpoint)
+	}
+	return msg
+}
+
+// Is 支持 errors.Is 判断错误分类
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrTokenInvalid:
+		return IsTokenInvalidCode(e.ErrCode)
+	case ErrQuotaExceeded:
+		return e.ErrCode == ErrCodeAPIQuotaExceeded
+	case ErrRateLimited:
+		return e.ErrCode == ErrCodeAPIMinuteQuotaExceeded || e.ErrCode == ErrCodeOutOfResponseCountLimit
+	case ErrInvalidOpenID:
+		return e.ErrCode == ErrCodeInvalidOpenID
+	case ErrSystemBusy:
+		return e.ErrCode == ErrCodeSystemBusy
+	}
+	return false
+}
+
+// IsTokenInvalidCode 判断错误码是否表示access_token无效或已过期
+func IsTokenInvalidCode(code int) bool {
+	switch code {
+	case ErrCodeInvalidCredential, ErrCodeInvalidAccessToken, ErrCodeAccessTokenExpired:
+		return true
+	}
+	return false
+}
+
+// stripQuery 去除URL中的查询参数
+func stripQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,49 @@
+package core
+
+import "time"
+
+// ExpiryPolicy 凭证提前过期策略
+//
+// 缓存的凭证（access_token、component_access_token、预授权码、ticket、网页授权access_token等）
+// 在微信返回的有效期结束前提前视为过期，避免下发只剩几毫秒的凭证、请求在途时凭证过期，以及多台机器时钟偏差导致误判。
+// 提前量取 Margin 和有效期乘以 Ratio 中的较大值，且不超过有效期的一半；零值表示不提前。
+type ExpiryPolicy struct {
+	Margin time.Duration // 固定提前量
+	Ratio  float64       // 按有效期比例计算的提前量，如0.05表示提前有效期的5%
+}
+
+// DefaultExpiryPolicy 返回默认提前过期策略：提前5分钟或有效期的5%，取较大值
+func DefaultExpiryPolicy() *ExpiryPolicy {
+	return &ExpiryPolicy{
+		Margin: 5 * time.Minute,
+		Ratio:  0.05,
+	}
+}
+
+// ExpiresAt 计算凭证提前过期后的截止时间
+// @param start time.Time 发起获取凭证请求的时间，请求耗时计入有效期
+// @param expiresIn int 微信返回的有效期，单位秒
+// @return time.Time 截止时间，策略为nil时使用默认策略
+func (p *ExpiryPolicy) ExpiresAt(start time.Time, expiresIn int) time.Time {
+	lifetime := time.Duration(expiresIn) * time.Second
+	return start.Add(lifetime - p.margin(lifetime))
+}
+
+// margin 返回有效期为lifetime的凭证的提前量
+func (p *ExpiryPolicy) margin(lifetime time.Duration) time.Duration {
+	if p == nil {
+		p = DefaultExpiryPolicy()
+	}
+
+	margin := p.Margin
+	if ratio := time.Duration(float64(lifetime) * p.Ratio); ratio > margin {
+		margin = ratio
+	}
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	if margin < 0 {
+		margin = 0
+	}
+	return margin
+}
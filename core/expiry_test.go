@@ -0,0 +1,27 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiryPolicyExpiresAt(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		policy    *ExpiryPolicy
+		expiresIn int
+		want      time.Duration
+	}{
+		{"默认策略按比例提前", nil, 7200, 7200*time.Second - 6*time.Minute},
+		{"默认策略固定提前", nil, 3600, 55 * time.Minute},
+		{"短有效期最多提前一半", nil, 120, time.Minute},
+		{"零值不提前", &ExpiryPolicy{}, 7200, 2 * time.Hour},
+		{"自定义提前量", &ExpiryPolicy{Margin: 30 * time.Second}, 7200, 2*time.Hour - 30*time.Second},
+	}
+	for _, c := range cases {
+		if got := c.policy.ExpiresAt(start, c.expiresIn).Sub(start); got != c.want {
+			t.Errorf("%s: expected %s, got %s", c.name, c.want, got)
+		}
+	}
+}
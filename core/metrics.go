@@ -0,0 +1,166 @@
+package core
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标名称，标签说明见各常量注释
+const (
+	// MetricRequestDuration 接口调用耗时（秒），直方图，标签：endpoint、method、errcode
+	MetricRequestDuration = "wego_request_duration_seconds"
+	// MetricRequestErrors 接口调用错误次数，计数器，标签：endpoint、errcode
+	MetricRequestErrors = "wego_request_errors_total"
+	// MetricTokenRefresh token刷新次数，计数器，标签：kind、result
+	MetricTokenRefresh = "wego_token_refresh_total"
+	// MetricStorageDuration 存储操作耗时（秒），直方图，标签：backend、operation、result
+	MetricStorageDuration = "wego_storage_duration_seconds"
+	// MetricCallbackDuration 回调处理耗时（秒），直方图，标签：source、type、result
+	MetricCallbackDuration = "wego_callback_duration_seconds"
+	// MetricCallbackDecryptFailures 回调消息解密失败次数，计数器，标签：source
+	MetricCallbackDecryptFailures = "wego_callback_decrypt_failures_total"
+)
+
+// token类型，用于 MetricTokenRefresh 的kind标签和 RefreshKey
+const (
+	TokenKindAccessToken           = "access_token"
+	TokenKindStableAccessToken     = "stable_access_token"
+	TokenKindComponentAccessToken  = "component_access_token"
+	TokenKindAuthorizerToken       = "authorizer_access_token"
+	TokenKindPreAuthCode           = "pre_auth_code"
+	TokenKindJSAPITicket           = "jsapi_ticket"
+	TokenKindCardTicket            = "wx_card_ticket"
+	TokenKindComponentVerifyTicket = "component_verify_ticket"
+)
+
+// Labels 指标标签
+type Labels map[string]string
+
+// Metrics 指标收集接口，可对接Prometheus、StatsD等监控系统
+// 实现需要支持并发调用
+type Metrics interface {
+	// IncCounter 计数器加1
+	IncCounter(name string, labels Labels)
+
+	// ObserveHistogram 记录一次直方图观测值，耗时类指标单位为秒
+	ObserveHistogram(name string, value float64, labels Labels)
+}
+
+// NopMetrics 不做任何处理的指标收集器，未设置指标收集器时使用
+type NopMetrics struct{}
+
+// IncCounter 实现 Metrics 接口
+func (NopMetrics) IncCounter(string, Labels) {}
+
+// ObserveHistogram 实现 Metrics 接口
+func (NopMetrics) ObserveHistogram(string, float64, Labels) {}
+
+// MemoryMetrics 内存指标收集器，适用于测试和调试
+type MemoryMetrics struct {
+	mu         sync.Mutex
+	counters   map[string]float64
+	histograms map[string][]float64
+}
+
+// NewMemoryMetrics 创建内存指标收集器
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		counters:   make(map[string]float64),
+		histograms: make(map[string][]float64),
+	}
+}
+
+// IncCounter 实现 Metrics 接口
+func (m *MemoryMetrics) IncCounter(name string, labels Labels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters[metricKey(name, labels)]++
+}
+
+// ObserveHistogram 实现 Metrics 接口
+func (m *MemoryMetrics) ObserveHistogram(name string, value float64, labels Labels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricKey(name, labels)
+	m.histograms[key] = append(m.histograms[key], value)
+}
+
+// CounterValue 获取计数器的值，标签需完全匹配
+func (m *MemoryMetrics) CounterValue(name string, labels Labels) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counters[metricKey(name, labels)]
+}
+
+// HistogramValues 获取直方图的全部观测值，标签需完全匹配
+func (m *MemoryMetrics) HistogramValues(name string, labels Labels) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]float64(nil), m.histograms[metricKey(name, labels)]...)
+}
+
+// Reset 清空所有指标
+func (m *MemoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters = make(map[string]float64)
+	m.histograms = make(map[string][]float64)
+}
+
+// metricKey 生成指标的唯一键，标签按名称排序，如 name{a="1",b="2"}
+func metricKey(name string, labels Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(labels[k]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// ResultLabel 将错误转换为result标签值：success或error
+func ResultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ErrCodeLabel 将错误转换为errcode标签值
+// 微信错误返回错误码，HTTP状态码异常返回 http_状态码，其他错误返回error，成功返回0
+func ErrCodeLabel(err error) string {
+	if err == nil {
+		return "0"
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		if apiErr.ErrCode == 0 && apiErr.HTTPStatus != 0 {
+			return "http_" + strconv.Itoa(apiErr.HTTPStatus)
+		}
+		return strconv.Itoa(apiErr.ErrCode)
+	}
+	return "error"
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestRequestRecordsMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/fail" {
+			_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid openid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	metrics := NewMemoryMetrics()
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetMetrics(metrics)
+
+	_ = req.Make(context.Background(), &ReqMakeOpt{URL: srv.URL + "/ok?access_token=TOKEN"})
+	_ = req.Make(context.Background(), &ReqMakeOpt{Method: "POST", URL: srv.URL + "/fail", Body: map[string]string{}})
+
+	ok := metrics.HistogramValues(MetricRequestDuration, Labels{"endpoint": "/ok", "method": "GET", "errcode": "0"})
+	if len(ok) != 1 {
+		t.Fatalf("expected one duration sample for /ok, got %v", ok)
+	}
+	if v := metrics.CounterValue(MetricRequestErrors, Labels{"endpoint": "/fail", "errcode": "40003"}); v != 1 {
+		t.Fatalf("expected one error for /fail, got %v", v)
+	}
+	if v := metrics.CounterValue(MetricRequestErrors, Labels{"endpoint": "/ok", "errcode": "0"}); v != 0 {
+		t.Fatalf("successful request should not count as error, got %v", v)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"net/http"
+)
+
+// RoundTrip 执行一次HTTP往返
+type RoundTrip func(req *http.Request) (*http.Response, error)
+
+// Middleware 请求中间件，包装下一个RoundTrip
+//
+// 可用于注入请求头、采集指标、链路追踪、透传请求ID、故障注入、响应缓存等，例如：
+//
+//	func RequestID(next core.RoundTrip) core.RoundTrip {
+//		return func(req *http.Request) (*http.Response, error) {
+//			req.Header.Set("X-Request-ID", requestIDFromContext(req.Context()))
+//			return next(req)
+//		}
+//	}
+type Middleware func(next RoundTrip) RoundTrip
+
+// Use 添加请求中间件，先添加的中间件位于外层，最先处理请求、最后处理响应
+func (r *Request) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// roundTrip 经过中间件链发送请求
+func (r *Request) roundTrip(req *http.Request) (*http.Response, error) {
+	next := RoundTrip(r.httpClient.Do)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		next = r.middlewares[i](next)
+	}
+	return next(req)
+}
+
+// ParseMiddlewares 从可选参数中解析请求中间件，供各客户端构造函数使用
+// 支持 Middleware、func(RoundTrip) RoundTrip 和 []Middleware 三种形式
+// @param option any 可选参数
+// @return []Middleware 中间件列表
+// @return bool 是否为中间件参数
+func ParseMiddlewares(option any) ([]Middleware, bool) {
+	switch v := option.(type) {
+	case Middleware:
+		return []Middleware{v}, true
+	case func(RoundTrip) RoundTrip:
+		return []Middleware{v}, true
+	case []Middleware:
+		return v, true
+	}
+	return nil, false
+}
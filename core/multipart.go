@@ -0,0 +1,147 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"sort"
+)
+
+// MultipartFile multipart/form-data 文件字段
+type MultipartFile struct {
+	FieldName string    // 字段名，微信素材接口为 media
+	FileName  string    // 文件名，微信根据扩展名识别文件格式
+	Reader    io.Reader // 文件内容，发送时以流的方式写入请求体，不会整体读入内存
+	Size      int64     // 文件大小，为0时尝试从Reader自动获取；无法获取时使用分块传输
+}
+
+// Multipart multipart/form-data 请求体
+type Multipart struct {
+	Files  []MultipartFile   // 文件字段
+	Fields map[string]string // 额外的表单字段，如视频素材的 description
+}
+
+// sizer 可获取剩余长度的Reader，如 bytes.Reader、strings.Reader
+type sizer interface {
+	Len() int
+}
+
+// build 构建流式请求体
+// 表单字段和各文件的分隔头在内存中生成，文件内容直接从Reader读取
+// @return io.Reader 请求体
+// @return string Content-Type
+// @return int64 请求体长度，无法确定时为-1
+// @return error 错误信息
+func (m *Multipart) build() (io.Reader, string, int64, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	keys := make([]string, 0, len(m.Fields))
+	for key := range m.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := writer.WriteField(key, m.Fields[key]); err != nil {
+			return nil, "", 0, fmt.Errorf("写入表单字段失败: %w", err)
+		}
+	}
+
+	var readers []io.Reader
+	var length int64
+	for _, file := range m.Files {
+		if file.Reader == nil {
+			return nil, "", 0, fmt.Errorf("文件%s内容不能为空", file.FileName)
+		}
+		if _, err := writer.CreateFormFile(file.FieldName, file.FileName); err != nil {
+			return nil, "", 0, fmt.Errorf("创建文件字段失败: %w", err)
+		}
+
+		header := bytes.NewReader(append([]byte(nil), buf.Bytes()...))
+		buf.Reset()
+		readers = append(readers, header, file.Reader)
+
+		size := fileSize(file)
+		if length >= 0 && size > 0 {
+			length += int64(header.Len()) + size
+		} else {
+			length = -1
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", 0, fmt.Errorf("关闭multipart writer失败: %w", err)
+	}
+	if length >= 0 {
+		length += int64(buf.Len())
+	}
+	readers = append(readers, bytes.NewReader(buf.Bytes()))
+
+	return io.MultiReader(readers...), writer.FormDataContentType(), length, nil
+}
+
+// rewind 将文件Reader重置到起始位置，用于token失效后的重试
+// @param offsets 各文件首次发送前的读取位置
+// @return bool 是否全部重置成功，存在不可重置的Reader时返回false
+func (m *Multipart) rewind(offsets []int64) bool {
+	if len(offsets) != len(m.Files) {
+		return false
+	}
+	for i, file := range m.Files {
+		seeker, ok := file.Reader.(io.Seeker)
+		if !ok || offsets[i] < 0 {
+			return false
+		}
+		if _, err := seeker.Seek(offsets[i], io.SeekStart); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// offsets 记录各文件Reader当前的读取位置，不可重置的Reader记为-1
+func (m *Multipart) offsets() []int64 {
+	offsets := make([]int64, len(m.Files))
+	for i, file := range m.Files {
+		offsets[i] = -1
+		if seeker, ok := file.Reader.(io.Seeker); ok {
+			if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				offsets[i] = offset
+			}
+		}
+	}
+	return offsets
+}
+
+// fileNames 返回所有文件名，用于日志记录
+func (m *Multipart) fileNames() []string {
+	names := make([]string, 0, len(m.Files))
+	for _, file := range m.Files {
+		names = append(names, file.FileName)
+	}
+	return names
+}
+
+// fileSize 获取文件剩余可读长度
+func fileSize(file MultipartFile) int64 {
+	if file.Size > 0 {
+		return file.Size
+	}
+	switch r := file.Reader.(type) {
+	case sizer:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil {
+			return 0
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0
+		}
+		return info.Size() - offset
+	}
+	return 0
+}
@@ -0,0 +1,363 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded 本地限流器拒绝了请求，请求未发送到微信
+var ErrRateLimitExceeded = errors.New("超出本地限流配额")
+
+// ErrDailyLimitReached 当日调用次数已达上限，由 RateLimitStore 返回
+var ErrDailyLimitReached = errors.New("当日调用次数已达上限")
+
+// quotaLocation 微信接口每日调用次数在北京时间0点重置
+var quotaLocation = time.FixedZone("CST", 8*3600)
+
+// RateLimitMode 超出限流时的处理方式
+type RateLimitMode int
+
+const (
+	RateLimitFailFast RateLimitMode = iota // 立即返回 *RateLimitError
+	RateLimitWait                          // 阻塞等待直到允许调用或上下文取消，超出每日上限时仍立即返回
+)
+
+// RateLimit 接口限流规则
+type RateLimit struct {
+	Rate  float64 // 令牌桶每秒补充的令牌数，0表示不限制频率
+	Burst int     // 令牌桶容量，为0时取 Rate 向上取整且不小于1
+	Daily int     // 每日调用上限，0表示不限制
+}
+
+// BucketSize 令牌桶容量，未设置 Burst 时取 Rate 向上取整且不小于1
+func (l RateLimit) BucketSize() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.Rate)))
+}
+
+// DefaultRateLimits 微信公布的常用接口每日调用上限，按接口路径配置
+// 实际额度与账号类型、粉丝数有关，建议通过 openplatform.Client.SyncApiQuota 同步
+var DefaultRateLimits = map[string]RateLimit{
+	"/cgi-bin/token":                      {Daily: 2000},
+	"/cgi-bin/stable_token":               {Daily: 10000},
+	"/cgi-bin/ticket/getticket":           {Daily: 100000},
+	"/cgi-bin/menu/create":                {Daily: 1000},
+	"/cgi-bin/menu/get":                   {Daily: 10000},
+	"/cgi-bin/menu/delete":                {Daily: 1000},
+	"/cgi-bin/user/info":                  {Daily: 5000000},
+	"/cgi-bin/user/info/batchget":         {Daily: 5000000},
+	"/cgi-bin/user/get":                   {Daily: 500},
+	"/cgi-bin/message/custom/send":        {Daily: 500000},
+	"/cgi-bin/message/template/send":      {Daily: 100000},
+	"/cgi-bin/message/mass/sendall":       {Daily: 100},
+	"/cgi-bin/message/mass/send":          {Daily: 100},
+	"/cgi-bin/media/upload":               {Daily: 100000},
+	"/cgi-bin/media/get":                  {Daily: 200000},
+	"/cgi-bin/material/add_material":      {Daily: 1000},
+	"/cgi-bin/material/get_material":      {Daily: 1000},
+	"/cgi-bin/qrcode/create":              {Daily: 100000},
+	"/cgi-bin/get_current_autoreply_info": {Daily: 1000},
+}
+
+// RateLimitStore 限流状态存储
+// 默认使用进程内存储，多实例部署时可使用 storage.RedisRateLimitStore 等共享存储
+type RateLimitStore interface {
+	// Take 尝试取出一个令牌并计入当日调用次数
+	// @return time.Duration 令牌不足时需要等待的时间，为0表示已取得令牌
+	// @return error 当日调用次数已达上限时返回 ErrDailyLimitReached
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (time.Duration, error)
+
+	// SyncDaily 同步当日的调用上限和已调用次数，覆盖当日的本地计数
+	SyncDaily(ctx context.Context, key string, quota, used int, now time.Time) error
+}
+
+// RateLimitError 本地限流错误
+// 可通过 errors.Is 匹配 ErrRateLimitExceeded；超出每日上限时同时匹配 ErrQuotaExceeded，否则匹配 ErrRateLimited
+type RateLimitError struct {
+	AppID      string        // 调用方appid
+	Endpoint   string        // 接口路径
+	RetryAfter time.Duration // 建议的重试等待时间，超出每日上限时为距离次日0点的时间
+	Daily      bool          // 是否超出每日上限
+}
+
+// Error 实现error接口
+func (e *RateLimitError) Error() string {
+	if e.Daily {
+		return fmt.Sprintf("%s: 接口 %s 当日调用次数已达上限 (appid: %s)", ErrRateLimitExceeded.Error(), e.Endpoint, e.AppID)
+	}
+	return fmt.Sprintf("%s: 接口 %s 调用过于频繁，%s 后重试 (appid: %s)", ErrRateLimitExceeded.Error(), e.Endpoint, e.RetryAfter, e.AppID)
+}
+
+// Is 支持 errors.Is 按错误分类匹配
+func (e *RateLimitError) Is(target error) bool {
+	switch target {
+	case ErrRateLimitExceeded:
+		return true
+	case ErrQuotaExceeded:
+		return e.Daily
+	case ErrRateLimited:
+		return !e.Daily
+	}
+	return false
+}
+
+// RateLimiter 按appid和接口限流的限流器
+// 令牌桶控制调用频率，每日上限控制调用总量，避免触发微信的45009、45011等错误
+type RateLimiter struct {
+	mu           sync.RWMutex
+	store        RateLimitStore
+	limits       map[string]RateLimit
+	defaultLimit RateLimit
+	mode         RateLimitMode
+}
+
+// NewRateLimiter 创建限流器，默认使用 DefaultRateLimits 并在超出限流时立即返回错误
+// @param store RateLimitStore 限流状态存储，为nil时使用进程内存储
+// @return *RateLimiter 限流器
+func NewRateLimiter(store RateLimitStore) *RateLimiter {
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	l := &RateLimiter{
+		store:  store,
+		limits: make(map[string]RateLimit, len(DefaultRateLimits)),
+	}
+	for endpoint, limit := range DefaultRateLimits {
+		l.limits[endpoint] = limit
+	}
+	return l
+}
+
+// SetLimit 设置指定接口的限流规则
+// @param endpoint string 接口路径，如 /cgi-bin/message/custom/send，也可传入完整URL
+// @param limit RateLimit 限流规则
+// @return *RateLimiter 限流器，便于链式调用
+func (l *RateLimiter) SetLimit(endpoint string, limit RateLimit) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits[endpointPath(endpoint)] = limit
+	return l
+}
+
+// SetDefaultLimit 设置未单独配置的接口使用的限流规则，默认不限制
+// @return *RateLimiter 限流器，便于链式调用
+func (l *RateLimiter) SetDefaultLimit(limit RateLimit) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.defaultLimit = limit
+	return l
+}
+
+// SetMode 设置超出限流时的默认处理方式，可通过 WithRateLimitMode 为单次调用指定
+// @return *RateLimiter 限流器，便于链式调用
+func (l *RateLimiter) SetMode(mode RateLimitMode) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mode = mode
+	return l
+}
+
+// Wait 申请一次调用额度，根据处理方式阻塞等待或立即返回 *RateLimitError
+// @param ctx context.Context 上下文
+// @param appID string 调用方appid
+// @param endpoint string 接口路径或完整URL
+// @return error 超出限流时返回 *RateLimitError
+func (l *RateLimiter) Wait(ctx context.Context, appID, endpoint string) error {
+	endpoint = endpointPath(endpoint)
+	limit := l.limitFor(endpoint)
+	if limit.Rate <= 0 && limit.Daily <= 0 {
+		return nil
+	}
+
+	key := rateLimitKey(appID, endpoint)
+	mode := l.modeFor(ctx)
+	for {
+		now := time.Now()
+		wait, err := l.store.Take(ctx, key, limit, now)
+		if errors.Is(err, ErrDailyLimitReached) {
+			return &RateLimitError{AppID: appID, Endpoint: endpoint, RetryAfter: untilQuotaReset(now), Daily: true}
+		}
+		if err != nil {
+			return fmt.Errorf("读取限流状态失败: %w", err)
+		}
+		if wait <= 0 {
+			return nil
+		}
+		if mode != RateLimitWait {
+			return &RateLimitError{AppID: appID, Endpoint: endpoint, RetryAfter: wait}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// SyncDaily 同步接口当日的调用上限和已调用次数，通常来自微信的额度查询接口
+// @param appID string 调用方appid
+// @param endpoint string 接口路径
+// @param quota int 当日调用上限
+// @param used int 当日已调用次数
+func (l *RateLimiter) SyncDaily(ctx context.Context, appID, endpoint string, quota, used int) error {
+	endpoint = endpointPath(endpoint)
+	return l.store.SyncDaily(ctx, rateLimitKey(appID, endpoint), quota, used, time.Now())
+}
+
+// Exhaust 标记接口当日额度已用尽，微信返回45009时调用，当日剩余时间内直接拒绝请求
+// 仅对配置了限流规则的接口生效
+func (l *RateLimiter) Exhaust(ctx context.Context, appID, endpoint string) error {
+	return l.SyncDaily(ctx, appID, endpoint, 1, 1)
+}
+
+// limitFor 获取接口的限流规则
+func (l *RateLimiter) limitFor(endpoint string) RateLimit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if limit, ok := l.limits[endpoint]; ok {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+// modeFor 获取本次调用的处理方式
+func (l *RateLimiter) modeFor(ctx context.Context) RateLimitMode {
+	if mode, ok := ctx.Value(rateLimitModeKey{}).(RateLimitMode); ok {
+		return mode
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.mode
+}
+
+type rateLimitModeKey struct{}
+
+// WithRateLimitMode 为单次调用指定超出限流时的处理方式
+// @param ctx context.Context 上下文
+// @param mode RateLimitMode 处理方式
+// @return context.Context 新的上下文
+func WithRateLimitMode(ctx context.Context, mode RateLimitMode) context.Context {
+	return context.WithValue(ctx, rateLimitModeKey{}, mode)
+}
+
+// MemoryRateLimitStore 进程内限流状态存储
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+// rateBucket 令牌桶和当日计数
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	day    string
+	used   int
+	quota  int // 同步得到的当日上限，0表示使用规则中的上限
+}
+
+// NewMemoryRateLimitStore 创建进程内限流状态存储
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*rateBucket)}
+}
+
+// Take 实现 RateLimitStore 接口
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bucket(key, limit, now)
+
+	quota := limit.Daily
+	if b.quota > 0 {
+		quota = b.quota
+	}
+	if quota > 0 && b.used >= quota {
+		return 0, ErrDailyLimitReached
+	}
+
+	if limit.Rate > 0 {
+		burst := float64(limit.BucketSize())
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), nil
+		}
+		b.tokens--
+	}
+	b.used++
+	return 0, nil
+}
+
+// SyncDaily 实现 RateLimitStore 接口
+func (s *MemoryRateLimitStore) SyncDaily(_ context.Context, key string, quota, used int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bucket(key, RateLimit{}, now)
+	b.quota = quota
+	b.used = used
+	return nil
+}
+
+// bucket 获取令牌桶，跨天时重置当日计数
+func (s *MemoryRateLimitStore) bucket(key string, limit RateLimit, now time.Time) *rateBucket {
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &rateBucket{tokens: float64(limit.BucketSize()), last: now}
+		s.buckets[key] = b
+	}
+	if day := QuotaDay(now); b.day != day {
+		b.day = day
+		b.used = 0
+		b.quota = 0
+	}
+	return b
+}
+
+// QuotaDay 返回每日额度所属的日期（北京时间），供 RateLimitStore 实现使用
+func QuotaDay(now time.Time) string {
+	return now.In(quotaLocation).Format("20060102")
+}
+
+// untilQuotaReset 距离下次额度重置的时间
+func untilQuotaReset(now time.Time) time.Duration {
+	local := now.In(quotaLocation)
+	next := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, quotaLocation)
+	return next.Sub(now)
+}
+
+// rateLimitKey 限流状态的存储键
+func rateLimitKey(appID, endpoint string) string {
+	return appID + ":" + endpoint
+}
+
+// endpointPath 从完整URL中提取接口路径
+func endpointPath(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		if parsed, err := url.Parse(endpoint); err == nil {
+			return parsed.Path
+		}
+	}
+	if idx := strings.IndexByte(endpoint, '?'); idx >= 0 {
+		endpoint = endpoint[:idx]
+	}
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/" + endpoint
+	}
+	return endpoint
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestRateLimiterFailFastAndDaily(t *testing.T) {
+	limiter := NewRateLimiter(nil).SetLimit("/cgi-bin/message/custom/send", RateLimit{Rate: 10, Burst: 1, Daily: 2})
+	ctx := context.Background()
+
+	if err := limiter.Wait(ctx, "wx1", "/cgi-bin/message/custom/send"); err != nil {
+		t.Fatalf("first call should pass: %v", err)
+	}
+	err := limiter.Wait(ctx, "wx1", "/cgi-bin/message/custom/send")
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) || rlErr.Daily || !errors.Is(err, ErrRateLimited) || rlErr.RetryAfter <= 0 {
+		t.Fatalf("expected rate limit error, got: %v", err)
+	}
+
+	// 不同appid互不影响
+	if err := limiter.Wait(ctx, "wx2", "/cgi-bin/message/custom/send"); err != nil {
+		t.Fatalf("other appid should pass: %v", err)
+	}
+
+	if err := limiter.Wait(WithRateLimitMode(ctx, RateLimitWait), "wx1", "/cgi-bin/message/custom/send"); err != nil {
+		t.Fatalf("wait mode should pass after refill: %v", err)
+	}
+	err = limiter.Wait(ctx, "wx1", "/cgi-bin/message/custom/send")
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected daily limit error, got: %v", err)
+	}
+}
+
+func TestRequestExhaustsQuotaOn45009(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"errcode":45009,"errmsg":"reach max api daily quota limit"}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetRateLimiter(NewRateLimiter(nil).SetLimit("/cgi-bin/menu/get", RateLimit{Daily: 100}))
+	req.SetAppIDResolver(func(*url.URL) string { return "wx1" })
+
+	for i := 0; i < 2; i++ {
+		err := req.Make(context.Background(), &ReqMakeOpt{Method: "GET", URL: srv.URL + "/cgi-bin/menu/get"})
+		if !errors.Is(err, ErrQuotaExceeded) {
+			t.Fatalf("expected quota error, got: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected second call to be rejected locally, got %d calls", calls)
+	}
+}
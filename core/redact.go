@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue 脱敏后的替换值
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactFields 默认脱敏的参数名和字段名
+// 匹配时忽略大小写和下划线，因此同样适用于XML中的驼峰标签，如 ComponentVerifyTicket
+var DefaultRedactFields = []string{
+	// 凭证和密钥
+	"access_token",
+	"component_access_token",
+	"authorizer_access_token",
+	"authorizer_refresh_token",
+	"refresh_token",
+	"secret",
+	"appsecret",
+	"component_appsecret",
+	"component_secret",
+	"component_verify_ticket",
+	"pre_auth_code",
+	"authorization_code",
+	"auth_code",
+	"code",
+	"ticket",
+	"encoding_aes_key",
+	"prev_encoding_aes_key",
+	"new_encoding_aes_key",
+	// 用户隐私信息
+	"openid",
+	"unionid",
+	"nickname",
+	"headimgurl",
+	"phone_number",
+	"pure_phone_number",
+	"mobile",
+}
+
+// sensitiveHeaders 需要脱敏的请求头
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Redactor 日志脱敏器
+// 用于在记录请求URL、请求头、请求体和响应体前隐藏access_token、secret等凭证以及用户隐私信息
+// 可通过 AddFields、AddPatterns 追加规则，建议在创建客户端前完成配置
+type Redactor struct {
+	mu           sync.RWMutex
+	fields       map[string]struct{}
+	patterns     []*regexp.Regexp
+	textPatterns []*regexp.Regexp // 非JSON内容的字段匹配规则
+}
+
+// NewRedactor 创建日志脱敏器，包含默认规则
+// @param extraFields 额外需要脱敏的参数名或字段名
+// @return *Redactor 日志脱敏器
+func NewRedactor(extraFields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]struct{})}
+	r.AddFields(DefaultRedactFields...)
+	r.AddFields(extraFields...)
+	return r
+}
+
+// AddFields 追加需要脱敏的参数名或字段名
+// @param fields 参数名或字段名
+// @return *Redactor 日志脱敏器，便于链式调用
+func (r *Redactor) AddFields(fields ...string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, field := range fields {
+		name := normalizeFieldName(field)
+		if name == "" {
+			continue
+		}
+		if _, ok := r.fields[name]; ok {
+			continue
+		}
+		r.fields[name] = struct{}{}
+
+		// 参数名中的下划线可有可无，以同时匹配 access_token 和 AccessToken
+		namePattern := strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(field)), "_", "_?")
+		r.textPatterns = append(r.textPatterns,
+			// JSON或表单：access_token=xxx、"access_token":"xxx"
+			regexp.MustCompile(`(?i)(\b`+namePattern+`"?\s*[=:]\s*"?)([^"&,\s}<]+)`),
+			// XML：<AccessToken><![CDATA[xxx]]></AccessToken>、<AccessToken>xxx</AccessToken>
+			regexp.MustCompile(`(?i)(<`+namePattern+`>(?:<!\[CDATA\[)?)([^<\]]*)`),
+		)
+	}
+	return r
+}
+
+// AddPatterns 追加正则脱敏规则，匹配到的内容整体替换为 RedactedValue
+// @param patterns 正则表达式，如手机号、身份证号
+// @return *Redactor 日志脱敏器，便于链式调用
+func (r *Redactor) AddPatterns(patterns ...*regexp.Regexp) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.patterns = append(r.patterns, patterns...)
+	return r
+}
+
+// URL 对URL中的敏感查询参数脱敏
+// @param rawURL 原始URL
+// @return string 脱敏后的URL
+func (r *Redactor) URL(rawURL string) string {
+	idx := strings.IndexByte(rawURL, '?')
+	if idx < 0 {
+		return rawURL
+	}
+
+	pairs := strings.Split(rawURL[idx+1:], "&")
+	for i, pair := range pairs {
+		key, _, found := strings.Cut(pair, "=")
+		if found && r.isSensitive(key) {
+			pairs[i] = key + "=" + RedactedValue
+		}
+	}
+	return r.applyPatterns(rawURL[:idx+1] + strings.Join(pairs, "&"))
+}
+
+// Header 对敏感请求头脱敏，返回新的请求头，不修改原请求头
+// @param header 原始请求头
+// @return http.Header 脱敏后的请求头
+func (r *Redactor) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, RedactedValue)
+		}
+	}
+	return redacted
+}
+
+// Body 对请求体或响应体脱敏
+// JSON内容按字段名递归脱敏，其他内容（XML、表单等）按规则匹配脱敏
+// @param body 原始内容
+// @return string 脱敏后的内容
+func (r *Redactor) Body(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		var data interface{}
+		if err := decoder.Decode(&data); err == nil {
+			if out, err := json.Marshal(r.redactValue(data)); err == nil {
+				return r.applyPatterns(string(out))
+			}
+		}
+	}
+
+	return r.String(string(body))
+}
+
+// String 对任意文本脱敏，如错误信息
+// @param s 原始文本
+// @return string 脱敏后的文本
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	for _, pattern := range r.textPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+RedactedValue)
+	}
+	r.mu.RUnlock()
+	return r.applyPatterns(s)
+}
+
+// redactValue 递归脱敏JSON值
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if r.isSensitive(key) {
+				val[key] = RedactedValue
+				continue
+			}
+			val[key] = r.redactValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.redactValue(item)
+		}
+	}
+	return v
+}
+
+// isSensitive 判断参数名或字段名是否需要脱敏
+func (r *Redactor) isSensitive(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.fields[normalizeFieldName(name)]
+	return ok
+}
+
+// applyPatterns 应用正则脱敏规则
+func (r *Redactor) applyPatterns(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, RedactedValue)
+	}
+	return s
+}
+
+// normalizeFieldName 统一字段名格式：小写并去除下划线
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
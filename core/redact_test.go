@@ -0,0 +1,49 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactorURL(t *testing.T) {
+	r := NewRedactor()
+	got := r.URL("https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=wx123&secret=abc")
+	if strings.Contains(got, "abc") || !strings.Contains(got, "appid=wx123") {
+		t.Fatalf("unexpected redacted url: %s", got)
+	}
+}
+
+func TestRedactorBody(t *testing.T) {
+	r := NewRedactor("card_no").AddPatterns(regexp.MustCompile(`1[3-9]\d{9}`))
+
+	got := r.Body([]byte(`{"errcode":0,"msgid":1234567890123456789,"authorizer_refresh_token":"rt","user":{"openid":"o1","card_no":"c1"},"remark":"13800138000"}`))
+	for _, leaked := range []string{`"rt"`, `"o1"`, `"c1"`, "13800138000"} {
+		if strings.Contains(got, leaked) {
+			t.Fatalf("%s leaked in: %s", leaked, got)
+		}
+	}
+	if !strings.Contains(got, "1234567890123456789") {
+		t.Fatalf("number precision lost: %s", got)
+	}
+
+	got = r.Body([]byte(`<xml><AppId>wx1</AppId><ComponentVerifyTicket><![CDATA[ticket@@@xyz]]></ComponentVerifyTicket></xml>`))
+	if strings.Contains(got, "ticket@@@xyz") || !strings.Contains(got, "wx1") {
+		t.Fatalf("unexpected redacted xml: %s", got)
+	}
+
+	got = r.Body([]byte(`<xml><AuthorizationCode>queryauthcode@@@abc</AuthorizationCode><NewEncodingAESKey><![CDATA[newkey]]></NewEncodingAESKey></xml>`))
+	if strings.Contains(got, "queryauthcode@@@abc") || strings.Contains(got, "newkey") {
+		t.Fatalf("unexpected redacted xml: %s", got)
+	}
+}
+
+func TestRedactorStartPushTicketBody(t *testing.T) {
+	body := []byte(`{"component_appid":"wx_component","component_secret":"s3cret"}`)
+	for _, r := range []*Redactor{NewRedactor(), newScrubber(DefaultScrubFields...)} {
+		got := r.Body(body)
+		if strings.Contains(got, "s3cret") || !strings.Contains(got, "wx_component") {
+			t.Fatalf("unexpected redacted body: %s", got)
+		}
+	}
+}
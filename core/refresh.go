@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RefreshGroup 合并同一进程内并发的token刷新
+//
+// 同一个key同时只有一个刷新在执行，其余调用方等待并共享其结果，避免并发请求同时获取token导致彼此失效。
+// 刷新不受单个调用方取消的影响，调用方的ctx被取消时只是停止等待。零值可直接使用。
+type RefreshGroup struct {
+	mu    sync.Mutex
+	calls map[string]*refreshCall
+}
+
+// refreshCall 执行中的刷新
+type refreshCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// RefreshKey 返回按token类型和appid区分的刷新key
+// @param kind string token类型，如 TokenKindAccessToken
+// @param appID string token所属的appid
+// @return string 刷新key
+func RefreshKey(kind, appID string) string {
+	return kind + ":" + appID
+}
+
+// Do 执行刷新，同一key已有刷新在执行时等待其结果
+// @param ctx context.Context 上下文，发起刷新的调用方的ctx中的span等值会传递给fn
+// @param key string 刷新key，见 RefreshKey
+// @param fn func(ctx context.Context) (interface{}, error) 刷新函数
+// @return interface{} 刷新结果
+// @return error 刷新失败或ctx被取消
+func (g *RefreshGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*refreshCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &refreshCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(context.WithoutCancel(ctx), key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run 执行刷新并通知等待的调用方
+func (g *RefreshGroup) run(ctx context.Context, key string, call *refreshCall, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("刷新token时发生panic: %v", r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn(ctx)
+}
+
+// RefreshOnce 使用 RefreshGroup 执行有类型的刷新
+// @param ctx context.Context 上下文
+// @param g *RefreshGroup 刷新去重组
+// @param key string 刷新key，见 RefreshKey
+// @param fn func(ctx context.Context) (T, error) 刷新函数
+// @return T 刷新结果
+// @return error 刷新失败或ctx被取消
+func RefreshOnce[T any](ctx context.Context, g *RefreshGroup, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	value, err := g.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshGroupDeduplicatesConcurrentCalls(t *testing.T) {
+	var group RefreshGroup
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "token", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = RefreshOnce(context.Background(), &group, RefreshKey(TokenKindAccessToken, "wx1"), fetch)
+		}(i)
+	}
+
+	// 其他appid的刷新互不影响
+	other, err := RefreshOnce(context.Background(), &group, RefreshKey(TokenKindAccessToken, "wx2"), func(ctx context.Context) (string, error) {
+		return "other", nil
+	})
+	if err != nil || other != "other" {
+		t.Fatalf("unexpected other result: %q %v", other, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected 1 refresh, got %d", n)
+	}
+	for _, result := range results {
+		if result != "token" {
+			t.Fatalf("unexpected result: %q", result)
+		}
+	}
+}
+
+func TestRefreshGroupCallerCancel(t *testing.T) {
+	var group RefreshGroup
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := RefreshOnce(context.Background(), &group, "key", func(ctx context.Context) (string, error) {
+			<-release
+			return "", ctx.Err()
+		})
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// 等待方取消只停止等待，不影响执行中的刷新
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := group.Do(ctx, "key", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("refresh should not be canceled: %v", err)
+	}
+}
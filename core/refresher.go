@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// 主动刷新的默认参数
+const (
+	DefaultRefreshInterval = time.Minute      // 检查间隔
+	DefaultRefreshLead     = 10 * time.Minute // 提前刷新的时间
+	DefaultRefreshJitter   = time.Minute      // 检查间隔和提前时间的随机抖动上限
+)
+
+// ErrRefresherRunning 刷新服务已启动
+var ErrRefresherRunning = errors.New("刷新服务已启动")
+
+// RefreshTarget 需要主动刷新的凭证
+type RefreshTarget struct {
+	Kind      string    // token类型，如 TokenKindAccessToken
+	AppID     string    // 凭证所属的appid
+	ExpiresAt time.Time // 当前凭证的过期时间，零值表示凭证不存在
+
+	// Refresh 刷新凭证，凭证在before之后才过期时（如已被其他实例刷新）不调用微信接口
+	Refresh func(ctx context.Context, before time.Time) error
+}
+
+// RefreshSource 提供需要主动刷新的凭证，公众号客户端和第三方平台客户端均已实现
+type RefreshSource interface {
+	RefreshTargets(ctx context.Context) ([]RefreshTarget, error)
+}
+
+// RefreshFailure 主动刷新失败的信息
+type RefreshFailure struct {
+	Kind      string    // token类型，获取刷新列表失败时为空
+	AppID     string    // 凭证所属的appid，获取刷新列表失败时为空
+	ExpiresAt time.Time // 当前凭证的过期时间，可用于判断距离失效还有多久
+	Err       error     // 失败原因
+}
+
+// RefreshFailureFunc 主动刷新失败回调，可用于在凭证真正失效前告警
+type RefreshFailureFunc func(failure *RefreshFailure)
+
+// Refresher 后台主动刷新服务
+//
+// 按带随机抖动的间隔检查各凭证，在过期前 lead 时间内主动刷新，避免业务请求承担刷新耗时和刷新失败。
+// 刷新失败时通过回调通知，并在下一次检查时重试。多个实例同时运行时，存储支持加锁可避免重复刷新。
+type Refresher struct {
+	sources   []RefreshSource
+	onFailure RefreshFailureFunc
+	interval  time.Duration
+	lead      time.Duration
+	jitter    time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRefresher 创建主动刷新服务
+// @param onFailure RefreshFailureFunc 刷新失败回调，可为nil
+// @param sources ...RefreshSource 凭证来源，如公众号客户端、第三方平台客户端
+// @return *Refresher 主动刷新服务
+func NewRefresher(onFailure RefreshFailureFunc, sources ...RefreshSource) *Refresher {
+	return &Refresher{
+		sources:   sources,
+		onFailure: onFailure,
+		interval:  DefaultRefreshInterval,
+		lead:      DefaultRefreshLead,
+		jitter:    DefaultRefreshJitter,
+	}
+}
+
+// SetInterval 设置检查间隔，需要在 Start 之前调用
+func (r *Refresher) SetInterval(interval time.Duration) *Refresher {
+	if interval > 0 {
+		r.interval = interval
+	}
+	return r
+}
+
+// SetLead 设置提前刷新的时间，凭证在该时间内过期时刷新，需要在 Start 之前调用
+func (r *Refresher) SetLead(lead time.Duration) *Refresher {
+	if lead > 0 {
+		r.lead = lead
+	}
+	return r
+}
+
+// SetJitter 设置随机抖动上限，分散多个实例的检查和刷新时间，为0时不抖动，需要在 Start 之前调用
+func (r *Refresher) SetJitter(jitter time.Duration) *Refresher {
+	if jitter >= 0 {
+		r.jitter = jitter
+	}
+	return r
+}
+
+// Start 启动后台刷新，立即执行一次检查
+// @param ctx context.Context 上下文，结束时刷新服务停止
+// @return error 刷新服务已启动时返回 ErrRefresherRunning
+func (r *Refresher) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return ErrRefresherRunning
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(ctx, r.done)
+	return nil
+}
+
+// Stop 停止后台刷新，等待正在执行的检查结束
+func (r *Refresher) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// RefreshDue 检查一次并刷新即将过期的凭证
+// @param ctx context.Context 上下文
+// @return int 刷新失败的数量，失败详情通过回调通知
+func (r *Refresher) RefreshDue(ctx context.Context) int {
+	failures := 0
+	for _, source := range r.sources {
+		targets, err := source.RefreshTargets(ctx)
+		if err != nil {
+			failures++
+			r.fail(&RefreshFailure{Err: err})
+			continue
+		}
+		for _, target := range targets {
+			if ctx.Err() != nil {
+				return failures
+			}
+			before := time.Now().Add(r.lead + r.randomJitter())
+			if target.ExpiresAt.After(before) {
+				continue
+			}
+			if err := target.Refresh(ctx, before); err != nil {
+				if ctx.Err() != nil {
+					// 刷新服务停止，不视为失败
+					return failures
+				}
+				failures++
+				r.fail(&RefreshFailure{Kind: target.Kind, AppID: target.AppID, ExpiresAt: target.ExpiresAt, Err: err})
+			}
+		}
+	}
+	return failures
+}
+
+// loop 按间隔检查，直到ctx结束
+func (r *Refresher) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		r.RefreshDue(ctx)
+
+		timer := time.NewTimer(r.interval + r.randomJitter())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// randomJitter 返回 [0, jitter) 内的随机时长
+func (r *Refresher) randomJitter() time.Duration {
+	if r.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(r.jitter)))
+}
+
+// fail 通知刷新失败
+func (r *Refresher) fail(failure *RefreshFailure) {
+	if r.onFailure != nil {
+		r.onFailure(failure)
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRefreshSource 固定返回刷新列表的凭证来源
+type testRefreshSource struct {
+	mu        sync.Mutex
+	targets   []RefreshTarget
+	refreshed []string
+}
+
+func (s *testRefreshSource) RefreshTargets(ctx context.Context) ([]RefreshTarget, error) {
+	return s.targets, nil
+}
+
+func (s *testRefreshSource) add(appID string, expiresAt time.Time, err error) {
+	s.targets = append(s.targets, RefreshTarget{
+		Kind:      TokenKindAccessToken,
+		AppID:     appID,
+		ExpiresAt: expiresAt,
+		Refresh: func(ctx context.Context, before time.Time) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.refreshed = append(s.refreshed, appID)
+			return err
+		},
+	})
+}
+
+func TestRefresherRefreshesDueTargets(t *testing.T) {
+	now := time.Now()
+	source := &testRefreshSource{}
+	source.add("wx_due", now.Add(5*time.Minute), nil)
+	source.add("wx_missing", time.Time{}, nil)
+	source.add("wx_fresh", now.Add(time.Hour), nil)
+	source.add("wx_broken", now.Add(time.Minute), errors.New("refresh failed"))
+
+	var failures []*RefreshFailure
+	refresher := NewRefresher(func(failure *RefreshFailure) {
+		failures = append(failures, failure)
+	}, source).SetJitter(0)
+
+	if n := refresher.RefreshDue(context.Background()); n != 1 {
+		t.Fatalf("expected 1 failure, got %d", n)
+	}
+	if len(source.refreshed) != 3 || source.refreshed[0] != "wx_due" || source.refreshed[1] != "wx_missing" || source.refreshed[2] != "wx_broken" {
+		t.Fatalf("unexpected refreshed targets: %v", source.refreshed)
+	}
+	if len(failures) != 1 || failures[0].AppID != "wx_broken" || failures[0].Err == nil {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+}
+
+func TestRefresherStartStop(t *testing.T) {
+	source := &testRefreshSource{}
+	source.add("wx1", time.Time{}, nil)
+	refresher := NewRefresher(nil, source).SetInterval(10 * time.Millisecond).SetJitter(0)
+
+	if err := refresher.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := refresher.Start(context.Background()); !errors.Is(err, ErrRefresherRunning) {
+		t.Fatalf("expected ErrRefresherRunning, got %v", err)
+	}
+	time.Sleep(35 * time.Millisecond)
+	refresher.Stop()
+
+	source.mu.Lock()
+	n := len(source.refreshed)
+	source.mu.Unlock()
+	if n < 2 {
+		t.Fatalf("expected periodic refreshes, got %d", n)
+	}
+
+	// 停止后不再刷新
+	time.Sleep(20 * time.Millisecond)
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if len(source.refreshed) != n {
+		t.Fatalf("refresher kept running after Stop")
+	}
+}
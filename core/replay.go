@@ -0,0 +1,328 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultScrubFields 录制时默认清除的凭证字段，匹配规则与 Redactor 相同
+// 与 DefaultRedactFields 不同，不包含openid、昵称等业务数据，以便测试断言
+var DefaultScrubFields = []string{
+	"access_token",
+	"component_access_token",
+	"authorizer_access_token",
+	"authorizer_refresh_token",
+	"refresh_token",
+	"secret",
+	"appsecret",
+	"component_appsecret",
+	"component_secret",
+	"component_verify_ticket",
+	"pre_auth_code",
+	"authorization_code",
+	"auth_code",
+	"ticket",
+	"encoding_aes_key",
+	"prev_encoding_aes_key",
+}
+
+// ErrUnmatchedRequest 回放时没有匹配的录制记录
+var ErrUnmatchedRequest = errors.New("没有匹配的录制请求")
+
+// Interaction 一次录制的HTTP往返
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest 录制的请求，查询参数和请求体均已清除凭证并规范化
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"` // 按参数名排序
+	Body   string `json:"body,omitempty"`  // JSON按字段名排序，multipart请求体不录制
+}
+
+// RecordedResponse 录制的响应
+type RecordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBytes  []byte            `json:"body_bytes,omitempty"` // 非文本内容（如图片）以base64保存
+}
+
+// UnmatchedRequestError 回放时没有匹配的录制记录，可通过 errors.Is 匹配 ErrUnmatchedRequest
+type UnmatchedRequestError struct {
+	Fixture string
+	Request RecordedRequest
+}
+
+// Error 实现error接口
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("%s: %s %s?%s body=%s (录制文件: %s)",
+		ErrUnmatchedRequest.Error(), e.Request.Method, e.Request.Path, e.Request.Query, e.Request.Body, e.Fixture)
+}
+
+// Is 支持 errors.Is 匹配 ErrUnmatchedRequest
+func (e *UnmatchedRequestError) Is(target error) bool {
+	return target == ErrUnmatchedRequest
+}
+
+// recordedHeaders 需要录制的响应头
+var recordedHeaders = []string{"Content-Type", "Content-Disposition"}
+
+// newScrubber 创建只清除指定字段的脱敏器
+func newScrubber(fields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]struct{})}
+	return r.AddFields(fields...)
+}
+
+// RecordingClient 录制HTTP往返的客户端，用于生成离线测试的录制文件
+// 请求和响应中的access_token、secret等凭证在写入文件前清除
+//
+//	recorder := core.NewRecordingClient(http.DefaultClient, "testdata/menu.json")
+//	defer recorder.Save()
+//	client := official_account.NewMPClientWithStorage(config, store, recorder)
+type RecordingClient struct {
+	mu           sync.Mutex
+	client       HTTPClient
+	fixture      string
+	scrubber     *Redactor
+	interactions []Interaction
+}
+
+// NewRecordingClient 创建录制客户端
+// @param client HTTPClient 实际发送请求的客户端，为nil时使用 http.DefaultClient
+// @param fixture string 录制文件路径
+// @param scrubFields ...string 额外需要清除的字段名
+// @return *RecordingClient 录制客户端
+func NewRecordingClient(client HTTPClient, fixture string, scrubFields ...string) *RecordingClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	scrubber := newScrubber(DefaultScrubFields...)
+	scrubber.AddFields(scrubFields...)
+	return &RecordingClient{
+		client:   client,
+		fixture:  fixture,
+		scrubber: scrubber,
+	}
+}
+
+// Do 实现 HTTPClient 接口，发送请求并录制往返
+func (c *RecordingClient) Do(req *http.Request) (*http.Response, error) {
+	recorded, err := normalizeRequest(req, c.scrubber)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	response := RecordedResponse{StatusCode: resp.StatusCode, Header: make(map[string]string)}
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			response.Header[name] = value
+		}
+	}
+	if utf8.Valid(body) {
+		response.Body = c.scrubber.Body(body)
+	} else {
+		response.BodyBytes = body
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{Request: recorded, Response: response})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// Interactions 返回已录制的往返
+func (c *RecordingClient) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save 将录制结果写入录制文件，文件已存在时覆盖
+func (c *RecordingClient) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化录制结果失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.fixture), 0755); err != nil {
+		return fmt.Errorf("创建录制目录失败: %w", err)
+	}
+	if err := os.WriteFile(c.fixture, data, 0644); err != nil {
+		return fmt.Errorf("写入录制文件失败: %w", err)
+	}
+	return nil
+}
+
+// ReplayingClient 回放录制文件的客户端，不发送任何网络请求
+//
+// 请求按方法、路径、规范化后的查询参数和请求体匹配，凭证在匹配前清除，因此测试中的token取值不影响匹配。
+// 同一请求录制了多次时按录制顺序依次返回，用完后重复返回最后一次的响应。
+// 没有匹配的录制记录时返回 *UnmatchedRequestError，不会重试或故障转移。
+type ReplayingClient struct {
+	mu           sync.Mutex
+	fixture      string
+	scrubber     *Redactor
+	interactions []Interaction
+	used         []bool
+	unmatched    []RecordedRequest
+}
+
+// NewReplayingClient 加载录制文件并创建回放客户端
+// @param fixture string 录制文件路径
+// @param scrubFields ...string 额外需要清除的字段名，需与录制时一致
+// @return *ReplayingClient 回放客户端
+// @return error 读取或解析录制文件失败
+func NewReplayingClient(fixture string, scrubFields ...string) (*ReplayingClient, error) {
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %w", err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("解析录制文件失败: %w", err)
+	}
+
+	scrubber := newScrubber(DefaultScrubFields...)
+	scrubber.AddFields(scrubFields...)
+	return &ReplayingClient{
+		fixture:      fixture,
+		scrubber:     scrubber,
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// Do 实现 HTTPClient 接口，返回匹配的录制响应
+func (c *ReplayingClient) Do(req *http.Request) (*http.Response, error) {
+	recorded, err := normalizeRequest(req, c.scrubber)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	for i, interaction := range c.interactions {
+		if interaction.Request != recorded {
+			continue
+		}
+		last = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if last < 0 {
+		c.unmatched = append(c.unmatched, recorded)
+		return nil, &UnmatchedRequestError{Fixture: c.fixture, Request: recorded}
+	}
+	c.used[last] = true
+
+	response := c.interactions[last].Response
+	body := response.BodyBytes
+	if body == nil {
+		body = []byte(response.Body)
+	}
+	header := make(http.Header)
+	for name, value := range response.Header {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		StatusCode:    response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Unmatched 返回没有匹配到录制记录的请求
+func (c *ReplayingClient) Unmatched() []RecordedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]RecordedRequest(nil), c.unmatched...)
+}
+
+// Unused 返回从未被回放的录制记录，可用于检查测试是否覆盖了全部录制的调用
+func (c *ReplayingClient) Unused() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range c.interactions {
+		if !c.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// normalizeRequest 清除凭证并规范化请求，读取后恢复请求体以便继续发送
+func normalizeRequest(req *http.Request, scrubber *Redactor) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+	if recorded.Method == "" {
+		recorded.Method = http.MethodGet
+	}
+
+	query := req.URL.Query()
+	for key := range query {
+		if scrubber.isSensitive(key) {
+			query.Set(key, RedactedValue)
+		}
+	}
+	recorded.Query = query.Encode()
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+	// multipart请求体包含随机分隔符且可能很大，不参与匹配
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); strings.HasPrefix(mediaType, "multipart/") {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return recorded, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	recorded.Body = scrubber.Body(body)
+	return recorded, nil
+}
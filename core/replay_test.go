@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"REAL_TOKEN","menu":{"button":[]}}`))
+	}))
+	defer srv.Close()
+
+	fixture := filepath.Join(t.TempDir(), "menu.json")
+	recorder := NewRecordingClient(http.DefaultClient, fixture)
+	req := NewRequest(recorder, logger.NewDefaultLoggerInterface())
+	body := map[string]interface{}{"b": 2, "a": 1, "secret": "REAL_SECRET"}
+	if err := req.Make(context.Background(), &ReqMakeOpt{Method: "POST", URL: srv.URL + "/cgi-bin/menu/create?access_token=REAL_TOKEN&x=1", Body: body}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(fixture)
+	if strings.Contains(string(data), "REAL_TOKEN") || strings.Contains(string(data), "REAL_SECRET") {
+		t.Fatalf("credentials not scrubbed: %s", data)
+	}
+
+	replayer, err := NewReplayingClient(fixture)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	req = NewRequest(replayer, logger.NewDefaultLoggerInterface())
+
+	// token、密钥取值和字段顺序不同仍然匹配
+	var result struct {
+		APIResponse
+		AccessToken string `json:"access_token"`
+	}
+	err = req.Make(context.Background(), &ReqMakeOpt{
+		Method: "POST",
+		URL:    "https://api.weixin.qq.com/cgi-bin/menu/create?x=1&access_token=OTHER",
+		Body:   map[string]interface{}{"a": 1, "secret": "OTHER", "b": 2},
+		Result: &result,
+	})
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if result.AccessToken != RedactedValue {
+		t.Fatalf("unexpected replayed token: %q", result.AccessToken)
+	}
+
+	err = req.Make(context.Background(), &ReqMakeOpt{Method: "POST", URL: "https://api.weixin.qq.com/cgi-bin/menu/create", Body: map[string]int{"a": 2}})
+	if !errors.Is(err, ErrUnmatchedRequest) {
+		t.Fatalf("expected unmatched error, got: %v", err)
+	}
+	if len(replayer.Unmatched()) != 1 || len(replayer.Unused()) != 0 {
+		t.Fatalf("unexpected replay state: unmatched=%v unused=%v", replayer.Unmatched(), replayer.Unused())
+	}
+}
@@ -4,33 +4,203 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/jcbowen/jcbaseGo/component/helper"
 	"github.com/jcbowen/wego/logger"
 )
 
 type ReqMakeOpt struct {
-	Method string      // HTTP方法，如GET、POST等
-	URL    string      // 请求URL
-	Query  interface{} // 查询参数，支持map[string]string或结构体
-	Body   interface{} // 请求体
-	Result interface{} // 响应体，需要传入指针
+	Method    string      // HTTP方法，如GET、POST等
+	URL       string      // 请求URL
+	Query     interface{} // 查询参数，支持map[string]string或结构体
+	Body      interface{} // 请求体
+	Multipart *Multipart  // multipart/form-data 请求体，设置后忽略Body，用于上传素材等接口
+	Result    interface{} // 响应体，需要传入指针
 }
 
+// TokenRefreshFunc access_token失效时的刷新函数
+// @param ctx 上下文
+// @param param 携带token的查询参数名，如 access_token、component_access_token
+// @param staleToken 被微信拒绝的token
+// @return string 新的token，返回空字符串表示无法刷新，不再重试
+// @return error 错误信息
+type TokenRefreshFunc func(ctx context.Context, param, staleToken string) (string, error)
+
+// AppIDResolver 根据请求URL解析调用方appid，用于按appid限流
+type AppIDResolver func(requestURL *url.URL) string
+
+// tokenParams 可自动刷新的token查询参数
+var tokenParams = []string{"access_token", "component_access_token"}
+
 type Request struct {
-	httpClient HTTPClient
-	logger     logger.LoggerInterface
+	httpClient     HTTPClient
+	logger         logger.LoggerInterface
+	tokenRefresher TokenRefreshFunc
+	middlewares    []Middleware
+	redactor       *Redactor
+	rateLimiter    *RateLimiter
+	appIDResolver  AppIDResolver
+	retryPolicy    *RetryPolicy
+	breaker        *CircuitBreaker
+	resolver       EndpointResolver
+	metrics        Metrics
+	tracer         Tracer
 }
 
 func NewRequest(httpClient HTTPClient, logger logger.LoggerInterface) *Request {
 	return &Request{
 		httpClient: httpClient,
 		logger:     logger,
+		redactor:   NewRedactor(),
+		metrics:    NopMetrics{},
+		tracer:     NopTracer{},
+	}
+}
+
+// SetRedactor 设置日志脱敏器，为nil时使用默认规则
+func (r *Request) SetRedactor(redactor *Redactor) {
+	if redactor == nil {
+		redactor = NewRedactor()
 	}
+	r.redactor = redactor
+}
+
+// redactError 对底层HTTP错误中携带的URL脱敏，避免access_token随错误信息泄露
+func (r *Request) redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = r.redactor.URL(urlErr.URL)
+	}
+	return err
+}
+
+// SetRateLimiter 设置限流器，为nil时不限流
+// 限流按appid和接口路径区分，appid由 SetAppIDResolver 设置的函数解析
+func (r *Request) SetRateLimiter(limiter *RateLimiter) {
+	r.rateLimiter = limiter
+}
+
+// SetRetryPolicy 设置重试策略，为nil时不重试
+func (r *Request) SetRetryPolicy(policy *RetryPolicy) {
+	r.retryPolicy = policy
+}
+
+// SetCircuitBreaker 设置熔断器，为nil时不熔断
+func (r *Request) SetCircuitBreaker(breaker *CircuitBreaker) {
+	r.breaker = breaker
+}
+
+// SetEndpointResolver 设置接口地址解析器，用于替换请求域名和故障转移，为nil时按原URL发送
+func (r *Request) SetEndpointResolver(resolver EndpointResolver) {
+	r.resolver = resolver
+}
+
+// SetMetrics 设置指标收集器，为nil时不收集
+func (r *Request) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	r.metrics = metrics
+}
+
+// observe 记录一次接口调用的耗时和错误码
+func (r *Request) observe(options *ReqMakeOpt, requestURL string, start time.Time, err error) {
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	endpoint := endpointPath(requestURL)
+	errCode := ErrCodeLabel(err)
+
+	r.metrics.ObserveHistogram(MetricRequestDuration, time.Since(start).Seconds(), Labels{
+		"endpoint": endpoint,
+		"method":   method,
+		"errcode":  errCode,
+	})
+	if err != nil {
+		r.metrics.IncCounter(MetricRequestErrors, Labels{"endpoint": endpoint, "errcode": errCode})
+	}
+}
+
+// SetTracer 设置链路追踪，为nil时不追踪
+func (r *Request) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		tracer = NopTracer{}
+	}
+	r.tracer = tracer
+}
+
+// startSpan 开始一次接口调用的span
+func (r *Request) startSpan(ctx context.Context, options *ReqMakeOpt, requestURL string) (context.Context, Span) {
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return r.tracer.Start(ctx, SpanRequest, Attr(AttrEndpoint, endpointPath(requestURL)), Attr(AttrMethod, method))
+}
+
+// endSpan 结束接口调用的span，成功时错误码为0
+func endSpan(span Span, err error) {
+	if err == nil {
+		span.SetAttributes(Attr(AttrErrCode, 0))
+	}
+	EndSpan(span, err)
+}
+
+// SetAppIDResolver 设置调用方appid的解析函数
+func (r *Request) SetAppIDResolver(resolver AppIDResolver) {
+	r.appIDResolver = resolver
+}
+
+// appID 解析请求的调用方appid
+func (r *Request) appID(requestURL *url.URL) string {
+	if r.appIDResolver == nil {
+		return ""
+	}
+	return r.appIDResolver(requestURL)
+}
+
+// acquire 申请调用额度
+func (r *Request) acquire(ctx context.Context, requestURL *url.URL) error {
+	if r.rateLimiter == nil {
+		return nil
+	}
+	if err := r.rateLimiter.Wait(ctx, r.appID(requestURL), requestURL.Path); err != nil {
+		r.logger.Warn(fmt.Sprintf("请求被限流: %v", err))
+		return err
+	}
+	return nil
+}
+
+// observeQuota 微信返回45009时标记当日额度已用尽
+func (r *Request) observeQuota(ctx context.Context, requestURL string, err error) {
+	if r.rateLimiter == nil || !errors.Is(err, ErrQuotaExceeded) {
+		return
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return
+	}
+	parsedURL, parseErr := url.Parse(requestURL)
+	if parseErr != nil {
+		return
+	}
+	if exhaustErr := r.rateLimiter.Exhaust(ctx, r.appID(parsedURL), parsedURL.Path); exhaustErr != nil {
+		r.logger.Warn(fmt.Sprintf("更新限流状态失败: %v", exhaustErr))
+	}
+}
+
+// SetTokenRefresher 设置token失效时的刷新函数
+// 设置后，微信返回40001/40014/42001时会清除缓存的token、获取新token并重试一次
+func (r *Request) SetTokenRefresher(fn TokenRefreshFunc) {
+	r.tokenRefresher = fn
 }
 
 // Make 发送HTTP请求的通用方法
@@ -40,127 +210,407 @@ func NewRequest(httpClient HTTPClient, logger logger.LoggerInterface) *Request {
 //   - options: 请求配置选项，包含方法、URL、查询参数、请求体和结果接收器
 //
 // 返回:
-//   - error: 请求执行过程中的错误，包含详细的错误信息
+//   - error: 请求执行过程中的错误，微信返回非0错误码或HTTP状态码异常时为 *Error
 //
 // 功能:
 //   - 支持GET、POST等常用HTTP方法
 //   - 自动处理查询参数拼接，支持map[string]string和结构体
 //   - 支持JSON格式的请求体和响应体
-//   - 自动验证HTTP响应状态码
+//   - 支持multipart/form-data流式上传文件
+//   - 自动验证HTTP响应状态码和微信错误码
 //   - 提供详细的日志记录
+//   - 设置了TokenRefreshFunc时，token失效会自动刷新并重试一次
 func (r *Request) Make(ctx context.Context, options *ReqMakeOpt) error {
-	method := options.Method
+	requestURL, err := buildURL(options)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	ctx, span := r.startSpan(ctx, options, requestURL)
+	err = r.withTokenRetry(ctx, requestURL, options, func(requestURL string) error {
+		return r.withRetry(ctx, requestURL, options, func() error {
+			return r.withFailover(ctx, requestURL, options, func(requestURL string) error {
+				return r.do(ctx, requestURL, options)
+			})
+		})
+	})
+	endSpan(span, err)
+	r.observe(options, requestURL, start, err)
+	return err
+}
+
+// buildURL 拼接查询参数，返回完整的请求URL
+func buildURL(options *ReqMakeOpt) (string, error) {
 	requestURL := options.URL
-	query := options.Query
-	body := options.Body
-	result := options.Result
-
-	// 处理查询参数
-	if query != nil {
-		queryMap := helper.Convert{Value: query}.ToMapString()
-		if len(queryMap) > 0 {
-			parsedURL, err := url.Parse(requestURL)
-			if err != nil {
-				return fmt.Errorf("解析URL失败: %v", err)
+	if options.Query == nil {
+		return requestURL, nil
+	}
+
+	queryMap := helper.Convert{Value: options.Query}.ToMapString()
+	if len(queryMap) == 0 {
+		return requestURL, nil
+	}
+
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("解析URL失败: %v", err)
+	}
+
+	// 构建查询参数
+	q := parsedURL.Query()
+	for key, value := range queryMap {
+		q.Set(key, value)
+	}
+	parsedURL.RawQuery = q.Encode()
+	return parsedURL.String(), nil
+}
+
+// withTokenRetry 执行请求，token失效时刷新token并重试一次
+func (r *Request) withTokenRetry(ctx context.Context, requestURL string, options *ReqMakeOpt, attempt func(requestURL string) error) error {
+	// 记录文件读取位置，token失效重试时需要重新发送文件内容
+	var offsets []int64
+	if options.Multipart != nil {
+		offsets = options.Multipart.offsets()
+	}
+
+	err := attempt(requestURL)
+	r.observeQuota(ctx, requestURL, err)
+	if err == nil || r.tokenRefresher == nil || !errors.Is(err, ErrTokenInvalid) {
+		return err
+	}
+	if options.Multipart != nil && !options.Multipart.rewind(offsets) {
+		return err
+	}
+
+	// token失效，刷新后重试一次
+	retryURL, ok := r.refreshTokenInURL(ctx, requestURL)
+	if !ok {
+		return err
+	}
+	r.logger.Warn(fmt.Sprintf("access_token已失效，刷新后重试: %v", err))
+	err = attempt(retryURL)
+	r.observeQuota(ctx, retryURL, err)
+	return err
+}
+
+// withRetry 经过熔断器执行请求，失败时按重试策略退避重试
+func (r *Request) withRetry(ctx context.Context, requestURL string, options *ReqMakeOpt, attempt func() error) error {
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	endpoint := stripQuery(requestURL)
+
+	var offsets []int64
+	if options.Multipart != nil {
+		offsets = options.Multipart.offsets()
+	}
+	policy := r.retryPolicy
+	if p, ok := ctx.Value(retryPolicyKey{}).(*RetryPolicy); ok {
+		policy = p
+	}
+	if policy != nil && policy.Budget != nil {
+		policy.Budget.deposit()
+	}
+
+	for i := 0; ; i++ {
+		if r.breaker != nil {
+			if err := r.breaker.Allow(endpoint); err != nil {
+				r.logger.Warn(err.Error())
+				return err
 			}
+		}
+
+		err := attempt()
+		if r.breaker != nil {
+			r.breaker.Record(endpoint, err)
+		}
+		if err == nil || policy == nil || !policy.ShouldRetry(i, method, err) {
+			return err
+		}
+		if options.Multipart != nil && !options.Multipart.rewind(offsets) {
+			return err
+		}
+
+		delay := policy.Backoff(i)
+		r.logger.Warn(fmt.Sprintf("请求失败，%s 后进行第%d次重试: %v", delay, i+1, err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
 
-			// 构建查询参数
-			q := parsedURL.Query()
-			for key, value := range queryMap {
-				q.Set(key, value)
+// withFailover 按解析器返回的域名依次发送请求，连接失败或5xx响应时切换到下一个域名
+// 非GET请求只在建立连接失败时切换，避免已送达的请求被重复执行
+func (r *Request) withFailover(ctx context.Context, requestURL string, options *ReqMakeOpt, attempt func(requestURL string) error) error {
+	if r.resolver == nil {
+		return attempt(requestURL)
+	}
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return attempt(requestURL)
+	}
+	baseURLs := r.resolver.Resolve(parsedURL)
+	if len(baseURLs) == 0 {
+		return attempt(requestURL)
+	}
+
+	var offsets []int64
+	if options.Multipart != nil {
+		offsets = options.Multipart.offsets()
+	}
+
+	var lastErr error
+	for i, baseURL := range baseURLs {
+		if i > 0 {
+			if options.Multipart != nil && !options.Multipart.rewind(offsets) {
+				return lastErr
 			}
-			parsedURL.RawQuery = q.Encode()
-			requestURL = parsedURL.String()
+			r.logger.Warn(fmt.Sprintf("请求失败，切换到备用域名 %s: %v", baseURL, lastErr))
+		}
+
+		rebasedURL, err := rebase(parsedURL, baseURL)
+		if err != nil {
+			return fmt.Errorf("替换请求域名失败: %w", err)
+		}
+
+		err = attempt(rebasedURL)
+		r.resolver.Report(baseURL, err)
+		if err == nil || !IsFailoverError(err) {
+			return err
+		}
+		if options.Method != "" && options.Method != http.MethodGet && !isDialError(err) {
+			return err
 		}
+		lastErr = err
 	}
+	return lastErr
+}
 
+// send 构建并发送一次HTTP请求，返回未读取的响应，调用方负责关闭响应体
+func (r *Request) send(ctx context.Context, requestURL string, options *ReqMakeOpt) (*http.Response, error) {
 	var reqBody []byte
-	if body != nil {
+	var bodyReader io.Reader
+	var contentType string
+	contentLength := int64(-1)
+	switch {
+	case options.Multipart != nil:
+		var err error
+		bodyReader, contentType, contentLength, err = options.Multipart.build()
+		if err != nil {
+			return nil, err
+		}
+	case options.Body != nil:
 		var err error
-		reqBody, err = json.Marshal(body)
+		reqBody, err = json.Marshal(options.Body)
 		if err != nil {
-			return fmt.Errorf("序列化请求体失败: %v", err)
+			return nil, fmt.Errorf("序列化请求体失败: %v", err)
 		}
+		bodyReader = bytes.NewReader(reqBody)
+		contentType = "application/json"
+	default:
+		bodyReader = http.NoBody
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, options.Method, requestURL, bodyReader)
 	if err != nil {
-		return fmt.Errorf("创建请求失败: %v", err)
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	if err = r.acquire(ctx, req.URL); err != nil {
+		return nil, err
 	}
 
 	// 设置请求头
-	if len(reqBody) > 0 {
-		req.Header.Set("Content-Type", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if options.Multipart != nil {
+		req.ContentLength = contentLength
 	}
 
-	// 记录请求日志
-    r.logger.Info(fmt.Sprintf("发送HTTP请求 - Method: %s URL: %s", req.Method, req.URL.String()), map[string]interface{}{
+	// 记录请求日志，请求元信息为Info级别，请求体为Debug级别
+	redactedURL := r.redactor.URL(requestURL)
+	r.logger.Info(fmt.Sprintf("发送HTTP请求 - Method: %s URL: %s", req.Method, redactedURL), map[string]interface{}{
 		"request_method":  req.Method,
-		"request_url":     req.URL.String(),
-		"request_headers": req.Header,
-		"request_body":    string(reqBody),
+		"request_url":     redactedURL,
+		"request_headers": r.redactor.Header(req.Header),
 	})
+	if len(reqBody) > 0 {
+		logger.Debug(r.logger, "HTTP请求体", map[string]interface{}{
+			"request_url":  redactedURL,
+			"request_body": r.redactor.Body(reqBody),
+		})
+	}
+	if options.Multipart != nil {
+		logger.Debug(r.logger, "HTTP上传文件", map[string]interface{}{
+			"request_url":    redactedURL,
+			"files":          options.Multipart.fileNames(),
+			"fields":         r.redactor.String(fmt.Sprint(options.Multipart.Fields)),
+			"content_length": contentLength,
+		})
+	}
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.roundTrip(req)
 	if err != nil {
+		err = r.redactError(err)
 		r.logger.Error(fmt.Sprintf("发送请求失败: %v", err), map[string]interface{}{
-			"url": requestURL,
+			"url": redactedURL,
 		})
-		return fmt.Errorf("发送请求失败: %v", err)
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	return resp, nil
+}
+
+// do 执行一次HTTP请求并解析响应
+func (r *Request) do(ctx context.Context, requestURL string, options *ReqMakeOpt) error {
+	resp, err := r.send(ctx, requestURL, options)
+	if err != nil {
+		return err
+	}
+	defer r.closeBody(resp.Body)
+
+	respBody, err := r.readBody(resp, requestURL)
+	if err != nil {
+		return err
+	}
+	if err = r.checkResponse(resp.StatusCode, respBody, requestURL); err != nil {
+		return err
 	}
-	defer func(Body io.ReadCloser) {
-		if closeErr := Body.Close(); closeErr != nil {
-			r.logger.Error(fmt.Sprintf("关闭响应体失败: %v", closeErr))
+
+	// 处理空响应
+	if len(respBody) == 0 {
+		if options.Result != nil {
+			r.logger.Info("响应体为空，跳过JSON解析")
+		}
+		return nil
+	}
+
+	// 解析JSON响应
+	if options.Result != nil {
+		if err = json.Unmarshal(respBody, options.Result); err != nil {
+			r.logger.Error(fmt.Sprintf("解析响应失败: %v, 响应内容: %s", err, r.redactor.Body(respBody)))
+			return fmt.Errorf("解析响应失败: %v", err)
 		}
-	}(resp.Body)
+	}
+
+	return nil
+}
 
+// readBody 读取完整响应体并记录响应日志
+func (r *Request) readBody(resp *http.Response, requestURL string) ([]byte, error) {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		r.logger.Error(fmt.Sprintf("读取响应体失败: %v", err), map[string]interface{}{
 			"status_code": resp.StatusCode,
 		})
-		return fmt.Errorf("读取响应体失败: %v", err)
+		return nil, fmt.Errorf("读取响应体失败: %v", err)
 	}
 
-	// 记录响应日志
-    r.logger.Info(fmt.Sprintf("HTTP响应 - 状态码: %d, 内容长度: %d", resp.StatusCode, len(respBody)), map[string]interface{}{
+	// 记录响应日志，响应元信息为Info级别，响应体为Debug级别
+	r.logger.Info(fmt.Sprintf("HTTP响应 - 状态码: %d, 内容长度: %d", resp.StatusCode, len(respBody)), map[string]interface{}{
 		"status_code":     resp.StatusCode,
 		"response_length": len(respBody),
-		"response_body":   string(respBody),
 	})
+	logger.Debug(r.logger, "HTTP响应体", map[string]interface{}{
+		"request_url":   r.redactor.URL(requestURL),
+		"response_body": r.redactor.Body(respBody),
+	})
+	return respBody, nil
+}
 
+// checkResponse 检查HTTP状态码和微信错误码，异常时返回 *Error
+func (r *Request) checkResponse(statusCode int, respBody []byte, requestURL string) error {
 	// 检查HTTP状态码
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		r.logger.Error(fmt.Sprintf("HTTP请求失败 - 状态码: %d, 响应: %s", resp.StatusCode, string(respBody)), map[string]interface{}{
-			"status_code": resp.StatusCode,
+	if statusCode < 200 || statusCode >= 300 {
+		redactedBody := r.redactor.Body(respBody)
+		r.logger.Error(fmt.Sprintf("HTTP请求失败 - 状态码: %d, 响应: %s", statusCode, redactedBody), map[string]interface{}{
+			"status_code": statusCode,
 		})
-		return fmt.Errorf("HTTP请求失败 - 状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+		apiResp := APIResponse{ErrMsg: redactedBody}
+		_ = json.Unmarshal(respBody, &apiResp)
+		return NewError(&apiResp, requestURL, statusCode)
 	}
 
-	// 处理空响应
-	if len(respBody) == 0 {
-		if result != nil {
-            r.logger.Info("响应体为空，跳过JSON解析")
-		}
-		return nil
+	// 检查微信错误码，响应体不是JSON对象时忽略
+	var apiResp APIResponse
+	if len(respBody) > 0 && json.Unmarshal(respBody, &apiResp) == nil && !apiResp.IsSuccess() {
+		apiErr := NewError(&apiResp, requestURL, statusCode)
+		r.logger.Warn(apiErr.Error(), map[string]interface{}{
+			"errcode": apiErr.ErrCode,
+			"errmsg":  apiErr.ErrMsg,
+			"rid":     apiErr.Rid,
+		})
+		return apiErr
 	}
+	return nil
+}
 
-	// 解析JSON响应
-	if result != nil {
-		if err = json.Unmarshal(respBody, result); err != nil {
-			r.logger.Error(fmt.Sprintf("解析响应失败: %v, 响应内容: %s", err, string(respBody)))
-			return fmt.Errorf("解析响应失败: %v", err)
+// closeBody 关闭响应体
+func (r *Request) closeBody(body io.ReadCloser) {
+	if closeErr := body.Close(); closeErr != nil {
+		r.logger.Error(fmt.Sprintf("关闭响应体失败: %v", closeErr))
+	}
+}
+
+// refreshTokenInURL 刷新URL中失效的token，返回替换后的URL
+// 网页授权（/sns/）接口使用的是用户授权token，无法由客户端刷新，不做处理
+func (r *Request) refreshTokenInURL(ctx context.Context, requestURL string) (string, bool) {
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil || strings.HasPrefix(parsedURL.Path, "/sns/") {
+		return "", false
+	}
+
+	q := parsedURL.Query()
+	for _, param := range tokenParams {
+		staleToken := q.Get(param)
+		if staleToken == "" {
+			continue
+		}
+
+		newToken, err := r.tokenRefresher(ctx, param, staleToken)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("刷新失效的%s失败: %v", param, err))
+			return "", false
+		}
+		if newToken == "" || newToken == staleToken {
+			return "", false
 		}
+
+		q.Set(param, newToken)
+		parsedURL.RawQuery = q.Encode()
+		return parsedURL.String(), true
 	}
 
-	return nil
+	return "", false
 }
 
 // MakeRaw 发送原始HTTP请求，返回响应对象
 func (r *Request) MakeRaw(req *http.Request) (*http.Response, error) {
-	resp, err := r.httpClient.Do(req)
+	// 原始请求的请求体可能无法重复读取，只替换域名，不做故障转移
+	if r.resolver != nil {
+		if baseURLs := r.resolver.Resolve(req.URL); len(baseURLs) > 0 {
+			rebasedURL, err := rebase(req.URL, baseURLs[0])
+			if err != nil {
+				return nil, fmt.Errorf("替换请求域名失败: %w", err)
+			}
+			if req.URL, err = url.Parse(rebasedURL); err != nil {
+				return nil, fmt.Errorf("替换请求域名失败: %w", err)
+			}
+			req.Host = ""
+		}
+	}
+	if err := r.acquire(req.Context(), req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := r.roundTrip(req)
 	if err != nil {
-		return nil, fmt.Errorf("发送请求失败: %v", err)
+		return nil, fmt.Errorf("发送请求失败: %w", r.redactError(err))
 	}
 	return resp, nil
 }
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestMakeReturnsTypedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential rid: 64f1c2a3-1b2c3d4e-5f6a7b8c"}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	var result APIResponse
+	err := req.Make(context.Background(), &ReqMakeOpt{
+		Method: "GET",
+		URL:    srv.URL + "/cgi-bin/menu/get?access_token=secret",
+		Result: &result,
+	})
+
+	if !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("expected ErrTokenInvalid, got: %v", err)
+	}
+	if errors.Is(err, ErrSystemBusy) {
+		t.Fatalf("unexpected ErrSystemBusy match")
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got: %T", err)
+	}
+	if apiErr.Rid != "64f1c2a3-1b2c3d4e-5f6a7b8c" {
+		t.Fatalf("unexpected rid: %s", apiErr.Rid)
+	}
+	if apiErr.Endpoint != srv.URL+"/cgi-bin/menu/get" {
+		t.Fatalf("endpoint should not contain query, got: %s", apiErr.Endpoint)
+	}
+	if apiErr.HTTPStatus != http.StatusOK {
+		t.Fatalf("unexpected http status: %d", apiErr.HTTPStatus)
+	}
+}
+
+func TestMakeHTTPStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	err := req.Make(context.Background(), &ReqMakeOpt{Method: "GET", URL: srv.URL})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadGateway {
+		t.Fatalf("expected *Error with status 502, got: %v", err)
+	}
+}
+
+func TestMakeRefreshesStaleToken(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("access_token") != "new" {
+			_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetTokenRefresher(func(ctx context.Context, param, staleToken string) (string, error) {
+		if param != "access_token" || staleToken != "old" {
+			t.Fatalf("unexpected refresh: %s=%s", param, staleToken)
+		}
+		return "new", nil
+	})
+
+	err := req.Make(context.Background(), &ReqMakeOpt{Method: "GET", URL: srv.URL + "/cgi-bin/menu/get?access_token=old"})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected exactly 2 calls, got %d", calls)
+	}
+}
+
+func TestMakeMultipartRetry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		file, header, err := r.FormFile("media")
+		if err != nil {
+			t.Fatalf("read form file: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "a.mp4" || string(content) != "video" || r.FormValue("description") != `{"title":"t"}` {
+			t.Fatalf("unexpected multipart body: %s %q %q", header.Filename, content, r.FormValue("description"))
+		}
+		if r.ContentLength <= 0 {
+			t.Fatalf("expected known content length, got %d", r.ContentLength)
+		}
+		if r.URL.Query().Get("access_token") != "new" {
+			_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"media_id":"m1"}`))
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetTokenRefresher(func(ctx context.Context, param, staleToken string) (string, error) {
+		return "new", nil
+	})
+
+	var result struct {
+		MediaID string `json:"media_id"`
+	}
+	err := req.Make(context.Background(), &ReqMakeOpt{
+		Method: "POST",
+		URL:    srv.URL + "/cgi-bin/material/add_material?access_token=old",
+		Multipart: &Multipart{
+			Files:  []MultipartFile{{FieldName: "media", FileName: "a.mp4", Reader: strings.NewReader("video")}},
+			Fields: map[string]string{"description": `{"title":"t"}`},
+		},
+		Result: &result,
+	})
+	if err != nil || result.MediaID != "m1" {
+		t.Fatalf("expected retry to succeed, got: %v %+v", err, result)
+	}
+	if calls != 2 {
+		t.Fatalf("expected exactly 2 calls, got %d", calls)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Trace")))
+	}))
+	defer srv.Close()
+
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next RoundTrip) RoundTrip {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				req.Header.Set("X-Trace", req.Header.Get("X-Trace")+name)
+				return next(req)
+			}
+		}
+	}
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.Use(mark("a"), mark("b"))
+
+	resp, err := req.MakeRaw(httptestRequest(t, srv.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if len(order) != 2 || order[0] != "a" || order[1] != "b" {
+		t.Fatalf("unexpected middleware order: %v", order)
+	}
+}
+
+func httptestRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy 重试策略，使用带随机抖动的指数退避
+//
+// 默认只重试以下两类错误，避免非幂等请求被重复执行：
+//   - 微信返回系统繁忙（errcode -1），此时请求未被处理，任何方法都可重试
+//   - GET请求的网络错误和5xx响应
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数，包含首次请求，默认3
+	BaseDelay   time.Duration // 首次重试的基础等待时间，默认200ms
+	MaxDelay    time.Duration // 单次等待时间上限，默认5s
+	Multiplier  float64       // 退避倍数，默认2
+	Jitter      float64       // 随机抖动比例，取值0~1，默认0.5，即等待时间在[50%, 100%]之间随机
+	Budget      *RetryBudget  // 重试预算，可在多个客户端间共享，为nil时不限制
+
+	// Retryable 自定义是否重试，为nil时使用 IsRetryable
+	Retryable func(method string, err error) bool
+}
+
+// DefaultRetryPolicy 返回默认重试策略
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.5,
+	}
+}
+
+// WithMaxAttempts 返回修改了最大尝试次数的策略副本
+func (p *RetryPolicy) WithMaxAttempts(maxAttempts int) *RetryPolicy {
+	clone := *p
+	clone.MaxAttempts = maxAttempts
+	return &clone
+}
+
+// Do 按策略执行函数，失败且可重试时等待后重试
+// @param ctx context.Context 上下文，取消时停止等待
+// @param method string 请求方法，用于判断是否为幂等请求
+// @param fn func() error 需要执行的函数
+// @return error 最后一次执行的错误
+func (p *RetryPolicy) Do(ctx context.Context, method string, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !p.ShouldRetry(attempt, method, err) {
+			return err
+		}
+
+		timer := time.NewTimer(p.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy 为单次调用指定重试策略，覆盖客户端设置的重试策略
+// @param ctx context.Context 上下文
+// @param policy *RetryPolicy 重试策略
+// @return context.Context 新的上下文
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// ShouldRetry 判断第attempt次（从0开始）执行失败后是否重试，需要重试时会消耗重试预算
+func (p *RetryPolicy) ShouldRetry(attempt int, method string, err error) bool {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	if attempt+1 >= maxAttempts {
+		return false
+	}
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	if !retryable(method, err) {
+		return false
+	}
+	return p.Budget == nil || p.Budget.withdraw()
+}
+
+// Backoff 计算第attempt次（从0开始）失败后的等待时间
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	base, maxDelay, multiplier, jitter := p.BaseDelay, p.MaxDelay, p.Multiplier, p.Jitter
+	if base <= 0 {
+		base = 200 * time.Millisecond
+	}
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	if jitter < 0 || jitter > 1 {
+		jitter = 0.5
+	}
+
+	delay := math.Min(float64(base)*math.Pow(multiplier, float64(attempt)), float64(maxDelay))
+	delay -= delay * jitter * rand.Float64()
+	return time.Duration(delay)
+}
+
+// IsRetryable 默认的重试判断
+// 系统繁忙（errcode -1）任何方法都可重试；网络错误和5xx响应仅GET请求重试
+func IsRetryable(method string, err error) bool {
+	if errors.Is(err, ErrSystemBusy) {
+		return true
+	}
+	if method != http.MethodGet {
+		return false
+	}
+	return isTransientError(err)
+}
+
+// isTransientError 判断是否为临时性故障：网络错误、5xx响应或系统繁忙
+func isTransientError(err error) bool {
+	return errors.Is(err, ErrSystemBusy) || IsFailoverError(err)
+}
+
+// RetryBudget 重试预算，限制重试请求占全部请求的比例，防止故障期间重试放大流量
+// 每次请求存入ratio个令牌，每次重试消耗1个令牌，令牌数不超过maxTokens
+type RetryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// NewRetryBudget 创建重试预算
+// @param maxTokens int 令牌上限，即故障开始时最多允许的连续重试次数
+// @param ratio float64 每次请求存入的令牌数，如0.1表示重试请求最多约占10%
+// @return *RetryBudget 重试预算
+func NewRetryBudget(maxTokens int, ratio float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// deposit 记录一次请求
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.maxTokens, b.tokens+b.ratio)
+}
+
+// withdraw 申请一次重试
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+func TestRetryOnlyIdempotentOrSystemBusy(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	_ = req.Make(context.Background(), &ReqMakeOpt{Method: "GET", URL: srv.URL})
+	if calls != 3 {
+		t.Fatalf("GET should be retried, got %d calls", calls)
+	}
+
+	calls = 0
+	_ = req.Make(context.Background(), &ReqMakeOpt{Method: "POST", URL: srv.URL, Body: map[string]string{}})
+	if calls != 1 {
+		t.Fatalf("POST with 5xx should not be retried, got %d calls", calls)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	var transitions []string
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond, func(endpoint string, from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+	busy := &Error{ErrCode: ErrCodeSystemBusy}
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.Allow("e"); err != nil {
+			t.Fatalf("unexpected reject: %v", err)
+		}
+		breaker.Record("e", busy)
+	}
+	if err := breaker.Allow("e"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := breaker.Allow("e"); err != nil {
+		t.Fatalf("half-open probe should pass: %v", err)
+	}
+	if err := breaker.Allow("e"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("only one probe allowed while half-open, got: %v", err)
+	}
+	breaker.Record("e", nil)
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("unexpected transitions: %v", transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("unexpected transitions: %v", transitions)
+		}
+	}
+}
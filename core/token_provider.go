@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultRemoteTokenTTL 远程token服务未返回有效期时，拉取结果在本地缓存的时长
+const DefaultRemoteTokenTTL = time.Minute
+
+// ErrTokenReadOnly token来源只读，不能调用微信接口刷新
+var ErrTokenReadOnly = errors.New("token来源只读，不能刷新")
+
+// TokenProvider access_token来源
+//
+// 客户端获取access_token时调用 AccessToken，微信返回token失效时调用 RefreshAccessToken。
+// 默认由客户端自行调用微信接口获取和刷新；token由外部系统（如中控服务）维护时，
+// 可使用只读来源 StaticTokenProvider、HTTPTokenProvider 或自定义实现，此时客户端不会调用微信接口获取token，
+// 避免使外部系统持有的token失效。
+type TokenProvider interface {
+	// AccessToken 返回appid当前的access_token
+	AccessToken(ctx context.Context, appID string) (string, error)
+
+	// RefreshAccessToken 微信拒绝staleToken后调用，返回新的access_token
+	// 只读来源不得调用微信接口，没有新token时返回 ErrTokenReadOnly
+	RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error)
+}
+
+// StaticTokenProvider 使用注入的access_token，只读
+// 外部系统更新token后通过 SetToken 注入
+type StaticTokenProvider struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+}
+
+// NewStaticTokenProvider 创建使用注入token的来源
+// @param tokens map[string]string appid到access_token的映射，可为nil
+// @return *StaticTokenProvider token来源
+func NewStaticTokenProvider(tokens map[string]string) *StaticTokenProvider {
+	p := &StaticTokenProvider{tokens: make(map[string]string, len(tokens))}
+	for appID, token := range tokens {
+		p.tokens[appID] = token
+	}
+	return p
+}
+
+// SetToken 注入appid的access_token
+func (p *StaticTokenProvider) SetToken(appID, token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokens[appID] = token
+}
+
+// AccessToken 实现 TokenProvider 接口
+func (p *StaticTokenProvider) AccessToken(ctx context.Context, appID string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	token := p.tokens[appID]
+	if token == "" {
+		return "", fmt.Errorf("未注入 %s 的access_token", appID)
+	}
+	return token, nil
+}
+
+// RefreshAccessToken 实现 TokenProvider 接口，已注入新token时返回新token，否则返回 ErrTokenReadOnly
+func (p *StaticTokenProvider) RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if token := p.tokens[appID]; token != "" && token != staleToken {
+		return token, nil
+	}
+	return "", ErrTokenReadOnly
+}
+
+// RemoteToken 远程token服务返回的access_token
+type RemoteToken struct {
+	APIResponse
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // 剩余有效期，单位秒，为0时按 DefaultRemoteTokenTTL 缓存
+}
+
+// RemoteTokenDecodeFunc 解析远程token服务的响应体，用于对接响应格式不同的中控服务
+type RemoteTokenDecodeFunc func(body []byte) (*RemoteToken, error)
+
+// remoteTokenCache 本地缓存的远程token
+type remoteTokenCache struct {
+	token     string
+	expiresAt time.Time
+}
+
+// HTTPTokenProvider 从远程token服务（如中控服务）拉取access_token，只读
+//
+// 以 GET {url}?appid={appid} 拉取，默认响应格式与 /cgi-bin/token 相同：
+//
+//	{"access_token":"ACCESS_TOKEN","expires_in":7200}
+//
+// 拉取结果在本地缓存到有效期结束；微信返回token失效时重新拉取一次，远程服务仍返回失效的token时返回 ErrTokenReadOnly。
+type HTTPTokenProvider struct {
+	url        string
+	httpClient HTTPClient
+	header     http.Header
+	decode     RemoteTokenDecodeFunc
+	group      RefreshGroup
+
+	mu    sync.Mutex
+	cache map[string]remoteTokenCache
+}
+
+// NewHTTPTokenProvider 创建从远程token服务拉取的来源
+// @param serviceURL string 远程token服务地址
+// @return *HTTPTokenProvider token来源
+func NewHTTPTokenProvider(serviceURL string) *HTTPTokenProvider {
+	return &HTTPTokenProvider{
+		url:        serviceURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		header:     make(http.Header),
+		decode:     decodeRemoteToken,
+		cache:      make(map[string]remoteTokenCache),
+	}
+}
+
+// SetHTTPClient 设置HTTP客户端，需要在使用前调用
+// @return *HTTPTokenProvider token来源，便于链式调用
+func (p *HTTPTokenProvider) SetHTTPClient(client HTTPClient) *HTTPTokenProvider {
+	if client != nil {
+		p.httpClient = client
+	}
+	return p
+}
+
+// SetHeader 设置请求头，如远程服务的鉴权信息，需要在使用前调用
+// @return *HTTPTokenProvider token来源，便于链式调用
+func (p *HTTPTokenProvider) SetHeader(key, value string) *HTTPTokenProvider {
+	p.header.Set(key, value)
+	return p
+}
+
+// SetDecoder 设置响应解析函数，需要在使用前调用
+// @return *HTTPTokenProvider token来源，便于链式调用
+func (p *HTTPTokenProvider) SetDecoder(decode RemoteTokenDecodeFunc) *HTTPTokenProvider {
+	if decode != nil {
+		p.decode = decode
+	}
+	return p
+}
+
+// AccessToken 实现 TokenProvider 接口，本地缓存有效时不请求远程服务
+func (p *HTTPTokenProvider) AccessToken(ctx context.Context, appID string) (string, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[appID]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+	return p.pull(ctx, appID)
+}
+
+// RefreshAccessToken 实现 TokenProvider 接口，重新拉取一次，不调用微信接口
+func (p *HTTPTokenProvider) RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error) {
+	p.mu.Lock()
+	if cached, ok := p.cache[appID]; ok && cached.token == staleToken {
+		delete(p.cache, appID)
+	}
+	p.mu.Unlock()
+
+	token, err := p.AccessToken(ctx, appID)
+	if err != nil {
+		return "", err
+	}
+	if token == staleToken {
+		return "", fmt.Errorf("远程token服务返回的access_token已失效: %w", ErrTokenReadOnly)
+	}
+	return token, nil
+}
+
+// pull 从远程服务拉取token并缓存，并发的拉取合并为一次请求
+func (p *HTTPTokenProvider) pull(ctx context.Context, appID string) (string, error) {
+	return RefreshOnce(ctx, &p.group, appID, func(ctx context.Context) (string, error) {
+		start := time.Now()
+		token, err := p.fetch(ctx, appID)
+		if err != nil {
+			return "", fmt.Errorf("从远程服务获取access_token失败: %w", err)
+		}
+
+		ttl := DefaultRemoteTokenTTL
+		if token.ExpiresIn > 0 {
+			ttl = time.Duration(token.ExpiresIn) * time.Second
+		}
+		p.mu.Lock()
+		p.cache[appID] = remoteTokenCache{token: token.AccessToken, expiresAt: start.Add(ttl)}
+		p.mu.Unlock()
+		return token.AccessToken, nil
+	})
+}
+
+// fetch 请求远程服务
+func (p *HTTPTokenProvider) fetch(ctx context.Context, appID string) (*RemoteToken, error) {
+	requestURL, err := url.Parse(p.url)
+	if err != nil {
+		return nil, err
+	}
+	query := requestURL.Query()
+	query.Set("appid", appID)
+	requestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range p.header {
+		req.Header[key] = values
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if _, err := p.decode(body); err != nil {
+			return nil, fmt.Errorf("HTTP状态码 %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("HTTP状态码 %d", resp.StatusCode)
+	}
+
+	token, err := p.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("响应中没有access_token")
+	}
+	return token, nil
+}
+
+// decodeRemoteToken 按 /cgi-bin/token 的格式解析响应
+func decodeRemoteToken(body []byte) (*RemoteToken, error) {
+	var token RemoteToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if !token.IsSuccess() {
+		return nil, NewError(&token.APIResponse, "", http.StatusOK)
+	}
+	return &token, nil
+}
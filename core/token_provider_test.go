@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStaticTokenProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewStaticTokenProvider(map[string]string{"wx1": "token_1"})
+
+	if token, err := provider.AccessToken(ctx, "wx1"); err != nil || token != "token_1" {
+		t.Fatalf("unexpected token %q, %v", token, err)
+	}
+	if _, err := provider.AccessToken(ctx, "wx2"); err == nil {
+		t.Fatal("expected error for missing token")
+	}
+	if _, err := provider.RefreshAccessToken(ctx, "wx1", "token_1"); !errors.Is(err, ErrTokenReadOnly) {
+		t.Fatalf("expected ErrTokenReadOnly, got %v", err)
+	}
+
+	provider.SetToken("wx1", "token_2")
+	if token, err := provider.RefreshAccessToken(ctx, "wx1", "token_1"); err != nil || token != "token_2" {
+		t.Fatalf("unexpected refreshed token %q, %v", token, err)
+	}
+}
+
+func TestHTTPTokenProvider(t *testing.T) {
+	var pulls, version atomic.Int32
+	version.Store(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pulls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"%s_%d","expires_in":7200}`, r.URL.Query().Get("appid"), version.Load())
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	provider := NewHTTPTokenProvider(server.URL+"/token").SetHeader("Authorization", "Bearer secret")
+
+	for i := 0; i < 3; i++ {
+		if token, err := provider.AccessToken(ctx, "wx1"); err != nil || token != "wx1_1" {
+			t.Fatalf("unexpected token %q, %v", token, err)
+		}
+	}
+	if n := pulls.Load(); n != 1 {
+		t.Fatalf("expected cached token, got %d pulls", n)
+	}
+
+	// 远程服务仍返回失效的token时不能刷新
+	if _, err := provider.RefreshAccessToken(ctx, "wx1", "wx1_1"); !errors.Is(err, ErrTokenReadOnly) {
+		t.Fatalf("expected ErrTokenReadOnly, got %v", err)
+	}
+
+	// 远程服务已更新token时重新拉取
+	version.Store(2)
+	if token, err := provider.RefreshAccessToken(ctx, "wx1", "wx1_1"); err != nil || token != "wx1_2" {
+		t.Fatalf("unexpected refreshed token %q, %v", token, err)
+	}
+	if n := pulls.Load(); n != 3 {
+		t.Fatalf("expected 3 pulls, got %d", n)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// 链路追踪的span名称
+const (
+	SpanRequest         = "wego.request"          // 调用微信接口，包含token刷新和重试
+	SpanTokenRefresh    = "wego.token.refresh"    // 从微信获取token
+	SpanStorage         = "wego.storage"          // 存储操作
+	SpanMessageDispatch = "wego.message.dispatch" // 回调消息分发
+)
+
+// 链路追踪的属性名称
+const (
+	AttrEndpoint         = "wechat.endpoint"        // 接口路径
+	AttrMethod           = "http.method"            // 请求方法
+	AttrHTTPStatus       = "http.status_code"       // HTTP状态码
+	AttrErrCode          = "wechat.errcode"         // 微信错误码
+	AttrRid              = "wechat.rid"             // 微信请求ID
+	AttrTokenKind        = "wego.token.kind"        // token类型，取值见 TokenKindAccessToken 等常量
+	AttrStorageBackend   = "wego.storage.backend"   // 存储实现
+	AttrStorageOperation = "wego.storage.operation" // 存储操作
+	AttrMsgType          = "wechat.msg_type"        // 消息类型
+	AttrEvent            = "wechat.event"           // 事件类型
+)
+
+// Attribute span属性
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr 创建span属性
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer 链路追踪接口，可对接OpenTelemetry等追踪系统
+//
+// Start 应从ctx中取出父span，创建子span并放入返回的ctx，
+// 这样同一回调中触发的模板消息发送、客服消息回复等调用会出现在同一条链路中
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span 链路追踪的span
+type Span interface {
+	// SetAttributes 设置属性
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError 记录错误并将span标记为失败
+	RecordError(err error)
+
+	// End 结束span
+	End()
+}
+
+// NopTracer 不做任何处理的链路追踪，未设置链路追踪时使用
+type NopTracer struct{}
+
+// Start 实现 Tracer 接口，原样返回ctx
+func (NopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+// nopSpan 不做任何处理的span
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+func (nopSpan) RecordError(error)          {}
+func (nopSpan) End()                       {}
+
+// EndSpan 记录结果并结束span
+// 错误为 *Error 时附加微信错误码、rid和HTTP状态码
+func EndSpan(span Span, err error) {
+	if err == nil {
+		span.End()
+		return
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		attrs := []Attribute{Attr(AttrErrCode, apiErr.ErrCode)}
+		if apiErr.Rid != "" {
+			attrs = append(attrs, Attr(AttrRid, apiErr.Rid))
+		}
+		if apiErr.HTTPStatus != 0 {
+			attrs = append(attrs, Attr(AttrHTTPStatus, apiErr.HTTPStatus))
+		}
+		span.SetAttributes(attrs...)
+	}
+	span.RecordError(err)
+	span.End()
+}
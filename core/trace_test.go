@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcbowen/wego/logger"
+)
+
+type parentKey struct{}
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(parentKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, parentKey{}, name), span
+}
+
+func TestRequestSpanCarriesErrCodeAndRid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid openid rid: 64f1c2a3-1b2c3d4e-5f6a7b8c"}`))
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	req := NewRequest(http.DefaultClient, logger.NewDefaultLoggerInterface())
+	req.SetTracer(tracer)
+
+	ctx := context.WithValue(context.Background(), parentKey{}, "callback")
+	_ = req.Make(ctx, &ReqMakeOpt{Method: "POST", URL: srv.URL + "/cgi-bin/message/custom/send", Body: map[string]string{}})
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != SpanRequest || span.parent != "callback" || !span.ended || span.err == nil {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+	if span.attrs[AttrErrCode] != 40003 || span.attrs[AttrRid] != "64f1c2a3-1b2c3d4e-5f6a7b8c" {
+		t.Fatalf("missing errcode/rid attributes: %v", span.attrs)
+	}
+	if span.attrs[AttrEndpoint] != "/cgi-bin/message/custom/send" {
+		t.Fatalf("unexpected endpoint attribute: %v", span.attrs[AttrEndpoint])
+	}
+}
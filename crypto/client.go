@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	
+	"github.com/jcbowen/wego/logger"
 	"github.com/jcbowen/wego/storage"
 )
 
@@ -73,6 +74,8 @@ type WXBizMsgCrypt struct {
 	PrevEncodingAESKey string // 上一次的EncodingAESKey（官方要求支持）
 	AppID           string
 	storage         storage.TokenStorage // 使用现有的存储系统
+	prevKeyLoaded   bool                 // 是否已从存储加载上一次的EncodingAESKey
+	logger          logger.LoggerInterface
 }
 
 // NewWXBizMsgCrypt 创建新的微信消息加解密实例
@@ -85,27 +88,33 @@ func NewWXBizMsgCrypt(token, encodingAESKey, appID string) *WXBizMsgCrypt {
 }
 
 // NewWXBizMsgCryptWithStorage 创建新的微信消息加解密实例（使用存储系统）
+// 上一次的EncodingAESKey在当前密钥无法解密时从存储中加载
 func NewWXBizMsgCryptWithStorage(token, encodingAESKey, appID string, storage storage.TokenStorage) *WXBizMsgCrypt {
-	crypto := &WXBizMsgCrypt{
-		Token:          token,
-		EncodingAESKey: encodingAESKey,
-		AppID:          appID,
-		storage:        storage,
-	}
-	
-	// 从存储中加载上一次的EncodingAESKey
-	crypto.loadPrevEncodingAESKey()
-	
+	crypto := NewWXBizMsgCrypt(token, encodingAESKey, appID)
+	crypto.storage = storage
 	return crypto
 }
 
+// SetLogger 设置日志器，记录密钥加载、保存失败等不影响解密结果的错误，为nil时使用默认日志器
+func (c *WXBizMsgCrypt) SetLogger(log logger.LoggerInterface) {
+	c.logger = log
+}
+
+// warn 记录不影响解密结果的错误
+func (c *WXBizMsgCrypt) warn(msg string) {
+	log := c.logger
+	if log == nil {
+		log = logger.NewDefaultLoggerInterface()
+	}
+	log.Warn(msg)
+}
+
 // SetPrevEncodingAESKey 设置上一次的EncodingAESKey（符合微信官方规范）
-func (c *WXBizMsgCrypt) SetPrevEncodingAESKey(prevKey string) error {
+func (c *WXBizMsgCrypt) SetPrevEncodingAESKey(ctx context.Context, prevKey string) error {
 	c.PrevEncodingAESKey = prevKey
 	
 	// 如果配置了存储系统，则保存到存储中
 	if c.storage != nil {
-		ctx := context.Background()
 		err := c.storage.SavePrevEncodingAESKey(ctx, c.AppID, prevKey)
 		if err != nil {
 			return fmt.Errorf("保存上一次EncodingAESKey到存储失败: %v", err)
@@ -115,15 +124,19 @@ func (c *WXBizMsgCrypt) SetPrevEncodingAESKey(prevKey string) error {
 	return nil
 }
 
-// loadPrevEncodingAESKey 从存储中加载上一次的EncodingAESKey
-func (c *WXBizMsgCrypt) loadPrevEncodingAESKey() {
-	if c.storage == nil {
+// loadPrevEncodingAESKey 从存储中加载上一次的EncodingAESKey，已设置或已加载过时不再读取存储
+func (c *WXBizMsgCrypt) loadPrevEncodingAESKey(ctx context.Context) {
+	if c.storage == nil || c.prevKeyLoaded || c.PrevEncodingAESKey != "" {
 		return
 	}
 	
-	ctx := context.Background()
 	prevKey, err := c.storage.GetPrevEncodingAESKey(ctx, c.AppID)
-	if err == nil && prevKey != nil {
+	if err != nil {
+		c.warn(fmt.Sprintf("加载上一次EncodingAESKey失败: %v", err))
+		return
+	}
+	c.prevKeyLoaded = true
+	if prevKey != nil {
 		c.PrevEncodingAESKey = prevKey.PrevEncodingAESKey
 	}
 }
@@ -147,6 +160,11 @@ func (c *WXBizMsgCrypt) EncryptMsg(replyMsg, timestamp, nonce string) (string, s
 
 // DecryptMsg 解密消息（符合微信官方规范，支持使用上一次的EncodingAESKey）<mcreference link="https://developers.weixin.qq.com/doc/oplatform/Third-party_Platforms/2.0/api/Before_Develop/Message_encryption_and_decryption.html" index="0">0</mcreference>
 func (c *WXBizMsgCrypt) DecryptMsg(msgSignature, timestamp, nonce, encryptedMsg string) (string, error) {
+	return c.DecryptMsgContext(context.Background(), msgSignature, timestamp, nonce, encryptedMsg)
+}
+
+// DecryptMsgContext 解密消息，ctx用于从存储加载和保存上一次的EncodingAESKey
+func (c *WXBizMsgCrypt) DecryptMsgContext(ctx context.Context, msgSignature, timestamp, nonce, encryptedMsg string) (string, error) {
 	// 首先验证消息签名（符合微信官方规范）<mcreference link="https://developers.weixin.qq.com/doc/oplatform/Third-party_Platforms/2.0/api/Before_Develop/Technical_Plan.html" index="1">1</mcreference>
 	if !c.VerifySignature(msgSignature, timestamp, nonce, encryptedMsg) {
 		return "", fmt.Errorf("消息签名验证失败")
@@ -164,6 +182,7 @@ func (c *WXBizMsgCrypt) DecryptMsg(msgSignature, timestamp, nonce, encryptedMsg
 	}
 
 	// 如果当前密钥解密失败，尝试使用上一次的EncodingAESKey（官方要求）<mcreference link="https://developers.weixin.qq.com/doc/oplatform/Third-party_Platforms/2.0/api/Before_Develop/Message_encryption_and_decryption.html" index="0">0</mcreference>
+	c.loadPrevEncodingAESKey(ctx)
 	if c.PrevEncodingAESKey != "" {
 		prevAesKey, err := DecodeAESKey(c.PrevEncodingAESKey)
 		if err != nil {
@@ -176,11 +195,10 @@ func (c *WXBizMsgCrypt) DecryptMsg(msgSignature, timestamp, nonce, encryptedMsg
 			// 需要将当前密钥保存为上一次密钥，并更新当前密钥为新的密钥
 			if c.storage != nil {
 				// 保存当前密钥为上一次密钥
-				ctx := context.Background()
 				err := c.storage.SavePrevEncodingAESKey(ctx, c.AppID, c.EncodingAESKey)
 				if err != nil {
 					// 保存失败不影响解密结果，但记录错误
-					fmt.Printf("警告：保存上一次EncodingAESKey失败: %v\n", err)
+					c.warn(fmt.Sprintf("保存上一次EncodingAESKey失败: %v", err))
 				}
 			}
 
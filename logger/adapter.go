@@ -27,7 +27,12 @@ func NewDebuggerLoggerAdapter(debuggerLogger debugger.LoggerInterface) *Debugger
 	}
 }
 
-// Debug 方法已移除，jcbaseGo接口不包含该方法
+// Debug 记录调试级别日志，底层日志器实现了 DebugLogger 时才会输出
+// @param msg 日志消息
+// @param fields 附加字段
+func (a *DebuggerLoggerAdapter) Debug(msg any, fields ...map[string]interface{}) {
+    Debug(a.debuggerLogger, msg, fields...)
+}
 
 // Info 记录信息级别日志
 // @param msg 日志消息
@@ -108,6 +113,10 @@ type wegoLoggerToDebuggerAdapter struct {
     wegoLogger LoggerInterface
 }
 
+func (a *wegoLoggerToDebuggerAdapter) Debug(msg any, fields ...map[string]interface{}) {
+    Debug(a.wegoLogger, msg, fields...)
+}
+
 func (a *wegoLoggerToDebuggerAdapter) Info(msg any, fields ...map[string]interface{}) {
     a.wegoLogger.Info(msg, fields...)
 }
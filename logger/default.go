@@ -16,6 +16,7 @@ import (
 // 支持不同日志级别和字段附加功能
 type DefaultLogger struct {
     level  debugger.LogLevel
+    debug  bool // 是否输出调试日志
     fields map[string]interface{}
     logs   []LoggerLog
 }
@@ -30,7 +31,22 @@ func NewDefaultLogger() *DefaultLogger {
     }
 }
 
-// Debug 方法已移除，jcbaseGo接口不包含该方法
+// Debug 记录调试级别日志，仅在 SetDebug(true) 后输出
+// jcbaseGo接口不包含该方法，通过 DebugLogger 可选接口调用
+// @param msg 日志消息，可以是任意类型
+// @param fields 附加字段，可选参数
+func (l *DefaultLogger) Debug(msg any, fields ...map[string]interface{}) {
+    if !l.debug {
+        return
+    }
+    l.log(debugger.LevelInfo, "debug", msg, fields)
+}
+
+// SetDebug 设置是否输出调试日志
+// @param enabled 是否开启
+func (l *DefaultLogger) SetDebug(enabled bool) {
+    l.debug = enabled
+}
 
 // Info 记录信息级别日志
 // @param msg 日志消息，可以是任意类型
@@ -39,7 +55,7 @@ func (l *DefaultLogger) Info(msg any, fields ...map[string]interface{}) {
     if !l.shouldLog(debugger.LevelInfo) {
         return
     }
-    l.log(debugger.LevelInfo, msg, fields)
+    l.log(debugger.LevelInfo, debugger.LevelInfo.String(), msg, fields)
 }
 
 // Warn 记录警告级别日志
@@ -49,7 +65,7 @@ func (l *DefaultLogger) Warn(msg any, fields ...map[string]interface{}) {
     if !l.shouldLog(debugger.LevelWarn) {
         return
     }
-    l.log(debugger.LevelWarn, msg, fields)
+    l.log(debugger.LevelWarn, debugger.LevelWarn.String(), msg, fields)
 }
 
 // Error 记录错误级别日志
@@ -59,7 +75,7 @@ func (l *DefaultLogger) Error(msg any, fields ...map[string]interface{}) {
     if !l.shouldLog(debugger.LevelError) {
         return
     }
-    l.log(debugger.LevelError, msg, fields)
+    l.log(debugger.LevelError, debugger.LevelError.String(), msg, fields)
 }
 
 // WithFields 创建带有字段的日志记录器
@@ -77,6 +93,7 @@ func (l *DefaultLogger) WithFields(fields map[string]interface{}) LoggerInterfac
 
     return &DefaultLogger{
         level:  l.level,
+        debug:  l.debug,
         fields: newFields,
         logs:   l.logs,
     }
@@ -112,9 +129,10 @@ func (l *DefaultLogger) shouldLog(level debugger.LogLevel) bool {
 
 // log 实际执行日志记录
 // @param level 日志级别
+// @param label 输出的级别标签
 // @param msg 日志消息
 // @param fields 附加字段
-func (l *DefaultLogger) log(level debugger.LogLevel, msg any, fields []map[string]interface{}) {
+func (l *DefaultLogger) log(level debugger.LogLevel, label string, msg any, fields []map[string]interface{}) {
     // 处理消息
     message := l.formatMessage(msg)
 
@@ -144,7 +162,7 @@ func (l *DefaultLogger) log(level debugger.LogLevel, msg any, fields []map[strin
     })
 
     // 可点击格式输出：[LEVEL] file:line - message
-    logMsg := fmt.Sprintf("[%s] %s:%d - %s", level.String(), fileName, line, message)
+    logMsg := fmt.Sprintf("[%s] %s:%d - %s", label, fileName, line, message)
     if len(allFields) > 0 {
         logMsg += " " + l.formatFields(allFields)
     }
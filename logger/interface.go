@@ -13,3 +13,20 @@ import (
 //  logger.WithFields(map[string]interface{}{"user_id": 123}).Warn("用户操作告警")
 //  if logger.GetLevel() == debugger.LevelError { /* ... */ }
 type LoggerInterface = debugger.LoggerInterface
+
+// DebugLogger 支持调试级别日志的记录器（可选接口）
+// debugger.LoggerInterface 不包含Debug方法，日志器实现该接口后才会输出请求体、响应体等调试信息
+// 调试日志可能包含用户数据，即使经过脱敏，也建议仅在排查问题时开启
+type DebugLogger interface {
+    Debug(msg any, fields ...map[string]interface{})
+}
+
+// Debug 以调试级别记录日志，日志器未实现 DebugLogger 时忽略
+// @param logger 日志记录器
+// @param msg 日志消息
+// @param fields 附加字段
+func Debug(logger LoggerInterface, msg any, fields ...map[string]interface{}) {
+    if l, ok := logger.(DebugLogger); ok {
+        l.Debug(msg, fields...)
+    }
+}
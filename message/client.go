@@ -1,6 +1,7 @@
 package message
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"strconv"
@@ -27,6 +28,7 @@ func NewMessageClient(client *openplatform.Client) *MessageClient {
 type SecureMessageProcessor struct {
 	processor   *MessageProcessor
 	cryptoCache map[string]*crypto.WXBizMsgCrypt // 按授权方AppID缓存加解密实例
+	metrics     core.Metrics                     // 指标收集器
 }
 
 // NewSecureMessageProcessor 创建安全消息处理器
@@ -34,9 +36,24 @@ func NewSecureMessageProcessor() *SecureMessageProcessor {
 	return &SecureMessageProcessor{
 		processor:   NewMessageProcessor(),
 		cryptoCache: make(map[string]*crypto.WXBizMsgCrypt),
+		metrics:     core.NopMetrics{},
 	}
 }
 
+// SetMetrics 设置指标收集器，记录回调处理耗时和解密失败次数，为nil时不收集
+func (p *SecureMessageProcessor) SetMetrics(metrics core.Metrics) {
+	if metrics == nil {
+		metrics = core.NopMetrics{}
+	}
+	p.metrics = metrics
+	p.processor.SetMetrics(metrics)
+}
+
+// SetTracer 设置链路追踪，为消息分发创建span，为nil时不追踪
+func (p *SecureMessageProcessor) SetTracer(tracer core.Tracer) {
+	p.processor.SetTracer(tracer)
+}
+
 // ProcessSecureMessage 处理安全消息（包含加解密，符合微信官方规范）
 func (p *SecureMessageProcessor) ProcessSecureMessage(
 	authorizerAppID string,
@@ -44,6 +61,18 @@ func (p *SecureMessageProcessor) ProcessSecureMessage(
 	timestamp string,
 	nonce string,
 	encryptedMsg string,
+) (interface{}, error) {
+	return p.ProcessSecureMessageContext(context.Background(), authorizerAppID, msgSignature, timestamp, nonce, encryptedMsg)
+}
+
+// ProcessSecureMessageContext 处理安全消息，ctx中的span会作为消息分发span的父span
+func (p *SecureMessageProcessor) ProcessSecureMessageContext(
+	ctx context.Context,
+	authorizerAppID string,
+	msgSignature string,
+	timestamp string,
+	nonce string,
+	encryptedMsg string,
 ) (interface{}, error) {
 	// 验证时间戳（防止重放攻击，微信官方建议5分钟时间窗口）
 	if err := p.validateTimestamp(timestamp); err != nil {
@@ -59,17 +88,19 @@ func (p *SecureMessageProcessor) ProcessSecureMessage(
 	// 验证消息签名（必须使用msg_signature参数）
 	valid := cryptoInstance.VerifySignature(msgSignature, timestamp, nonce, encryptedMsg)
 	if !valid {
+		p.metrics.IncCounter(core.MetricCallbackDecryptFailures, core.Labels{"source": "message"})
 		return nil, fmt.Errorf("消息签名验证不通过，请检查msg_signature参数")
 	}
 
 	// 解密消息
-	decryptedMsg, err := cryptoInstance.DecryptMsg(msgSignature, timestamp, nonce, encryptedMsg)
+	decryptedMsg, err := cryptoInstance.DecryptMsgContext(ctx, msgSignature, timestamp, nonce, encryptedMsg)
 	if err != nil {
+		p.metrics.IncCounter(core.MetricCallbackDecryptFailures, core.Labels{"source": "message"})
 		return nil, fmt.Errorf("消息解密失败: %v", err)
 	}
 
 	// 处理消息
-	reply, err := p.processor.ProcessMessage([]byte(decryptedMsg))
+	reply, err := p.processor.ProcessMessageContext(ctx, []byte(decryptedMsg))
 	if err != nil {
 		return nil, err
 	}
@@ -410,23 +441,27 @@ type UpdateAuthorizedEvent struct {
 }
 
 // MessageHandler 消息处理器接口
+// ctx 为 ProcessMessageContext 传入的上下文，携带消息分发span，处理器调用微信接口或存储时应继续传递
 type MessageHandler interface {
-	HandleMessage(msg *Message) (interface{}, error)
+	HandleMessage(ctx context.Context, msg *Message) (interface{}, error)
 }
 
 // EventHandler 事件处理器接口
+// ctx 为 ProcessMessageContext 传入的上下文，携带消息分发span，处理器调用微信接口或存储时应继续传递
 type EventHandler interface {
-	HandleEvent(event *EventMessage) (interface{}, error)
+	HandleEvent(ctx context.Context, event *EventMessage) (interface{}, error)
 }
 
 // ComponentVerifyTicketHandler 第三方平台component_verify_ticket事件处理器接口
+// ctx 为 ProcessMessageContext 传入的上下文，携带消息分发span，处理器保存票据时应继续传递
 type ComponentVerifyTicketHandler interface {
-	HandleComponentVerifyTicket(event *ComponentVerifyTicketEvent) error
+	HandleComponentVerifyTicket(ctx context.Context, event *ComponentVerifyTicketEvent) error
 }
 
 // AuthorizeEventHandler 第三方平台授权事件处理器接口
+// ctx 为 ProcessMessageContext 传入的上下文，携带消息分发span，处理器调用微信接口或存储时应继续传递
 type AuthorizeEventHandler interface {
-	HandleAuthorizeEvent(event interface{}) error
+	HandleAuthorizeEvent(ctx context.Context, event interface{}) error
 }
 
 // MessageProcessor 消息处理器
@@ -435,6 +470,8 @@ type MessageProcessor struct {
 	eventHandlers                 map[string]EventHandler
 	componentVerifyTicketHandlers []ComponentVerifyTicketHandler
 	authorizeEventHandlers        []AuthorizeEventHandler
+	metrics                       core.Metrics // 指标收集器
+	tracer                        core.Tracer  // 链路追踪
 }
 
 // NewMessageProcessor 创建消息处理器
@@ -444,7 +481,25 @@ func NewMessageProcessor() *MessageProcessor {
 		eventHandlers:                 make(map[string]EventHandler),
 		componentVerifyTicketHandlers: make([]ComponentVerifyTicketHandler, 0),
 		authorizeEventHandlers:        make([]AuthorizeEventHandler, 0),
+		metrics:                       core.NopMetrics{},
+		tracer:                        core.NopTracer{},
+	}
+}
+
+// SetMetrics 设置指标收集器，记录消息处理耗时，为nil时不收集
+func (p *MessageProcessor) SetMetrics(metrics core.Metrics) {
+	if metrics == nil {
+		metrics = core.NopMetrics{}
 	}
+	p.metrics = metrics
+}
+
+// SetTracer 设置链路追踪，为消息分发创建span，为nil时不追踪
+func (p *MessageProcessor) SetTracer(tracer core.Tracer) {
+	if tracer == nil {
+		tracer = core.NopTracer{}
+	}
+	p.tracer = tracer
 }
 
 // RegisterMessageHandler 注册消息处理器
@@ -469,21 +524,44 @@ func (p *MessageProcessor) RegisterAuthorizeEventHandler(handler AuthorizeEventH
 
 // ProcessMessage 处理消息
 func (p *MessageProcessor) ProcessMessage(xmlData []byte) (interface{}, error) {
+	return p.ProcessMessageContext(context.Background(), xmlData)
+}
+
+// ProcessMessageContext 处理消息，ctx中的span会作为消息分发span的父span
+func (p *MessageProcessor) ProcessMessageContext(ctx context.Context, xmlData []byte) (reply interface{}, err error) {
+	// 记录处理耗时，事件消息的type标签为 event.事件类型
+	start := time.Now()
+	msgType := "unknown"
+	ctx, span := p.tracer.Start(ctx, core.SpanMessageDispatch)
+	defer func() {
+		p.metrics.ObserveHistogram(core.MetricCallbackDuration, time.Since(start).Seconds(), core.Labels{
+			"source": "message",
+			"type":   msgType,
+			"result": core.ResultLabel(err),
+		})
+		core.EndSpan(span, err)
+	}()
+
 	// 解析基础消息类型
 	var baseMsg Message
 	if err := xml.Unmarshal(xmlData, &baseMsg); err != nil {
 		return nil, fmt.Errorf("解析XML消息失败: %v", err)
 	}
+	msgType = baseMsg.MsgType
+	span.SetAttributes(core.Attr(core.AttrMsgType, baseMsg.MsgType))
 
 	// 检查是否为第三方平台特殊事件
 	// 第三方平台事件通常有特定的Event类型
 	if baseMsg.MsgType == core.MessageTypeEvent {
 		var eventMsg EventMessage
 		if err := xml.Unmarshal(xmlData, &eventMsg); err == nil {
+			msgType = baseMsg.MsgType + "." + eventMsg.Event
+			span.SetAttributes(core.Attr(core.AttrEvent, eventMsg.Event))
+
 			// 检查是否为第三方平台特定事件
 			switch eventMsg.Event {
 			case core.EventTypeComponentVerifyTicket, core.EventTypeAuthorized, core.EventTypeUpdateAuthorized, core.EventTypeUnauthorized:
-				return p.handleThirdPartyMessage(xmlData, &baseMsg)
+				return p.handleThirdPartyMessage(ctx, xmlData, &baseMsg)
 			}
 		}
 	}
@@ -491,26 +569,26 @@ func (p *MessageProcessor) ProcessMessage(xmlData []byte) (interface{}, error) {
 	// 根据消息类型进行具体解析
 	switch baseMsg.MsgType {
 	case core.MessageTypeEvent:
-		return p.processEventMessage(xmlData)
+		return p.processEventMessage(ctx, xmlData)
 	case core.MessageTypeText:
-		return p.processTextMessage(xmlData)
+		return p.processTextMessage(ctx, xmlData)
 	case core.MessageTypeImage:
-		return p.processImageMessage(xmlData)
+		return p.processImageMessage(ctx, xmlData)
 	case core.MessageTypeVoice:
-		return p.processVoiceMessage(xmlData)
+		return p.processVoiceMessage(ctx, xmlData)
 	case core.MessageTypeVideo:
-		return p.processVideoMessage(xmlData)
+		return p.processVideoMessage(ctx, xmlData)
 	case core.MessageTypeLocation:
-		return p.processLocationMessage(xmlData)
+		return p.processLocationMessage(ctx, xmlData)
 	case core.MessageTypeLink:
-		return p.processLinkMessage(xmlData)
+		return p.processLinkMessage(ctx, xmlData)
 	default:
 		return nil, fmt.Errorf("不支持的消息类型: %s", baseMsg.MsgType)
 	}
 }
 
 // handleThirdPartyMessage 处理第三方平台消息
-func (p *MessageProcessor) handleThirdPartyMessage(xmlData []byte, msg *Message) (interface{}, error) {
+func (p *MessageProcessor) handleThirdPartyMessage(ctx context.Context, xmlData []byte, msg *Message) (interface{}, error) {
 	// 解析第三方平台事件
 	var event EventMessage
 	err := xml.Unmarshal(xmlData, &event)
@@ -521,17 +599,17 @@ func (p *MessageProcessor) handleThirdPartyMessage(xmlData []byte, msg *Message)
 	// 根据事件类型进行处理
 	switch event.Event {
 	case core.EventTypeComponentVerifyTicket:
-		return p.handleComponentVerifyTicketEvent(xmlData)
+		return p.handleComponentVerifyTicketEvent(ctx, xmlData)
 	case core.EventTypeAuthorized, core.EventTypeUpdateAuthorized, core.EventTypeUnauthorized:
-		return p.handleAuthorizeEvent(xmlData)
+		return p.handleAuthorizeEvent(ctx, xmlData)
 	default:
 		// 如果不是第三方平台特定事件，则按普通事件处理
-		return p.processEventMessage(xmlData)
+		return p.processEventMessage(ctx, xmlData)
 	}
 }
 
 // handleComponentVerifyTicketEvent 处理component_verify_ticket事件
-func (p *MessageProcessor) handleComponentVerifyTicketEvent(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) handleComponentVerifyTicketEvent(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var event ComponentVerifyTicketEvent
 	err := xml.Unmarshal(xmlData, &event)
 	if err != nil {
@@ -540,7 +618,7 @@ func (p *MessageProcessor) handleComponentVerifyTicketEvent(xmlData []byte) (int
 
 	// 调用所有注册的处理器
 	for _, handler := range p.componentVerifyTicketHandlers {
-		err := handler.HandleComponentVerifyTicket(&event)
+		err := handler.HandleComponentVerifyTicket(ctx, &event)
 		if err != nil {
 			return nil, fmt.Errorf("处理component_verify_ticket事件失败: %v", err)
 		}
@@ -551,7 +629,7 @@ func (p *MessageProcessor) handleComponentVerifyTicketEvent(xmlData []byte) (int
 }
 
 // handleAuthorizeEvent 处理授权事件
-func (p *MessageProcessor) handleAuthorizeEvent(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) handleAuthorizeEvent(ctx context.Context, xmlData []byte) (interface{}, error) {
 	// 解析XML获取事件类型
 	var baseEvent EventMessage
 	err := xml.Unmarshal(xmlData, &baseEvent)
@@ -569,7 +647,7 @@ func (p *MessageProcessor) handleAuthorizeEvent(xmlData []byte) (interface{}, er
 		}
 		// 调用所有注册的处理器
 		for _, handler := range p.authorizeEventHandlers {
-			err := handler.HandleAuthorizeEvent(&event)
+			err := handler.HandleAuthorizeEvent(ctx, &event)
 			if err != nil {
 				return nil, fmt.Errorf("处理授权事件失败: %v", err)
 			}
@@ -582,7 +660,7 @@ func (p *MessageProcessor) handleAuthorizeEvent(xmlData []byte) (interface{}, er
 		}
 		// 调用所有注册的处理器
 		for _, handler := range p.authorizeEventHandlers {
-			err := handler.HandleAuthorizeEvent(&event)
+			err := handler.HandleAuthorizeEvent(ctx, &event)
 			if err != nil {
 				return nil, fmt.Errorf("处理授权事件失败: %v", err)
 			}
@@ -595,7 +673,7 @@ func (p *MessageProcessor) handleAuthorizeEvent(xmlData []byte) (interface{}, er
 		}
 		// 调用所有注册的处理器
 		for _, handler := range p.authorizeEventHandlers {
-			err := handler.HandleAuthorizeEvent(&event)
+			err := handler.HandleAuthorizeEvent(ctx, &event)
 			if err != nil {
 				return nil, fmt.Errorf("处理授权事件失败: %v", err)
 			}
@@ -609,7 +687,7 @@ func (p *MessageProcessor) handleAuthorizeEvent(xmlData []byte) (interface{}, er
 }
 
 // processEventMessage 处理事件消息
-func (p *MessageProcessor) processEventMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processEventMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var event EventMessage
 	if err := xml.Unmarshal(xmlData, &event); err != nil {
 		return nil, fmt.Errorf("解析事件消息失败: %v", err)
@@ -620,11 +698,11 @@ func (p *MessageProcessor) processEventMessage(xmlData []byte) (interface{}, err
 		return nil, fmt.Errorf("未注册的事件处理器: %s", event.Event)
 	}
 
-	return handler.HandleEvent(&event)
+	return handler.HandleEvent(ctx, &event)
 }
 
 // processTextMessage 处理文本消息
-func (p *MessageProcessor) processTextMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processTextMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var textMsg TextMessage
 	if err := xml.Unmarshal(xmlData, &textMsg); err != nil {
 		return nil, fmt.Errorf("解析文本消息失败: %v", err)
@@ -635,11 +713,11 @@ func (p *MessageProcessor) processTextMessage(xmlData []byte) (interface{}, erro
 		return nil, fmt.Errorf("未注册的文本消息处理器")
 	}
 
-	return handler.HandleMessage(&textMsg.Message)
+	return handler.HandleMessage(ctx, &textMsg.Message)
 }
 
 // processImageMessage 处理图片消息
-func (p *MessageProcessor) processImageMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processImageMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var imageMsg ImageMessage
 	if err := xml.Unmarshal(xmlData, &imageMsg); err != nil {
 		return nil, fmt.Errorf("解析图片消息失败: %v", err)
@@ -650,11 +728,11 @@ func (p *MessageProcessor) processImageMessage(xmlData []byte) (interface{}, err
 		return nil, fmt.Errorf("未注册的图片消息处理器")
 	}
 
-	return handler.HandleMessage(&imageMsg.Message)
+	return handler.HandleMessage(ctx, &imageMsg.Message)
 }
 
 // processVoiceMessage 处理语音消息
-func (p *MessageProcessor) processVoiceMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processVoiceMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var voiceMsg VoiceMessage
 	if err := xml.Unmarshal(xmlData, &voiceMsg); err != nil {
 		return nil, fmt.Errorf("解析语音消息失败: %v", err)
@@ -665,11 +743,11 @@ func (p *MessageProcessor) processVoiceMessage(xmlData []byte) (interface{}, err
 		return nil, fmt.Errorf("未注册的语音消息处理器")
 	}
 
-	return handler.HandleMessage(&voiceMsg.Message)
+	return handler.HandleMessage(ctx, &voiceMsg.Message)
 }
 
 // processVideoMessage 处理视频消息
-func (p *MessageProcessor) processVideoMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processVideoMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var videoMsg VideoMessage
 	if err := xml.Unmarshal(xmlData, &videoMsg); err != nil {
 		return nil, fmt.Errorf("解析视频消息失败: %v", err)
@@ -680,11 +758,11 @@ func (p *MessageProcessor) processVideoMessage(xmlData []byte) (interface{}, err
 		return nil, fmt.Errorf("未注册的视频消息处理器")
 	}
 
-	return handler.HandleMessage(&videoMsg.Message)
+	return handler.HandleMessage(ctx, &videoMsg.Message)
 }
 
 // processLocationMessage 处理位置消息
-func (p *MessageProcessor) processLocationMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processLocationMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var locationMsg LocationMessage
 	if err := xml.Unmarshal(xmlData, &locationMsg); err != nil {
 		return nil, fmt.Errorf("解析位置消息失败: %v", err)
@@ -695,11 +773,11 @@ func (p *MessageProcessor) processLocationMessage(xmlData []byte) (interface{},
 		return nil, fmt.Errorf("未注册的位置消息处理器")
 	}
 
-	return handler.HandleMessage(&locationMsg.Message)
+	return handler.HandleMessage(ctx, &locationMsg.Message)
 }
 
 // processLinkMessage 处理链接消息
-func (p *MessageProcessor) processLinkMessage(xmlData []byte) (interface{}, error) {
+func (p *MessageProcessor) processLinkMessage(ctx context.Context, xmlData []byte) (interface{}, error) {
 	var linkMsg LinkMessage
 	if err := xml.Unmarshal(xmlData, &linkMsg); err != nil {
 		return nil, fmt.Errorf("解析链接消息失败: %v", err)
@@ -710,7 +788,7 @@ func (p *MessageProcessor) processLinkMessage(xmlData []byte) (interface{}, erro
 		return nil, fmt.Errorf("未注册的链接消息处理器")
 	}
 
-	return handler.HandleMessage(&linkMsg.Message)
+	return handler.HandleMessage(ctx, &linkMsg.Message)
 }
 
 // VoiceMessage 语音消息
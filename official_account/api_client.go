@@ -2,12 +2,17 @@ package official_account
 
 import (
 	"context"
-	"fmt"
-	"net/url"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointCallbackCheck  = core.Endpoint[CallbackCheckRequest, CallbackCheckResponse]{Method: "POST", URL: URLCallbackCheck, Token: core.ParamAccessToken}
+	endpointGetApiDomainIp = core.Endpoint[core.NoParams, GetApiDomainIpResponse]{Method: "GET", URL: URLGetApiDomainIp, Token: core.ParamAccessToken}
+	endpointGetCallbackIp  = core.Endpoint[core.NoParams, GetCallbackIpResponse]{Method: "GET", URL: URLGetCallbackIp, Token: core.ParamAccessToken}
+)
+
 // APIClient 微信公众号API客户端
 type APIClient struct {
 	Client *Client
@@ -47,83 +52,51 @@ type GetCallbackIpResponse struct {
 
 // CallbackCheck 网络通信检测
 func (c *APIClient) CallbackCheck(ctx context.Context, action, checkOperator string) (*CallbackCheckResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := CallbackCheckRequest{
 		Action:        action,
 		CheckOperator: checkOperator,
 	}
 
-	var result CallbackCheckResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLCallbackCheck, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointCallbackCheck, request)
 }
 
 // GetApiDomainIp 获取微信API服务器IP
 func (c *APIClient) GetApiDomainIp(ctx context.Context) (*GetApiDomainIpResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetApiDomainIpResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetApiDomainIp, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
+	return core.Call(ctx, c.Client, endpointGetApiDomainIp, core.NoParams{})
+}
 
-	return &result, nil
+// APIDomainStatus API域名诊断结果
+type APIDomainStatus struct {
+	Active   string   `json:"active"`    // 当前使用的域名
+	BaseURLs []string `json:"base_urls"` // 主域名和备用域名
+	IPList   []string `json:"ip_list"`   // 微信API服务器IP
 }
 
-// GetCallbackIp 获取微信推送服务器IP
-func (c *APIClient) GetCallbackIp(ctx context.Context) (*GetCallbackIpResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
+// CheckAPIDomain 诊断API域名
+// 通过 GetApiDomainIp 经当前域名请求微信，请求失败时会按域名解析器的配置故障转移，
+// 返回请求后解析器使用的域名和微信API服务器IP
+// 未设置 *core.DomainResolver 时 Active 为 core.BaseAPIURL
+func (c *APIClient) CheckAPIDomain(ctx context.Context) (*APIDomainStatus, error) {
+	result, err := c.GetApiDomainIp(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var result GetCallbackIpResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetCallbackIp, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
+	status := &APIDomainStatus{
+		Active:   core.BaseAPIURL,
+		BaseURLs: []string{core.BaseAPIURL},
+		IPList:   result.IPList,
 	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+	if resolver, ok := c.Client.GetEndpointResolver().(*core.DomainResolver); ok {
+		status.Active = resolver.Active()
+		status.BaseURLs = resolver.BaseURLs()
 	}
+	return status, nil
+}
 
-	return &result, nil
+// GetCallbackIp 获取微信推送服务器IP
+func (c *APIClient) GetCallbackIp(ctx context.Context) (*GetCallbackIpResponse, error) {
+	return core.Call(ctx, c.Client, endpointGetCallbackIp, core.NoParams{})
 }
 
 // GetSubscribeClient 获取订阅消息客户端
@@ -67,10 +67,6 @@ func (q *Qrcode) Create(ctx context.Context, qrCode *QRCodeRequest, accessToken
 	if err != nil {
 		return nil, err
 	}
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	return &result, nil
 }
 
@@ -79,9 +79,5 @@ func (c *Template) SendTemplateMessage(ctx context.Context, template *TemplateMe
 	if err != nil {
 		return nil, err
 	}
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	return &result, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/jcbowen/jcbaseGo/component/debugger"
@@ -13,6 +14,9 @@ import (
 	"github.com/jcbowen/wego/storage"
 )
 
+// endpointClearQuota 清空API调用次数接口
+var endpointClearQuota = core.Endpoint[ClearQuotaRequest, ClearQuotaResponse]{Method: "POST", URL: URLClearQuota, Token: core.ParamAccessToken}
+
 // Client 微信公众号客户端
 type Client struct {
 	config     *Config
@@ -21,7 +25,19 @@ type Client struct {
 	logger     logger.LoggerInterface
 	req        *core.Request
 
-	stableTokenClient *StableTokenClient // 稳定版access_token客户端
+	middlewares       []core.Middleware     // 请求中间件
+	redactor          *core.Redactor        // 日志脱敏器
+	rateLimiter       *core.RateLimiter     // 限流器
+	retryPolicy       *core.RetryPolicy     // 重试策略
+	expiryPolicy      *core.ExpiryPolicy    // 凭证提前过期策略
+	breaker           *core.CircuitBreaker  // 熔断器
+	resolver          core.EndpointResolver // 接口域名解析器
+	metrics           core.Metrics          // 指标收集器
+	tracer            core.Tracer           // 链路追踪
+	tokenProvider     core.TokenProvider    // access_token来源，为nil时按配置自行刷新或使用稳定版access_token
+	stableTokenClient *StableTokenClient    // 稳定版access_token客户端
+	refreshGroup      core.RefreshGroup     // 合并并发的token刷新
+	legacyStorage     storage.TokenStorage  // 升级前未使用命名空间的存储，只读，存储不支持命名空间时为nil
 }
 
 // NewClient 创建新的微信公众号客户端（使用默认文件存储）
@@ -29,6 +45,16 @@ type Client struct {
 // @param opts ...any 可选参数，支持以下类型：
 //   - debugger.LoggerInterface: 自定义日志器
 //   - core.HTTPClient: 自定义HTTP客户端
+//   - core.Middleware、[]core.Middleware: 请求中间件
+//   - *core.Redactor: 日志脱敏器，默认隐藏凭证和用户隐私信息
+//   - *core.RateLimiter: 限流器，按appid和接口限制调用频率和每日调用次数
+//   - *core.RetryPolicy: 重试策略，系统繁忙和GET请求的临时故障按指数退避重试
+//   - *core.ExpiryPolicy: 凭证提前过期策略，默认提前5分钟或有效期的5%
+//   - *core.CircuitBreaker: 熔断器，接口连续故障时快速失败
+//   - core.EndpointResolver: 接口域名解析器，如 *core.DomainResolver，用于故障转移到备用域名
+//   - core.Metrics: 指标收集器，记录接口耗时、错误码、token刷新和存储耗时
+//   - core.Tracer: 链路追踪，为接口调用、token刷新和存储操作创建span
+//   - core.TokenProvider: access_token来源，如 *core.StaticTokenProvider、*core.HTTPTokenProvider，token由外部系统维护时使用
 //
 // @return *Client 公众号客户端实例
 func NewClient(config *Config, opts ...any) *Client {
@@ -42,10 +68,21 @@ func NewClient(config *Config, opts ...any) *Client {
 
 // NewMPClientWithStorage 创建新的微信公众号客户端（使用自定义存储）
 // @param config *Config 公众号配置信息
-// @param storage storage.TokenStorage 自定义存储实例
+// @param storage storage.TokenStorage 自定义存储实例，实现 storage.NamespacedStorage 时公众号的token保存在 storage.OfficialAccountNamespace，
+// 命名空间中没有token时读取升级前保存在默认命名空间的token，直到其过期
 // @param opts ...any 可选参数，支持以下类型：
 //   - debugger.LoggerInterface: 自定义日志器
 //   - core.HTTPClient: 自定义HTTP客户端
+//   - core.Middleware、[]core.Middleware: 请求中间件
+//   - *core.Redactor: 日志脱敏器，默认隐藏凭证和用户隐私信息
+//   - *core.RateLimiter: 限流器，按appid和接口限制调用频率和每日调用次数
+//   - *core.RetryPolicy: 重试策略，系统繁忙和GET请求的临时故障按指数退避重试
+//   - *core.ExpiryPolicy: 凭证提前过期策略，默认提前5分钟或有效期的5%
+//   - *core.CircuitBreaker: 熔断器，接口连续故障时快速失败
+//   - core.EndpointResolver: 接口域名解析器，如 *core.DomainResolver，用于故障转移到备用域名
+//   - core.Metrics: 指标收集器，记录接口耗时、错误码、token刷新和存储耗时
+//   - core.Tracer: 链路追踪，为接口调用、token刷新和存储操作创建span
+//   - core.TokenProvider: access_token来源，如 *core.StaticTokenProvider、*core.HTTPTokenProvider，token由外部系统维护时使用
 //
 // @return *Client 公众号客户端实例
 func NewMPClientWithStorage(config *Config, storage storage.TokenStorage, opts ...any) *Client {
@@ -54,7 +91,10 @@ func NewMPClientWithStorage(config *Config, storage storage.TokenStorage, opts .
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		storage:    storage,
 		logger:     logger.NewDefaultLoggerInterface(),
+		metrics:    core.NopMetrics{},
+		tracer:     core.NopTracer{},
 	}
+	client.scopeStorage()
 
 	// 遍历所有可选参数，根据类型进行相应设置
 	if len(opts) > 0 {
@@ -66,6 +106,37 @@ func NewMPClientWithStorage(config *Config, storage storage.TokenStorage, opts .
 			case core.HTTPClient:
 				// 设置自定义HTTP客户端
 				client.SetHTTPClient(v)
+			case core.Middleware, func(core.RoundTrip) core.RoundTrip, []core.Middleware:
+				// 添加请求中间件
+				middlewares, _ := core.ParseMiddlewares(v)
+				client.Use(middlewares...)
+			case *core.Redactor:
+				// 设置日志脱敏器
+				client.SetRedactor(v)
+			case *core.RateLimiter:
+				// 设置限流器
+				client.SetRateLimiter(v)
+			case *core.RetryPolicy:
+				// 设置重试策略
+				client.SetRetryPolicy(v)
+			case *core.ExpiryPolicy:
+				// 设置凭证提前过期策略
+				client.SetExpiryPolicy(v)
+			case *core.CircuitBreaker:
+				// 设置熔断器
+				client.SetCircuitBreaker(v)
+			case core.EndpointResolver:
+				// 设置接口域名解析器
+				client.SetEndpointResolver(v)
+			case core.Metrics:
+				// 设置指标收集器
+				client.SetMetrics(v)
+			case core.Tracer:
+				// 设置链路追踪
+				client.SetTracer(v)
+			case core.TokenProvider:
+				// 设置access_token来源
+				client.SetTokenProvider(v)
 			default:
 				// 记录未知类型的可选参数
 				client.logger.Warn(fmt.Sprintf("未知的可选参数类型: %T", v))
@@ -73,12 +144,46 @@ func NewMPClientWithStorage(config *Config, storage storage.TokenStorage, opts .
 		}
 	}
 
-	client.req = core.NewRequest(client.httpClient, client.logger)
+	client.req = client.newRequest()
 	client.stableTokenClient = NewStableTokenClient(client)
 
 	return client
 }
 
+// scopeStorage 存储支持命名空间时使用 storage.OfficialAccountNamespace，与第三方平台的授权方令牌分开保存，
+// 原存储保留为 legacyStorage 以读取升级前的token
+func (c *Client) scopeStorage() {
+	scoped, err := storage.Scope(c.storage, storage.OfficialAccountNamespace)
+	if err != nil {
+		return
+	}
+	c.legacyStorage = c.storage
+	c.storage = scoped
+}
+
+// getStoredToken 从存储读取access_token
+// 命名空间中没有token时读取默认命名空间中升级前保存的token，没有refresh_token的才是公众号自身的token；
+// 新token只保存到命名空间，避免升级后所有实例重新调用 /cgi-bin/token 使正在使用的token失效
+func (c *Client) getStoredToken(ctx context.Context) (*storage.AuthorizerAccessToken, error) {
+	token, err := c.storage.GetAuthorizerToken(ctx, c.config.AppID)
+	if err != nil || token != nil {
+		return token, err
+	}
+	return c.getLegacyToken(ctx)
+}
+
+// getLegacyToken 读取默认命名空间中升级前保存的token，存储不支持命名空间时返回nil
+func (c *Client) getLegacyToken(ctx context.Context) (*storage.AuthorizerAccessToken, error) {
+	if c.legacyStorage == nil {
+		return nil, nil
+	}
+	legacy, err := c.legacyStorage.GetAuthorizerToken(ctx, c.config.AppID)
+	if err != nil || legacy == nil || legacy.AuthorizerRefreshToken != "" {
+		return nil, err
+	}
+	return legacy, nil
+}
+
 // GetStableTokenClient 获取稳定版access_token客户端
 func (c *Client) GetStableTokenClient() *StableTokenClient {
 	return c.stableTokenClient
@@ -95,7 +200,7 @@ func (c *Client) SetLogger(log debugger.LoggerInterface) {
 	}
 	// 同时更新请求对象中的日志器
 	if c.req != nil {
-		c.req = core.NewRequest(c.httpClient, c.logger)
+		c.req = c.newRequest()
 	}
 }
 
@@ -104,14 +209,197 @@ func (c *Client) SetHTTPClient(client core.HTTPClient) {
 	c.httpClient = client
 	// 同时更新请求对象中的HTTP客户端
 	if c.req != nil {
-		c.req = core.NewRequest(client, c.logger)
+		c.req = c.newRequest()
+	}
+}
+
+// Use 添加请求中间件，作用于该客户端发出的所有请求
+func (c *Client) Use(middlewares ...core.Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	if c.req != nil {
+		c.req.Use(middlewares...)
+	}
+}
+
+// SetRedactor 设置日志脱敏器，为nil时使用默认规则
+func (c *Client) SetRedactor(redactor *core.Redactor) {
+	c.redactor = redactor
+	if c.req != nil {
+		c.req.SetRedactor(redactor)
+	}
+}
+
+// SetRateLimiter 设置限流器，为nil时不限流
+func (c *Client) SetRateLimiter(limiter *core.RateLimiter) {
+	c.rateLimiter = limiter
+	if c.req != nil {
+		c.req.SetRateLimiter(limiter)
+	}
+}
+
+// GetRateLimiter 获取限流器，未设置时返回nil
+func (c *Client) GetRateLimiter() *core.RateLimiter {
+	return c.rateLimiter
+}
+
+// SetRetryPolicy 设置重试策略，为nil时不重试
+func (c *Client) SetRetryPolicy(policy *core.RetryPolicy) {
+	c.retryPolicy = policy
+	if c.req != nil {
+		c.req.SetRetryPolicy(policy)
+	}
+}
+
+// GetRetryPolicy 获取重试策略，未设置时返回nil
+func (c *Client) GetRetryPolicy() *core.RetryPolicy {
+	return c.retryPolicy
+}
+
+// SetExpiryPolicy 设置凭证提前过期策略，为nil时使用 core.DefaultExpiryPolicy
+func (c *Client) SetExpiryPolicy(policy *core.ExpiryPolicy) {
+	c.expiryPolicy = policy
+}
+
+// GetExpiryPolicy 获取凭证提前过期策略，未设置时返回nil
+func (c *Client) GetExpiryPolicy() *core.ExpiryPolicy {
+	return c.expiryPolicy
+}
+
+// expiresAt 按提前过期策略计算凭证的截止时间
+// @param start time.Time 发起获取凭证请求的时间
+// @param expiresIn int 微信返回的有效期，单位秒
+func (c *Client) expiresAt(start time.Time, expiresIn int) time.Time {
+	return c.expiryPolicy.ExpiresAt(start, expiresIn)
+}
+
+// SetTokenProvider 设置access_token来源，为nil时恢复默认
+// 默认由客户端调用微信接口获取和刷新，配置了 Config.UseStableToken 时使用稳定版access_token
+// token由外部系统维护时设置只读来源，客户端不再调用微信接口获取token，也不会被 core.Refresher 主动刷新
+func (c *Client) SetTokenProvider(provider core.TokenProvider) {
+	c.tokenProvider = provider
+}
+
+// GetTokenProvider 获取access_token来源，未设置时返回按配置使用的内置来源
+func (c *Client) GetTokenProvider() core.TokenProvider {
+	if c.tokenProvider != nil {
+		return c.tokenProvider
+	}
+	if c.config.UseStableToken {
+		return NewStableTokenProvider(c)
+	}
+	return NewSelfTokenProvider(c)
+}
+
+// SetCircuitBreaker 设置熔断器，为nil时不熔断
+func (c *Client) SetCircuitBreaker(breaker *core.CircuitBreaker) {
+	c.breaker = breaker
+	if c.req != nil {
+		c.req.SetCircuitBreaker(breaker)
+	}
+}
+
+// SetEndpointResolver 设置接口域名解析器，为nil时直接请求 core.BaseAPIURL
+func (c *Client) SetEndpointResolver(resolver core.EndpointResolver) {
+	c.resolver = resolver
+	if c.req != nil {
+		c.req.SetEndpointResolver(resolver)
+	}
+}
+
+// GetEndpointResolver 获取接口域名解析器，未设置时返回nil
+func (c *Client) GetEndpointResolver() core.EndpointResolver {
+	return c.resolver
+}
+
+// SetMetrics 设置指标收集器，为nil时不收集
+// 设置后存储会被包装为 *storage.InstrumentedStorage 以记录存储操作耗时
+func (c *Client) SetMetrics(metrics core.Metrics) {
+	if metrics == nil {
+		metrics = core.NopMetrics{}
+	}
+	c.metrics = metrics
+	c.instrumentStorage()
+	if c.req != nil {
+		c.req.SetMetrics(metrics)
+	}
+}
+
+// GetMetrics 获取指标收集器
+func (c *Client) GetMetrics() core.Metrics {
+	return c.metrics
+}
+
+// SetTracer 设置链路追踪，为nil时不追踪
+// 设置后存储会被包装为 *storage.InstrumentedStorage 以追踪存储操作
+func (c *Client) SetTracer(tracer core.Tracer) {
+	if tracer == nil {
+		tracer = core.NopTracer{}
+	}
+	c.tracer = tracer
+	c.instrumentStorage()
+	if c.req != nil {
+		c.req.SetTracer(tracer)
 	}
 }
 
-// GetAccessToken 获取公众号access_token
+// GetTracer 获取链路追踪
+func (c *Client) GetTracer() core.Tracer {
+	return c.tracer
+}
+
+// instrumentStorage 设置了指标收集器或链路追踪时包装存储，都未设置时还原为原始存储
+func (c *Client) instrumentStorage() {
+	if instrumented, ok := c.storage.(*storage.InstrumentedStorage); ok {
+		c.storage = instrumented.Unwrap()
+	}
+	_, nopMetrics := c.metrics.(core.NopMetrics)
+	_, nopTracer := c.tracer.(core.NopTracer)
+	if !nopMetrics || !nopTracer {
+		c.storage = storage.NewInstrumentedStorage(c.storage, c.metrics, c.tracer)
+	}
+}
+
+// refreshToken 从微信获取token，记录刷新指标并创建span
+// @param kind string token类型，如 core.TokenKindAccessToken
+// @param fetch func(ctx context.Context) error 获取token的请求
+func (c *Client) refreshToken(ctx context.Context, kind string, fetch func(ctx context.Context) error) error {
+	ctx, span := c.tracer.Start(ctx, core.SpanTokenRefresh, core.Attr(core.AttrTokenKind, kind))
+	err := fetch(ctx)
+	c.metrics.IncCounter(core.MetricTokenRefresh, core.Labels{"kind": kind, "result": core.ResultLabel(err)})
+	core.EndSpan(span, err)
+	return err
+}
+
+// newRequest 创建请求对象，token失效时自动刷新并重试
+func (c *Client) newRequest() *core.Request {
+	req := core.NewRequest(c.httpClient, c.logger)
+	req.SetTokenRefresher(c.refreshStaleToken)
+	req.SetRedactor(c.redactor)
+	req.SetRateLimiter(c.rateLimiter)
+	req.SetRetryPolicy(c.retryPolicy)
+	req.SetCircuitBreaker(c.breaker)
+	req.SetEndpointResolver(c.resolver)
+	req.SetMetrics(c.metrics)
+	req.SetTracer(c.tracer)
+	req.SetAppIDResolver(c.resolveAppID)
+	req.Use(c.middlewares...)
+	return req
+}
+
+// resolveAppID 公众号客户端发出的请求均属于当前公众号
+func (c *Client) resolveAppID(*url.URL) string {
+	return c.config.AppID
+}
+
+// GetAccessToken 获取公众号access_token，从 GetTokenProvider 返回的来源获取
 func (c *Client) GetAccessToken(ctx context.Context) (string, error) {
+	return c.GetTokenProvider().AccessToken(ctx, c.config.AppID)
+}
+
+// getSelfAccessToken 从存储获取access_token，过期时调用微信接口刷新
+func (c *Client) getSelfAccessToken(ctx context.Context) (string, error) {
 	// 从存储中获取token
-	token, err := c.storage.GetAuthorizerToken(ctx, c.config.AppID)
+	token, err := c.getStoredToken(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -121,7 +409,7 @@ func (c *Client) GetAccessToken(ctx context.Context) (string, error) {
 	}
 
 	// 重新获取access_token
-	return c.refreshAccessToken(ctx)
+	return c.refreshAccessToken(ctx, time.Now())
 }
 
 // GetStableAccessToken 获取稳定版access_token
@@ -139,15 +427,28 @@ func (c *Client) MakeRequestWithStableToken(ctx context.Context, method, url str
 	return c.stableTokenClient.MakeRequestWithStableToken(ctx, method, url, body, result)
 }
 
-// refreshAccessToken 刷新access_token
-func (c *Client) refreshAccessToken(ctx context.Context) (string, error) {
+// refreshAccessToken 刷新access_token，并发的刷新合并为一次请求，存储支持加锁时多个实例间互斥
+// @param before time.Time 存储中的token在该时间之后才过期时直接返回，不调用微信接口
+func (c *Client) refreshAccessToken(ctx context.Context, before time.Time) (string, error) {
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, c.accessTokenRefreshKey(), func(ctx context.Context) (string, error) {
+		return c.fetchAccessToken(ctx, before)
+	})
+}
+
+// accessTokenRefreshKey 返回access_token的刷新key
+func (c *Client) accessTokenRefreshKey() string {
+	return core.RefreshKey(core.TokenKindAccessToken, c.config.AppID)
+}
+
+// fetchAccessToken 从微信获取access_token并保存到存储
+func (c *Client) fetchAccessToken(ctx context.Context, before time.Time) (string, error) {
 	// 双重检查：再次从存储中获取
-	token, err := c.storage.GetAuthorizerToken(ctx, c.config.AppID)
+	token, err := c.getStoredToken(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	if token != nil && time.Now().Before(token.ExpiresAt) {
+	if token != nil && before.Before(token.ExpiresAt) {
 		return token.AuthorizerAccessToken, nil
 	}
 
@@ -161,34 +462,123 @@ func (c *Client) refreshAccessToken(ctx context.Context) (string, error) {
 		ExpiresIn   int    `json:"expires_in"`
 	}
 
-	err = c.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
+	start := time.Now()
+	err = c.refreshToken(ctx, core.TokenKindAccessToken, func(ctx context.Context) error {
+		return c.req.Make(ctx, &core.ReqMakeOpt{
+			Method: "GET",
+			URL:    apiURL,
+			Result: &result,
+		})
 	})
 	if err != nil {
 		return "", err
 	}
 
-	if !result.IsSuccess() {
-		return "", &result.APIResponse
-	}
-
 	// 更新存储
 	newToken := &storage.AuthorizerAccessToken{
 		AuthorizerAppID:       c.config.AppID,
 		AuthorizerAccessToken: result.AccessToken,
 		ExpiresIn:             result.ExpiresIn,
-		ExpiresAt:             time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+		ExpiresAt:             c.expiresAt(start, result.ExpiresIn),
 	}
 
 	if err := c.storage.SaveAuthorizerToken(ctx, c.config.AppID, newToken); err != nil {
-		return "", fmt.Errorf("保存公众号token失败: %v", err)
+		return "", fmt.Errorf("保存公众号token失败: %w", err)
 	}
 
 	return result.AccessToken, nil
 }
 
+// refreshStaleToken 清除被微信拒绝的access_token并获取新token
+// 如果存储中的token已被其他请求刷新，直接返回新token
+func (c *Client) refreshStaleToken(ctx context.Context, param, staleToken string) (string, error) {
+	if param != "access_token" {
+		return "", nil
+	}
+	return c.GetTokenProvider().RefreshAccessToken(ctx, c.config.AppID, staleToken)
+}
+
+// refreshStaleSelfToken 清除失效的access_token并调用微信接口获取新token
+func (c *Client) refreshStaleSelfToken(ctx context.Context, staleToken string) (string, error) {
+	// 与 refreshAccessToken 使用同一个刷新key，避免清除其他请求刚刷新的token
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, c.accessTokenRefreshKey(), func(ctx context.Context) (string, error) {
+		token, err := c.storage.GetAuthorizerToken(ctx, c.config.AppID)
+		if err != nil {
+			return "", err
+		}
+		if token == nil {
+			// 命名空间中还没有token时，失效的可能是升级前的token，只读不删除
+			if token, err = c.getLegacyToken(ctx); err != nil {
+				return "", err
+			}
+		} else if token.AuthorizerAccessToken == staleToken || !time.Now().Before(token.ExpiresAt) {
+			if err := c.storage.DeleteAuthorizerToken(ctx, c.config.AppID); err != nil {
+				return "", fmt.Errorf("清除失效的公众号token失败: %w", err)
+			}
+		}
+		if token != nil && token.AuthorizerAccessToken != staleToken && time.Now().Before(token.ExpiresAt) {
+			return token.AuthorizerAccessToken, nil
+		}
+
+		// 删除后仍可能读到升级前的token，只使用比失效token更晚过期的token
+		before := time.Now()
+		if token != nil && token.ExpiresAt.After(before) {
+			before = token.ExpiresAt
+		}
+		return c.fetchAccessToken(ctx, before)
+	})
+}
+
+// RefreshTargets 实现 core.RefreshSource 接口，返回公众号的access_token，供 core.Refresher 主动刷新
+// 配置了 Config.UseStableToken 时返回稳定版access_token；token来源未实现 core.RefreshSource（如只读来源）时不主动刷新
+func (c *Client) RefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	if source, ok := c.GetTokenProvider().(core.RefreshSource); ok {
+		return source.RefreshTargets(ctx)
+	}
+	return nil, nil
+}
+
+// selfRefreshTargets 返回存储中access_token的主动刷新信息
+func (c *Client) selfRefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	token, err := c.getStoredToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取公众号token失败: %w", err)
+	}
+
+	target := core.RefreshTarget{
+		Kind:  core.TokenKindAccessToken,
+		AppID: c.config.AppID,
+		Refresh: func(ctx context.Context, before time.Time) error {
+			_, err := c.refreshAccessToken(ctx, before)
+			return err
+		},
+	}
+	if token != nil {
+		target.ExpiresAt = token.ExpiresAt
+	}
+	return []core.RefreshTarget{target}, nil
+}
+
+// Token 实现 core.Caller 接口，返回公众号的access_token
+func (c *Client) Token(ctx context.Context, param string) (string, error) {
+	if param != core.ParamAccessToken {
+		return "", fmt.Errorf("公众号接口不支持 %s", param)
+	}
+	return c.GetAccessToken(ctx)
+}
+
+// Make 实现 core.Caller 接口，使用客户端配置的中间件、限流、重试等组件发送请求
+// 可配合 core.Call 调用未封装的接口
+func (c *Client) Make(ctx context.Context, options *core.ReqMakeOpt) error {
+	return c.req.Make(ctx, options)
+}
+
+// Download 实现 core.Downloader 接口，以流的方式返回响应内容
+// 可配合 core.CallDownload 下载未封装的媒体接口
+func (c *Client) Download(ctx context.Context, options *core.ReqMakeOpt) (*core.Download, error) {
+	return c.req.Download(ctx, options)
+}
+
 // GetConfig 获取配置信息
 func (c *Client) GetConfig() *Config {
 	return c.config
@@ -206,33 +596,12 @@ func (c *Client) GetLogger() logger.LoggerInterface {
 // 请求方式：POST https://api.weixin.qq.com/cgi-bin/clear_quota?access_token=ACCESS_TOKEN
 // 请求体：{"appid":"APPID"}
 func (c *Client) ClearQuota(ctx context.Context) error {
-	// 获取access_token
-	accessToken, err := c.GetAccessToken(ctx)
-	if err != nil {
-		return fmt.Errorf("获取access_token失败: %v", err)
-	}
-
-	// 构建请求URL
-	url := fmt.Sprintf("%s?access_token=%s", URLClearQuota, accessToken)
-
-	// 构建请求体
 	request := ClearQuotaRequest{
 		AppID: c.config.AppID,
 	}
 
-	var response ClearQuotaResponse
-	err = c.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    url,
-		Body:   request,
-		Result: &response,
-	})
-	if err != nil {
-		return fmt.Errorf("清空API调用次数失败: %v", err)
-	}
-
-	if !response.IsSuccess() {
-		return &response.APIResponse
+	if _, err := core.Call(ctx, c, endpointClearQuota, request); err != nil {
+		return fmt.Errorf("清空API调用次数失败: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,71 @@
+package official_account_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestClientReadsTokenSavedBeforeNamespace(t *testing.T) {
+	server := wegotest.NewServer().AddApp("wx_test", "secret")
+	defer server.Close()
+
+	// 升级前的版本将token保存在默认命名空间
+	resp, err := http.Get(server.URL + "/cgi-bin/token?grant_type=client_credential&appid=wx_test&secret=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	legacy := &storage.AuthorizerAccessToken{AuthorizerAppID: "wx_test", AuthorizerAccessToken: result.AccessToken, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.SaveAuthorizerToken(ctx, "wx_test", legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	client := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_test", AppSecret: "secret"},
+		store, server.Resolver())
+	menus := official_account.NewMenuClient(client)
+	menu := &official_account.Menu{Button: []official_account.Button{{Type: "click", Name: "菜单", Key: "K1"}}}
+	if _, err := menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("create menu: %v", err)
+	}
+	if n := len(server.Requests("/cgi-bin/token")); n != 1 {
+		t.Fatalf("expected the legacy token to be used, got %d token requests", n)
+	}
+
+	// 旧token失效后获取的新token只保存到命名空间
+	server.ExpireTokens()
+	if _, err := menus.GetMenu(ctx); err != nil {
+		t.Fatalf("get menu with stale legacy token: %v", err)
+	}
+	if n := len(server.Requests("/cgi-bin/token")); n != 2 {
+		t.Fatalf("expected 2 token requests, got %d", n)
+	}
+	scoped, err := storage.Scope(store, storage.OfficialAccountNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := scoped.GetAuthorizerToken(ctx, "wx_test"); err != nil || token == nil || token.AuthorizerAccessToken == result.AccessToken {
+		t.Fatalf("expected a new token in the namespace, got %+v, %v", token, err)
+	}
+	if token, err := store.GetAuthorizerToken(ctx, "wx_test"); err != nil || token == nil || token.AuthorizerAccessToken != result.AccessToken {
+		t.Fatalf("legacy token should be left untouched, got %+v, %v", token, err)
+	}
+}
@@ -10,6 +10,10 @@ type Config struct {
 	AppSecret string `json:"app_secret" ini:"app_secret"` // 公众号appsecret
 	Token     string `json:"token" ini:"token"`           // 消息校验Token
 	AESKey    string `json:"aes_key" ini:"aes_key"`       // 消息加解密Key
+
+	// UseStableToken 使用稳定版access_token作为所有接口的默认token
+	// 稳定版access_token在有效期内重复获取不会使之前的token失效，适合多个服务共用同一个公众号
+	UseStableToken bool `json:"use_stable_token" ini:"use_stable_token"`
 }
 
 // StableAccessTokenMode 稳定版access_token模式
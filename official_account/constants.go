@@ -74,16 +74,18 @@ const (
 	URLGetCurrentAutoreplyInfo = core.BaseAPIURL + "/cgi-bin/get_current_autoreply_info"
 
 	// 素材管理
-	URLUploadMaterial      = core.BaseAPIURL + "/cgi-bin/media/upload"
-	URLGetMaterial         = core.BaseAPIURL + "/cgi-bin/media/get"
-	URLDeleteMaterial      = core.BaseAPIURL + "/cgi-bin/material/del_material"
-	URLUpdateNews          = core.BaseAPIURL + "/cgi-bin/material/update_news"
-	URLGetMaterialCount    = core.BaseAPIURL + "/cgi-bin/material/get_materialcount"
-	URLBatchGetMaterial    = core.BaseAPIURL + "/cgi-bin/material/batchget_material"
-	URLAddNews             = core.BaseAPIURL + "/cgi-bin/material/add_news"
-	URLMaterialUploadImage = core.BaseAPIURL + "/cgi-bin/media/uploadimg"
-	URLUploadVideo         = core.BaseAPIURL + "/cgi-bin/material/add_material"
-	URLGetHDVoice          = core.BaseAPIURL + "/cgi-bin/media/get/jssdk"
+	URLUploadMaterial       = core.BaseAPIURL + "/cgi-bin/media/upload"
+	URLGetMaterial          = core.BaseAPIURL + "/cgi-bin/media/get"
+	URLGetPermanentMaterial = core.BaseAPIURL + "/cgi-bin/material/get_material"
+	URLDeleteMaterial       = core.BaseAPIURL + "/cgi-bin/material/del_material"
+	URLUpdateNews           = core.BaseAPIURL + "/cgi-bin/material/update_news"
+	URLGetMaterialCount     = core.BaseAPIURL + "/cgi-bin/material/get_materialcount"
+	URLBatchGetMaterial     = core.BaseAPIURL + "/cgi-bin/material/batchget_material"
+	URLAddNews              = core.BaseAPIURL + "/cgi-bin/material/add_news"
+	URLMaterialUploadImage  = core.BaseAPIURL + "/cgi-bin/media/uploadimg"
+	URLUploadVideo          = core.BaseAPIURL + "/cgi-bin/material/add_material"
+	URLAddMaterial          = core.BaseAPIURL + "/cgi-bin/material/add_material"
+	URLGetHDVoice           = core.BaseAPIURL + "/cgi-bin/media/get/jssdk"
 
 	// 草稿管理
 	URLAddDraft      = core.BaseAPIURL + "/cgi-bin/draft/add"
@@ -3,11 +3,29 @@ package official_account
 import (
 	"context"
 	"fmt"
-	"net/url"
+	"io"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointGetMsgType              = core.Endpoint[SendCustomMessageRequest, SendCustomMessageResponse]{Method: "POST", URL: URLMessageCustomSend, Token: core.ParamAccessToken}
+	endpointAddCustomAccount        = core.Endpoint[AddCustomAccountRequest, AddCustomAccountResponse]{Method: "POST", URL: URLAddCustomAccount, Token: core.ParamAccessToken}
+	endpointUpdateCustomAccount     = core.Endpoint[UpdateCustomAccountRequest, UpdateCustomAccountResponse]{Method: "POST", URL: URLUpdateCustomAccount, Token: core.ParamAccessToken}
+	endpointDeleteCustomAccount     = core.Endpoint[DeleteCustomAccountRequest, DeleteCustomAccountResponse]{Method: "POST", URL: URLDeleteCustomAccount, Token: core.ParamAccessToken}
+	endpointGetAllCustomAccounts    = core.Endpoint[core.NoParams, GetAllCustomAccountsResponse]{Method: "GET", URL: URLGetAllCustomAccounts, Token: core.ParamAccessToken}
+	endpointGetOnlineCustomAccounts = core.Endpoint[core.NoParams, GetOnlineCustomAccountsResponse]{Method: "GET", URL: URLGetOnlineCustomAccounts, Token: core.ParamAccessToken}
+	endpointCreateCustomSession     = core.Endpoint[CreateCustomSessionRequest, CreateCustomSessionResponse]{Method: "POST", URL: URLCreateCustomSession, Token: core.ParamAccessToken}
+	endpointCloseCustomSession      = core.Endpoint[CloseCustomSessionRequest, CloseCustomSessionResponse]{Method: "POST", URL: URLCloseCustomSession, Token: core.ParamAccessToken}
+	endpointGetCustomSession        = core.Endpoint[GetCustomSessionRequest, GetCustomSessionResponse]{Method: "POST", URL: URLGetCustomSession, Token: core.ParamAccessToken}
+	endpointGetCustomSessionList    = core.Endpoint[GetCustomSessionListRequest, GetCustomSessionListResponse]{Method: "POST", URL: URLGetCustomSessionList, Token: core.ParamAccessToken}
+	endpointGetWaitCase             = core.Endpoint[core.NoParams, GetWaitCaseResponse]{Method: "GET", URL: URLGetWaitCase, Token: core.ParamAccessToken}
+	endpointTyping                  = core.Endpoint[TypingRequest, TypingResponse]{Method: "POST", URL: URLTyping, Token: core.ParamAccessToken}
+	endpointGetMsgRecord            = core.Endpoint[GetMsgRecordRequest, GetMsgRecordResponse]{Method: "POST", URL: URLGetMsgRecord, Token: core.ParamAccessToken}
+	endpointSetCustomAccountHeadImg = core.Endpoint[*core.Multipart, SetCustomAccountHeadImgResponse]{Method: "POST", URL: URLSetCustomAccountHeadImg, Token: core.ParamAccessToken}
+)
+
 // CustomClient 客服消息客户端
 type CustomClient struct {
 	Client *Client
@@ -211,11 +229,6 @@ type DeleteCustomAccountResponse struct {
 	core.APIResponse
 }
 
-// SetCustomAccountHeadImgRequest 设置客服账号头像请求
-type SetCustomAccountHeadImgRequest struct {
-	KfAccount string `json:"kf_account"`
-}
-
 // SetCustomAccountHeadImgResponse 设置客服账号头像响应
 type SetCustomAccountHeadImgResponse struct {
 	core.APIResponse
@@ -330,11 +343,6 @@ type MsgRecord struct {
 
 // SendCustomMessage 发送客服消息
 func (c *CustomClient) SendCustomMessage(ctx context.Context, touser string, message CustomMessage) (*SendCustomMessageResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := SendCustomMessageRequest{
 		Touser:  touser,
 		MsgType: message.GetMsgType(),
@@ -364,342 +372,104 @@ func (c *CustomClient) SendCustomMessage(ctx context.Context, touser string, mes
 		return nil, fmt.Errorf("unsupported message type: %s", message.GetMsgType())
 	}
 
-	var result SendCustomMessageResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLMessageCustomSend, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetMsgType, request)
 }
 
 // AddCustomAccount 添加客服账号
 func (c *CustomClient) AddCustomAccount(ctx context.Context, kfAccount, nickname string) (*AddCustomAccountResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddCustomAccountRequest{
 		KfAccount: kfAccount,
 		Nickname:  nickname,
 	}
 
-	var result AddCustomAccountResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLAddCustomAccount, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddCustomAccount, request)
 }
 
 // UpdateCustomAccount 修改客服账号
 func (c *CustomClient) UpdateCustomAccount(ctx context.Context, kfAccount, nickname string) (*UpdateCustomAccountResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := UpdateCustomAccountRequest{
 		KfAccount: kfAccount,
 		Nickname:  nickname,
 	}
 
-	var result UpdateCustomAccountResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUpdateCustomAccount, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUpdateCustomAccount, request)
 }
 
 // DeleteCustomAccount 删除客服账号
 func (c *CustomClient) DeleteCustomAccount(ctx context.Context, kfAccount string) (*DeleteCustomAccountResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteCustomAccountRequest{
 		KfAccount: kfAccount,
 	}
 
-	var result DeleteCustomAccountResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDeleteCustomAccount, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteCustomAccount, request)
 }
 
 // SetCustomAccountHeadImg 设置客服账号头像
-func (c *CustomClient) SetCustomAccountHeadImg(ctx context.Context, kfAccount string) (*SetCustomAccountHeadImgResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
+// 接口文档：https://developers.weixin.qq.com/doc/offiaccount/Customer_Service/Customer_Service_Management.html
+// 限制：头像图片文件必须是jpg格式，推荐使用640*640大小的图片
+// 请求方式：POST multipart/form-data
+// 参数:
+//   - kfAccount: 完整客服账号，格式为：账号前缀@公众号微信号
+//   - filename: 文件名
+//   - reader: 头像图片内容
+func (c *CustomClient) SetCustomAccountHeadImg(ctx context.Context, kfAccount, filename string, reader io.Reader) (*SetCustomAccountHeadImgResponse, error) {
+	multipart := &core.Multipart{
+		Files: []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
 	}
-
-	request := SetCustomAccountHeadImgRequest{
-		KfAccount: kfAccount,
-	}
-
-	var result SetCustomAccountHeadImgResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLSetCustomAccountHeadImg, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointSetCustomAccountHeadImg.WithQuery(map[string]string{"kf_account": kfAccount}), multipart)
 }
 
 // GetAllCustomAccounts 获取所有客服账号
 func (c *CustomClient) GetAllCustomAccounts(ctx context.Context) (*GetAllCustomAccountsResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetAllCustomAccountsResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetAllCustomAccounts, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetAllCustomAccounts, core.NoParams{})
 }
 
 // GetOnlineCustomAccounts 获取在线客服接待信息
 func (c *CustomClient) GetOnlineCustomAccounts(ctx context.Context) (*GetOnlineCustomAccountsResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetOnlineCustomAccountsResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetOnlineCustomAccounts, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetOnlineCustomAccounts, core.NoParams{})
 }
 
 // CreateCustomSession 创建客服会话
 func (c *CustomClient) CreateCustomSession(ctx context.Context, kfAccount, openid string) (*CreateCustomSessionResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := CreateCustomSessionRequest{
 		KfAccount: kfAccount,
 		OpenID:    openid,
 	}
 
-	var result CreateCustomSessionResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLCreateCustomSession, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointCreateCustomSession, request)
 }
 
 // CloseCustomSession 关闭客服会话
 func (c *CustomClient) CloseCustomSession(ctx context.Context, kfAccount, openid string) (*CloseCustomSessionResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := CloseCustomSessionRequest{
 		KfAccount: kfAccount,
 		OpenID:    openid,
 	}
 
-	var result CloseCustomSessionResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLCloseCustomSession, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointCloseCustomSession, request)
 }
 
 // GetCustomSession 获取客服会话
 func (c *CustomClient) GetCustomSession(ctx context.Context, openid string) (*GetCustomSessionResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetCustomSessionRequest{
 		OpenID: openid,
 	}
 
-	var result GetCustomSessionResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetCustomSession, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetCustomSession, request)
 }
 
 // GetCustomSessionList 获取客服会话列表
 func (c *CustomClient) GetCustomSessionList(ctx context.Context, kfAccount string) (*GetCustomSessionListResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetCustomSessionListRequest{
 		KfAccount: kfAccount,
 	}
 
-	var result GetCustomSessionListResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetCustomSessionList, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetCustomSessionList, request)
 }
 
 // GetWaitCase 获取未接入会话列表
 func (c *CustomClient) GetWaitCase(ctx context.Context) (*GetWaitCaseResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetWaitCaseResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetWaitCase, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetWaitCase, core.NoParams{})
 }
 
 // TypingRequest 客服输入状态请求
@@ -722,33 +492,12 @@ type TypingResponse struct {
 // 接口说明：控制客服输入状态，让用户看到客服"正在输入"的状态
 // 注意事项：此接口需要客服账号已绑定且在线
 func (c *CustomClient) Typing(ctx context.Context, toUser, command string) (*TypingResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := TypingRequest{
 		ToUser:  toUser,
 		Command: command,
 	}
 
-	var result TypingResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTyping, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointTyping, request)
 }
 
 // TypingStart 开始客服输入状态
@@ -769,11 +518,6 @@ func (c *CustomClient) TypingCancel(ctx context.Context, toUser string) (*Typing
 
 // GetMsgRecord 获取聊天记录
 func (c *CustomClient) GetMsgRecord(ctx context.Context, startTime, endTime int64, msgID int64, number int) (*GetMsgRecordResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetMsgRecordRequest{
 		StartTime: startTime,
 		EndTime:   endTime,
@@ -781,21 +525,5 @@ func (c *CustomClient) GetMsgRecord(ctx context.Context, startTime, endTime int6
 		Number:    number,
 	}
 
-	var result GetMsgRecordResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetMsgRecord, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetMsgRecord, request)
 }
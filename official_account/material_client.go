@@ -6,14 +6,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointDeleteMaterial      = core.Endpoint[DeleteMaterialRequest, DeleteMaterialResponse]{Method: "POST", URL: URLDeleteMaterial, Token: core.ParamAccessToken}
+	endpointUpdateMaterial      = core.Endpoint[UpdateNewsRequest, UpdateNewsResponse]{Method: "POST", URL: URLUpdateNews, Token: core.ParamAccessToken}
+	endpointGetMaterialCount    = core.Endpoint[core.NoParams, GetMaterialCountResponse]{Method: "GET", URL: URLGetMaterialCount, Token: core.ParamAccessToken}
+	endpointBatchGetMaterial    = core.Endpoint[BatchGetMaterialRequest, BatchGetMaterialResponse]{Method: "POST", URL: URLBatchGetMaterial, Token: core.ParamAccessToken}
+	endpointAddNews             = core.Endpoint[AddNewsRequest, AddNewsResponse]{Method: "POST", URL: URLAddNews, Token: core.ParamAccessToken}
+	endpointUploadVideo         = core.Endpoint[UploadVideoRequest, UploadVideoResponse]{Method: "POST", URL: URLUploadVideo, Token: core.ParamAccessToken}
+	endpointAddDraft            = core.Endpoint[AddDraftRequest, AddDraftResponse]{Method: "POST", URL: URLAddDraft, Token: core.ParamAccessToken}
+	endpointGetDraft            = core.Endpoint[GetDraftRequest, GetDraftResponse]{Method: "POST", URL: URLGetDraft, Token: core.ParamAccessToken}
+	endpointDeleteDraft         = core.Endpoint[DeleteDraftRequest, DeleteDraftResponse]{Method: "POST", URL: URLDeleteDraft, Token: core.ParamAccessToken}
+	endpointGetDraftCount       = core.Endpoint[core.NoParams, GetDraftCountResponse]{Method: "GET", URL: URLGetDraftCount, Token: core.ParamAccessToken}
+	endpointBatchGetDraft       = core.Endpoint[BatchGetDraftRequest, BatchGetDraftResponse]{Method: "POST", URL: URLBatchGetDraft, Token: core.ParamAccessToken}
+	endpointUpdateDraft         = core.Endpoint[UpdateDraftRequest, UpdateDraftResponse]{Method: "POST", URL: URLUpdateDraft, Token: core.ParamAccessToken}
+	endpointUploadMaterial      = core.Endpoint[*core.Multipart, UploadMaterialResponse]{Method: "POST", URL: URLUploadMaterial, Token: core.ParamAccessToken}
+	endpointMaterialUploadImage = core.Endpoint[*core.Multipart, MaterialUploadImageResponse]{Method: "POST", URL: URLMaterialUploadImage, Token: core.ParamAccessToken}
+	endpointAddMaterial         = core.Endpoint[*core.Multipart, AddMaterialResponse]{Method: "POST", URL: URLAddMaterial, Token: core.ParamAccessToken}
+	endpointDownloadMaterial    = core.Endpoint[GetMaterialRequest, core.Download]{Method: "POST", URL: URLGetPermanentMaterial, Token: core.ParamAccessToken}
+	endpointDownloadMedia       = core.Endpoint[GetMediaRequest, core.Download]{Method: "GET", URL: URLGetMaterial, Token: core.ParamAccessToken}
+	endpointDownloadHDVoice     = core.Endpoint[GetHDVoiceRequest, core.Download]{Method: "GET", URL: URLGetHDVoice, Token: core.ParamAccessToken}
+)
+
 // MaterialClient 素材管理客户端
 type MaterialClient struct {
 	Client *Client
@@ -149,6 +167,16 @@ type DeleteMaterialRequest struct {
 	MediaID string `json:"media_id"`
 }
 
+// GetMaterialRequest 获取永久素材请求
+type GetMaterialRequest struct {
+	MediaID string `json:"media_id"`
+}
+
+// GetMediaRequest 获取临时素材请求，media_id 作为查询参数
+type GetMediaRequest struct {
+	MediaID string `json:"media_id"`
+}
+
 // DeleteMaterialResponse 删除素材响应
 type DeleteMaterialResponse struct {
 	core.APIResponse
@@ -160,241 +188,100 @@ type DeleteMaterialResponse struct {
 // 限制：图片大小不超过2MB，语音大小不超过2MB，视频大小不超过10MB，缩略图大小不超过64KB
 // 请求方式：POST multipart/form-data
 func (c *MaterialClient) UploadMaterial(ctx context.Context, materialType MaterialType, filename string, data []byte) (*UploadMaterialResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s&type=%s", URLUploadMaterial, url.QueryEscape(accessToken), materialType)
-
-	// 创建multipart/form-data请求
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// 创建文件字段
-	part, err := writer.CreateFormFile("media", filename)
-	if err != nil {
-		return nil, fmt.Errorf("创建文件字段失败: %v", err)
-	}
-
-	// 写入文件数据
-	if _, err := part.Write(data); err != nil {
-		return nil, fmt.Errorf("写入文件数据失败: %v", err)
-	}
-
-	// 关闭writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("关闭multipart writer失败: %v", err)
-	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// 发送请求
-	resp, err := c.Client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var result UploadMaterialResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
-	}
+	return c.UploadMaterialFromReader(ctx, materialType, filename, bytes.NewReader(data))
+}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+// UploadMaterialFromReader 从io.Reader上传临时素材
+// 文件内容以流的方式发送，适合上传较大的视频文件
+// 参数:
+//   - materialType: 素材类型
+//   - filename: 文件名，微信根据扩展名识别文件格式
+//   - reader: 文件内容，实现 io.Seeker 时token失效后可自动重试
+func (c *MaterialClient) UploadMaterialFromReader(ctx context.Context, materialType MaterialType, filename string, reader io.Reader) (*UploadMaterialResponse, error) {
+	multipart := &core.Multipart{
+		Files: []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
 	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUploadMaterial.WithQuery(map[string]string{"type": string(materialType)}), multipart)
 }
 
 // GetMaterial 获取永久素材
 func (c *MaterialClient) GetMaterial(ctx context.Context, mediaID string) ([]byte, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
+	download, err := c.DownloadMaterial(ctx, mediaID)
 	if err != nil {
 		return nil, err
 	}
+	defer download.Close()
 
-	request := map[string]string{
-		"media_id": mediaID,
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetMaterial, url.QueryEscape(accessToken))
-
-	// 使用MakeRequest获取原始响应数据
-	var respData []byte
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &respData,
-	})
+	data, err := io.ReadAll(download)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("读取素材内容失败: %w", err)
 	}
+	return data, nil
+}
 
-	return respData, nil
+// DownloadMaterial 以流的方式下载永久素材
+// 接口文档：https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Getting_Permanent_Assets.html
+// 功能：
+//   - 图片、语音等素材直接返回文件内容
+//   - 视频素材自动跟随返回的 down_url 下载视频文件
+//   - 图文素材返回JSON内容，可通过 IsJSON 判断
+//
+// 注意：返回的 *core.Download 使用完毕后必须关闭
+func (c *MaterialClient) DownloadMaterial(ctx context.Context, mediaID string) (*core.Download, error) {
+	return core.CallDownload(ctx, c.Client, endpointDownloadMaterial, GetMaterialRequest{MediaID: mediaID})
+}
+
+// DownloadMedia 以流的方式下载临时素材
+// 接口文档：https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_temporary_materials.html
+// 功能：视频素材自动跟随返回的 video_url 下载视频文件
+// 注意：返回的 *core.Download 使用完毕后必须关闭
+func (c *MaterialClient) DownloadMedia(ctx context.Context, mediaID string) (*core.Download, error) {
+	return core.CallDownload(ctx, c.Client, endpointDownloadMedia, GetMediaRequest{MediaID: mediaID})
 }
 
 // DeleteMaterial 删除永久素材
 func (c *MaterialClient) DeleteMaterial(ctx context.Context, mediaID string) (*DeleteMaterialResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteMaterialRequest{
 		MediaID: mediaID,
 	}
 
-	var result DeleteMaterialResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDeleteMaterial, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteMaterial, request)
 }
 
 // UpdateMaterial 修改永久图文素材
 func (c *MaterialClient) UpdateMaterial(ctx context.Context, mediaID string, index int, article NewsArticle) (*UpdateNewsResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := UpdateNewsRequest{
 		MediaID:  mediaID,
 		Index:    index,
 		Articles: article,
 	}
 
-	var result UpdateNewsResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUpdateNews, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUpdateMaterial, request)
 }
 
 // GetMaterialCount 获取素材总数
 func (c *MaterialClient) GetMaterialCount(ctx context.Context) (*GetMaterialCountResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetMaterialCountResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetMaterialCount, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetMaterialCount, core.NoParams{})
 }
 
 // BatchGetMaterial 批量获取素材列表
 func (c *MaterialClient) BatchGetMaterial(ctx context.Context, materialType MaterialType, offset, count int) (*BatchGetMaterialResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := BatchGetMaterialRequest{
 		Type:   materialType,
 		Offset: offset,
 		Count:  count,
 	}
 
-	var result BatchGetMaterialResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLBatchGetMaterial, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointBatchGetMaterial, request)
 }
 
 // AddNews 新增永久图文素材
 func (c *MaterialClient) AddNews(ctx context.Context, articles []NewsArticle) (*AddNewsResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddNewsRequest{
 		Articles: articles,
 	}
 
-	var result AddNewsResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLAddNews, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddNews, request)
 }
 
 // UploadImage 上传图文消息内的图片获取URL
@@ -403,64 +290,18 @@ func (c *MaterialClient) AddNews(ctx context.Context, articles []NewsArticle) (*
 // 限制：图片大小不超过2MB，支持JPG、PNG格式
 // 请求方式：POST multipart/form-data
 func (c *MaterialClient) UploadImage(ctx context.Context, filename string, data []byte) (*MaterialUploadImageResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLMaterialUploadImage, url.QueryEscape(accessToken))
-
-	// 创建multipart/form-data请求
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// 创建文件字段
-	part, err := writer.CreateFormFile("media", filename)
-	if err != nil {
-		return nil, fmt.Errorf("创建文件字段失败: %v", err)
-	}
-
-	// 写入文件数据
-	if _, err := part.Write(data); err != nil {
-		return nil, fmt.Errorf("写入文件数据失败: %v", err)
-	}
-
-	// 关闭writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("关闭multipart writer失败: %v", err)
-	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// 发送请求
-	resp, err := c.Client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var result MaterialUploadImageResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
-	}
+	return c.UploadImageFromReader(ctx, filename, bytes.NewReader(data))
+}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+// UploadImageFromReader 从io.Reader上传图文消息内的图片获取URL
+// 参数:
+//   - filename: 文件名，仅支持jpg、png格式
+//   - reader: 文件内容，实现 io.Seeker 时token失效后可自动重试
+func (c *MaterialClient) UploadImageFromReader(ctx context.Context, filename string, reader io.Reader) (*MaterialUploadImageResponse, error) {
+	multipart := &core.Multipart{
+		Files: []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
 	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointMaterialUploadImage, multipart)
 }
 
 // AddMaterialRequest 新增永久素材请求
@@ -488,104 +329,65 @@ type AddMaterialResponse struct {
 //   - 图片大小不超过2MB，支持JPG、PNG格式
 //   - 语音大小不超过2MB，支持AMR、MP3格式
 //   - 缩略图大小不超过64KB，支持JPG格式
-//   - 视频素材需要通过UploadMaterial先上传临时素材，再调用UploadVideo转换为永久素材
+//   - 视频素材需要额外提交标题和简介，请使用 AddVideoMaterial
 func (c *MaterialClient) AddMaterial(ctx context.Context, materialType MaterialType, filename string, data []byte) (*AddMaterialResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s&type=%s", URLUploadVideo, url.QueryEscape(accessToken), materialType)
-
-	// 创建multipart/form-data请求
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// 创建文件字段
-	part, err := writer.CreateFormFile("media", filename)
-	if err != nil {
-		return nil, fmt.Errorf("创建文件字段失败: %v", err)
-	}
-
-	// 写入文件数据
-	if _, err := part.Write(data); err != nil {
-		return nil, fmt.Errorf("写入文件数据失败: %v", err)
-	}
-
-	// 关闭writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("关闭multipart writer失败: %v", err)
-	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.AddMaterialFromReader(ctx, materialType, filename, bytes.NewReader(data))
+}
 
-	// 发送请求
-	resp, err := c.Client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
+// AddMaterialFromReader 从io.Reader新增永久素材
+// 参数:
+//   - materialType: 素材类型
+//   - filename: 文件名，微信根据扩展名识别文件格式
+//   - reader: 文件内容，实现 io.Seeker 时token失效后可自动重试
+func (c *MaterialClient) AddMaterialFromReader(ctx context.Context, materialType MaterialType, filename string, reader io.Reader) (*AddMaterialResponse, error) {
+	return c.addMaterial(ctx, materialType, filename, reader, nil)
+}
 
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
+// AddVideoMaterial 新增永久视频素材
+// 参考文档：https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Adding_Permanent_Assets.html
+// 注意事项：视频大小不超过10MB，支持MP4格式，需要在表单中额外提交 description 字段
+// 参数:
+//   - filename: 文件名
+//   - reader: 文件内容，以流的方式发送
+//   - title: 视频标题
+//   - introduction: 视频简介
+func (c *MaterialClient) AddVideoMaterial(ctx context.Context, filename string, reader io.Reader, title, introduction string) (*AddMaterialResponse, error) {
+	description, err := json.Marshal(map[string]string{
+		"title":        title,
+		"introduction": introduction,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var result AddMaterialResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+		return nil, fmt.Errorf("序列化视频描述失败: %w", err)
 	}
+	return c.addMaterial(ctx, MaterialTypeVideo, filename, reader, map[string]string{
+		"description": string(description),
+	})
+}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+// addMaterial 新增永久素材的通用实现
+func (c *MaterialClient) addMaterial(ctx context.Context, materialType MaterialType, filename string, reader io.Reader, fields map[string]string) (*AddMaterialResponse, error) {
+	multipart := &core.Multipart{
+		Files:  []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
+		Fields: fields,
 	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddMaterial.WithQuery(map[string]string{"type": string(materialType)}), multipart)
 }
 
 // UploadVideo 上传视频素材
 func (c *MaterialClient) UploadVideo(ctx context.Context, mediaID, title, description string) (*UploadVideoResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := UploadVideoRequest{
 		MediaID:     mediaID,
 		Title:       title,
 		Description: description,
 	}
 
-	var result UploadVideoResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUploadVideo, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUploadVideo, request)
 }
 
 // GetHDVoiceRequest 获取高清语音请求
 // 参考文档：https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_hd_voice.html
 // 接口说明：获取高清语音素材，用于JS-SDK的语音播放功能
-// 请求方式：POST application/json
+// 请求方式：GET，media_id 作为查询参数
 type GetHDVoiceRequest struct {
 	MediaID string `json:"media_id"` // 语音素材的media_id
 }
@@ -604,72 +406,24 @@ type GetHDVoiceResponse struct {
 //   - 需要先通过UploadMaterial上传语音素材获取media_id
 //   - 主要用于JS-SDK的wx.downloadVoice和wx.playVoice功能
 func (c *MaterialClient) GetHDVoice(ctx context.Context, mediaID string) (*GetHDVoiceResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
+	download, err := c.DownloadHDVoice(ctx, mediaID)
 	if err != nil {
 		return nil, err
 	}
+	defer download.Close()
 
-	request := GetHDVoiceRequest{
-		MediaID: mediaID,
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetHDVoice, url.QueryEscape(accessToken))
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-
-	// 序列化请求体
-	requestBody, err := json.Marshal(request)
+	data, err := io.ReadAll(download)
 	if err != nil {
-		return nil, fmt.Errorf("序列化请求体失败: %v", err)
-	}
-	req.Body = io.NopCloser(bytes.NewReader(requestBody))
-
-	// 发送请求
-	resp, err := c.Client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应数据
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	// 检查响应类型
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") {
-		// 如果是JSON响应，说明有错误
-		var result GetHDVoiceResponse
-		if err := json.Unmarshal(respBody, &result); err != nil {
-			return nil, fmt.Errorf("解析响应失败: %v", err)
-		}
-
-		if !result.IsSuccess() {
-			return nil, &result.APIResponse
-		}
-
-		return &result, nil
-	}
-
-	// 如果是二进制数据，直接返回
-	result := &GetHDVoiceResponse{
-		APIResponse: core.APIResponse{
-			ErrCode: 0,
-			ErrMsg:  "success",
-		},
-		Data: respBody,
+		return nil, fmt.Errorf("读取语音内容失败: %w", err)
 	}
+	return &GetHDVoiceResponse{APIResponse: core.APIResponse{ErrCode: 0, ErrMsg: "success"}, Data: data}, nil
+}
 
-	return result, nil
+// DownloadHDVoice 以流的方式下载高清语音素材
+// 接口文档：https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_hd_voice.html
+// 注意：返回的 *core.Download 使用完毕后必须关闭
+func (c *MaterialClient) DownloadHDVoice(ctx context.Context, mediaID string) (*core.Download, error) {
+	return core.CallDownload(ctx, c.Client, endpointDownloadHDVoice, GetHDVoiceRequest{MediaID: mediaID})
 }
 
 // DraftArticle 草稿文章
@@ -834,32 +588,11 @@ type UpdateDraftResponse struct {
 //   - 图文消息支持1到8条图文
 //   - 草稿不会自动发布到公众号
 func (c *MaterialClient) AddDraft(ctx context.Context, articles []DraftArticle) (*AddDraftResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddDraftRequest{
 		Articles: articles,
 	}
 
-	var result AddDraftResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLAddDraft, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddDraft, request)
 }
 
 // GetDraft 获取草稿
@@ -869,32 +602,11 @@ func (c *MaterialClient) AddDraft(ctx context.Context, articles []DraftArticle)
 //   - 只能获取草稿箱中的草稿
 //   - 返回草稿的详细信息
 func (c *MaterialClient) GetDraft(ctx context.Context, mediaID string) (*GetDraftResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetDraftRequest{
 		MediaID: mediaID,
 	}
 
-	var result GetDraftResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetDraft, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetDraft, request)
 }
 
 // DeleteDraft 删除草稿
@@ -904,32 +616,11 @@ func (c *MaterialClient) GetDraft(ctx context.Context, mediaID string) (*GetDraf
 //   - 只能删除草稿箱中的草稿
 //   - 删除后无法恢复
 func (c *MaterialClient) DeleteDraft(ctx context.Context, mediaID string) (*DeleteDraftResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteDraftRequest{
 		MediaID: mediaID,
 	}
 
-	var result DeleteDraftResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDeleteDraft, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteDraft, request)
 }
 
 // GetDraftCount 获取草稿总数
@@ -938,27 +629,7 @@ func (c *MaterialClient) DeleteDraft(ctx context.Context, mediaID string) (*Dele
 // 注意事项：
 //   - 返回草稿箱中的草稿总数
 func (c *MaterialClient) GetDraftCount(ctx context.Context) (*GetDraftCountResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetDraftCountResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetDraftCount, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetDraftCount, core.NoParams{})
 }
 
 // BatchGetDraft 批量获取草稿列表
@@ -969,34 +640,13 @@ func (c *MaterialClient) GetDraftCount(ctx context.Context) (*GetDraftCountRespo
 //   - count: 返回草稿的数量，取值在1到20之间
 //   - no_content: 是否不返回content字段，1表示不返回，0表示返回
 func (c *MaterialClient) BatchGetDraft(ctx context.Context, offset, count, noContent int) (*BatchGetDraftResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := BatchGetDraftRequest{
 		Offset:    offset,
 		Count:     count,
 		NoContent: noContent,
 	}
 
-	var result BatchGetDraftResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLBatchGetDraft, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointBatchGetDraft, request)
 }
 
 // UpdateDraft 修改草稿
@@ -1006,32 +656,11 @@ func (c *MaterialClient) BatchGetDraft(ctx context.Context, offset, count, noCon
 //   - 只能修改草稿箱中的草稿
 //   - 修改后草稿的media_id不变
 func (c *MaterialClient) UpdateDraft(ctx context.Context, mediaID string, index int, article DraftArticle) (*UpdateDraftResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := UpdateDraftRequest{
 		MediaID:  mediaID,
 		Index:    index,
 		Articles: article,
 	}
 
-	var result UpdateDraftResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUpdateDraft, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUpdateDraft, request)
 }
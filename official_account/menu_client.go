@@ -2,12 +2,21 @@ package official_account
 
 import (
 	"context"
-	"fmt"
-	"net/url"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointCreateMenu             = core.Endpoint[*Menu, CreateMenuResponse]{Method: "POST", URL: URLCreateMenu, Token: core.ParamAccessToken}
+	endpointGetCurrentSelfmenuInfo = core.Endpoint[core.NoParams, GetCurrentMenuResponse]{Method: "GET", URL: URLGetCurrentMenu, Token: core.ParamAccessToken}
+	endpointGetMenu                = core.Endpoint[core.NoParams, GetMenuResponse]{Method: "GET", URL: URLGetMenu, Token: core.ParamAccessToken}
+	endpointDeleteMenu             = core.Endpoint[core.NoParams, DeleteMenuResponse]{Method: "GET", URL: URLDeleteMenu, Token: core.ParamAccessToken}
+	endpointAddConditionalMenu     = core.Endpoint[*ConditionalMenu, AddConditionalMenuResponse]{Method: "POST", URL: URLAddConditionalMenu, Token: core.ParamAccessToken}
+	endpointTryMatchMenu           = core.Endpoint[TryMatchMenuRequest, TryMatchMenuResponse]{Method: "POST", URL: URLTryMatchMenu, Token: core.ParamAccessToken}
+	endpointDeleteConditionalMenu  = core.Endpoint[core.NoParams, DeleteConditionalMenuResponse]{Method: "POST", URL: URLDeleteConditionalMenu, Token: core.ParamAccessToken}
+)
+
 // MenuClient 自定义菜单客户端
 type MenuClient struct {
 	Client *Client
@@ -105,182 +114,39 @@ type TryMatchMenuResponse struct {
 
 // CreateMenu 创建自定义菜单
 func (c *MenuClient) CreateMenu(ctx context.Context, menu *Menu) (*CreateMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result CreateMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLCreateMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   menu,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointCreateMenu, menu)
 }
 
 // GetCurrentSelfmenuInfo 获取当前菜单信息
 func (c *MenuClient) GetCurrentSelfmenuInfo(ctx context.Context) (*GetCurrentMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetCurrentMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetCurrentMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetCurrentSelfmenuInfo, core.NoParams{})
 }
 
 // GetMenu 获取菜单
 func (c *MenuClient) GetMenu(ctx context.Context) (*GetMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetMenu, core.NoParams{})
 }
 
 // DeleteMenu 删除菜单
 func (c *MenuClient) DeleteMenu(ctx context.Context) (*DeleteMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result DeleteMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDeleteMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteMenu, core.NoParams{})
 }
 
 // AddConditionalMenu 添加个性化菜单
 func (c *MenuClient) AddConditionalMenu(ctx context.Context, menu *ConditionalMenu) (*AddConditionalMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result AddConditionalMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLAddConditionalMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   menu,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddConditionalMenu, menu)
 }
 
 // DeleteConditionalMenu 删除个性化菜单
 func (c *MenuClient) DeleteConditionalMenu(ctx context.Context, menuID string) (*DeleteConditionalMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result DeleteConditionalMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s&menuid=%s", URLDeleteConditionalMenu, url.QueryEscape(accessToken), url.QueryEscape(menuID))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteConditionalMenu.WithQuery(map[string]string{"menuid": menuID}), core.NoParams{})
 }
 
 // TryMatchMenu 测试个性化菜单匹配
 func (c *MenuClient) TryMatchMenu(ctx context.Context, userID string) (*TryMatchMenuResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := TryMatchMenuRequest{
 		UserID: userID,
 	}
 
-	var result TryMatchMenuResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTryMatchMenu, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointTryMatchMenu, request)
 }
@@ -3,16 +3,25 @@ package official_account
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
-	"net/url"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointDeleteMassMsg           = core.Endpoint[DeleteMassMsgRequest, DeleteMassMsgResponse]{Method: "POST", URL: URLDeleteMassMsg, Token: core.ParamAccessToken}
+	endpointGetSpeed                = core.Endpoint[core.NoParams, GetSpeedResponse]{Method: "GET", URL: URLGetSpeed, Token: core.ParamAccessToken}
+	endpointMassMsgGet              = core.Endpoint[MassMsgGetRequest, MassMsgGetResponse]{Method: "POST", URL: URLMassMsgGet, Token: core.ParamAccessToken}
+	endpointMassSend                = core.Endpoint[MassSendRequest, MassSendResponse]{Method: "POST", URL: URLMassSend, Token: core.ParamAccessToken}
+	endpointPreview                 = core.Endpoint[PreviewRequest, PreviewResponse]{Method: "POST", URL: URLPreview, Token: core.ParamAccessToken}
+	endpointSendAll                 = core.Endpoint[SendAllRequest, SendAllResponse]{Method: "POST", URL: URLSendAll, Token: core.ParamAccessToken}
+	endpointSetSpeed                = core.Endpoint[SetSpeedRequest, SetSpeedResponse]{Method: "POST", URL: URLSetSpeed, Token: core.ParamAccessToken}
+	endpointUploadNewsMsg           = core.Endpoint[UploadNewsMsgRequest, UploadNewsMsgResponse]{Method: "POST", URL: URLUploadNewsMsg, Token: core.ParamAccessToken}
+	endpointGetCurrentAutoreplyInfo = core.Endpoint[core.NoParams, GetCurrentAutoreplyInfoResponse]{Method: "GET", URL: URLGetCurrentAutoreplyInfo, Token: core.ParamAccessToken}
+	endpointUploadImage             = core.Endpoint[*core.Multipart, UploadImageResponse]{Method: "POST", URL: URLUploadImage, Token: core.ParamAccessToken}
+)
+
 // MessageClient 消息管理客户端
 type MessageClient struct {
 	Client *Client
@@ -146,306 +155,93 @@ type UploadNewsMsgResponse struct {
 // 限制：图片大小不超过2MB，支持JPG、PNG格式
 // 请求方式：POST multipart/form-data
 func (c *MessageClient) UploadImage(ctx context.Context, filename string, imageData []byte) (*UploadImageResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUploadImage, url.QueryEscape(accessToken))
-
-	// 创建multipart/form-data请求
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// 创建文件字段
-	part, err := writer.CreateFormFile("media", filename)
-	if err != nil {
-		return nil, fmt.Errorf("创建文件字段失败: %v", err)
-	}
-
-	// 写入文件数据
-	if _, err := part.Write(imageData); err != nil {
-		return nil, fmt.Errorf("写入文件数据失败: %v", err)
-	}
-
-	// 关闭writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("关闭multipart writer失败: %v", err)
-	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// 发送请求
-	resp, err := c.Client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	var result UploadImageResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
-	}
+	return c.UploadImageFromReader(ctx, filename, bytes.NewReader(imageData))
+}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+// UploadImageFromReader 从io.Reader上传图文消息图片
+// 参数:
+//   - filename: 文件名，仅支持jpg、png格式
+//   - reader: 文件内容，实现 io.Seeker 时token失效后可自动重试
+func (c *MessageClient) UploadImageFromReader(ctx context.Context, filename string, reader io.Reader) (*UploadImageResponse, error) {
+	multipart := &core.Multipart{
+		Files: []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
 	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUploadImage, multipart)
 }
 
 // DeleteMassMsg 删除群发消息
 func (c *MessageClient) DeleteMassMsg(ctx context.Context, msgID int64, articleIdx int) (*DeleteMassMsgResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteMassMsgRequest{
 		MsgID:      msgID,
 		ArticleIdx: articleIdx,
 	}
 
-	var result DeleteMassMsgResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDeleteMassMsg, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteMassMsg, request)
 }
 
 // GetSpeed 获取群发速度
 func (c *MessageClient) GetSpeed(ctx context.Context) (*GetSpeedResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetSpeedResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetSpeed, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetSpeed, core.NoParams{})
 }
 
 // MassMsgGet 获取群发消息发送状态
 func (c *MessageClient) MassMsgGet(ctx context.Context, msgID int64) (*MassMsgGetResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := MassMsgGetRequest{
 		MsgID: msgID,
 	}
 
-	var result MassMsgGetResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLMassMsgGet, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointMassMsgGet, request)
 }
 
 // MassSend 根据OpenID群发消息
 func (c *MessageClient) MassSend(ctx context.Context, touser []string, msgType string, content interface{}) (*MassSendResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := MassSendRequest{
 		Touser:  touser,
 		MsgType: msgType,
 		Content: content,
 	}
 
-	var result MassSendResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLMassSend, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointMassSend, request)
 }
 
 // Preview 预览消息
 func (c *MessageClient) Preview(ctx context.Context, touser, msgType string, content interface{}) (*PreviewResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := PreviewRequest{
 		Touser:  touser,
 		MsgType: msgType,
 		Content: content,
 	}
 
-	var result PreviewResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLPreview, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointPreview, request)
 }
 
 // SendAll 根据标签群发消息
 func (c *MessageClient) SendAll(ctx context.Context, filter Filter, msgType string, content interface{}) (*SendAllResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := SendAllRequest{
 		Filter:  filter,
 		MsgType: msgType,
 		Content: content,
 	}
 
-	var result SendAllResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLSendAll, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointSendAll, request)
 }
 
 // SetSpeed 设置群发速度
 func (c *MessageClient) SetSpeed(ctx context.Context, speed int) (*SetSpeedResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := SetSpeedRequest{
 		Speed: speed,
 	}
 
-	var result SetSpeedResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLSetSpeed, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointSetSpeed, request)
 }
 
 // UploadNewsMsg 上传图文消息素材
 func (c *MessageClient) UploadNewsMsg(ctx context.Context, articles []Article) (*UploadNewsMsgResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := UploadNewsMsgRequest{
 		Articles: articles,
 	}
 
-	var result UploadNewsMsgResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLUploadNewsMsg, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointUploadNewsMsg, request)
 }
 
 // GetCurrentAutoreplyInfoResponse 获取当前自动回复信息响应
@@ -495,25 +291,5 @@ type GetCurrentAutoreplyInfoResponse struct {
 // 功能：获取公众号当前使用的自动回复规则，包括关注后自动回复、消息自动回复和关键词自动回复
 // 请求方式：GET
 func (c *MessageClient) GetCurrentAutoreplyInfo(ctx context.Context) (*GetCurrentAutoreplyInfoResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetCurrentAutoreplyInfoResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetCurrentAutoreplyInfo, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetCurrentAutoreplyInfo, core.NoParams{})
 }
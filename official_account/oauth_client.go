@@ -2,8 +2,10 @@ package official_account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/jcbowen/jcbaseGo/component/debugger"
 	"github.com/jcbowen/wego/core"
@@ -79,6 +81,7 @@ func (o *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthAc
 	}
 
 	var resp OAuthAccessTokenResponse
+	start := time.Now()
 	err := o.client.req.Make(ctx, &core.ReqMakeOpt{
 		Method: "GET",
 		URL:    URLSnsOAuth2AccessToken,
@@ -86,12 +89,9 @@ func (o *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthAc
 		Result: &resp,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("获取网页授权access_token失败: %v", err)
-	}
-
-	if !resp.IsSuccess() {
-		return nil, &resp.APIResponse
+		return nil, fmt.Errorf("获取网页授权access_token失败: %w", err)
 	}
+	resp.ExpiresAt = o.client.expiresAt(start, resp.ExpiresIn)
 
     o.logger.Info("获取网页授权access_token成功", map[string]interface{}{"openid": resp.OpenID, "scope": resp.Scope})
 	return &resp, nil
@@ -115,6 +115,7 @@ func (o *OAuthClient) RefreshAccessToken(ctx context.Context, refreshToken strin
 	}
 
 	var resp OAuthAccessTokenResponse
+	start := time.Now()
 	err := o.client.req.Make(ctx, &core.ReqMakeOpt{
 		Method: "GET",
 		URL:    URLSnsOAuth2RefreshToken,
@@ -122,12 +123,9 @@ func (o *OAuthClient) RefreshAccessToken(ctx context.Context, refreshToken strin
 		Result: &resp,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("刷新网页授权access_token失败: %v", err)
-	}
-
-	if !resp.IsSuccess() {
-		return nil, &resp.APIResponse
+		return nil, fmt.Errorf("刷新网页授权access_token失败: %w", err)
 	}
+	resp.ExpiresAt = o.client.expiresAt(start, resp.ExpiresIn)
 
     o.logger.Info("刷新网页授权access_token成功", map[string]interface{}{"openid": resp.OpenID})
 	return &resp, nil
@@ -166,11 +164,7 @@ func (o *OAuthClient) GetUserInfo(ctx context.Context, accessToken, openID, lang
 		Result: &resp,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("获取用户信息失败: %v", err)
-	}
-
-	if !resp.IsSuccess() {
-		return nil, &resp.APIResponse
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
 	}
 
     o.logger.Info("获取用户信息成功", map[string]interface{}{"openid": resp.OpenID, "nickname": resp.Nickname})
@@ -204,17 +198,17 @@ func (o *OAuthClient) ValidateAccessToken(ctx context.Context, accessToken, open
 		Result: &resp,
 	})
 	if err != nil {
-		return false, fmt.Errorf("检验access_token失败: %v", err)
-	}
-
-	// 如果返回的错误码为0，则表示有效
-	if resp.ErrCode == 0 {
-        o.logger.Info("检验access_token有效", map[string]interface{}{"openid": openID})
-		return true, nil
+		// 微信返回非0错误码，表示access_token无效
+		var apiErr *core.Error
+		if errors.As(err, &apiErr) && apiErr.ErrCode != 0 {
+			o.logger.Warn("检验access_token无效", map[string]interface{}{"openid": openID, "errcode": apiErr.ErrCode, "errmsg": apiErr.ErrMsg})
+			return false, nil
+		}
+		return false, fmt.Errorf("检验access_token失败: %w", err)
 	}
 
-    o.logger.Warn("检验access_token无效", map[string]interface{}{"openid": openID, "errcode": resp.ErrCode, "errmsg": resp.ErrMsg})
-	return false, nil
+	o.logger.Info("检验access_token有效", map[string]interface{}{"openid": openID})
+	return true, nil
 }
 
 // CompleteOAuthFlow 完整的网页授权流程
@@ -228,7 +222,7 @@ func (o *OAuthClient) CompleteOAuthFlow(ctx context.Context, code, scope string)
 	// 1. 获取access_token
 	accessTokenResp, err := o.GetAccessToken(ctx, code)
 	if err != nil {
-		return nil, nil, fmt.Errorf("获取access_token失败: %v", err)
+		return nil, nil, fmt.Errorf("获取access_token失败: %w", err)
 	}
 
 	// 2. 如果scope是snsapi_userinfo，获取用户信息
@@ -281,7 +275,7 @@ func (o *OAuthClient) GetUserInfoByCode(ctx context.Context, code string) (*OAut
 func (o *OAuthClient) GetOpenIDByCode(ctx context.Context, code string) (string, *OAuthAccessTokenResponse, error) {
 	accessTokenResp, err := o.GetAccessToken(ctx, code)
 	if err != nil {
-		return "", nil, fmt.Errorf("获取access_token失败: %v", err)
+		return "", nil, fmt.Errorf("获取access_token失败: %w", err)
 	}
 	return accessTokenResp.OpenID, accessTokenResp, nil
 }
@@ -2,21 +2,27 @@ package official_account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/storage"
 )
 
 // StableTokenClient 稳定版access_token客户端
+// 存储实现 storage.StableTokenStorage 时（内置存储均已实现）token保存到存储，多个实例共享；
+// 否则只缓存在进程内
 type StableTokenClient struct {
 	client *Client
+	local  *DefaultStableAccessTokenStorage // 存储不支持稳定版access_token时使用的进程内缓存
 }
 
 // NewStableTokenClient 创建稳定版access_token客户端
 func NewStableTokenClient(client *Client) *StableTokenClient {
 	return &StableTokenClient{
 		client: client,
+		local:  NewDefaultStableAccessTokenStorage(),
 	}
 }
 
@@ -39,11 +45,45 @@ func (c *StableTokenClient) GetStableAccessTokenForceRefresh(ctx context.Context
 func (c *StableTokenClient) getStableAccessTokenWithMode(ctx context.Context, mode StableAccessTokenMode) (*StableAccessTokenInfo, error) {
 	// 检查是否已有有效的token
 	if mode == StableAccessTokenModeNormal {
-		if token, err := c.getValidStableAccessToken(ctx); err == nil && token != nil {
+		token, err := c.getValidStableAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
 			return token, nil
 		}
 	}
 
+	// 并发的获取合并为一次请求，强制刷新模式的调用方也可能共享普通模式的结果
+	return c.refreshStableAccessToken(ctx, mode, time.Now())
+}
+
+// refreshStableAccessToken 刷新稳定版access_token，并发的刷新合并为一次请求，存储支持加锁时多个实例间互斥
+// @param mode StableAccessTokenMode 获取模式
+// @param before time.Time 普通模式下存储中的token在该时间之后才过期时直接返回，不调用微信接口
+func (c *StableTokenClient) refreshStableAccessToken(ctx context.Context, mode StableAccessTokenMode, before time.Time) (*StableAccessTokenInfo, error) {
+	return storage.RefreshWithLock(ctx, &c.client.refreshGroup, c.client.storage, c.refreshKey(), func(ctx context.Context) (*StableAccessTokenInfo, error) {
+		if mode == StableAccessTokenModeNormal {
+			// 双重检查：其他请求或实例可能已经刷新
+			token, err := c.loadStableAccessToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if token != nil && before.Before(token.ExpiresAt) {
+				return token, nil
+			}
+		}
+		return c.fetchStableAccessToken(ctx, mode)
+	})
+}
+
+// refreshKey 返回稳定版access_token的刷新key
+func (c *StableTokenClient) refreshKey() string {
+	return core.RefreshKey(core.TokenKindStableAccessToken, c.client.config.AppID)
+}
+
+// fetchStableAccessToken 从微信获取稳定版access_token并保存到存储
+func (c *StableTokenClient) fetchStableAccessToken(ctx context.Context, mode StableAccessTokenMode) (*StableAccessTokenInfo, error) {
 	// 构建请求参数
 	request := StableAccessTokenRequest{
 		GrantType:    core.GrantTypeClientCredential,
@@ -54,44 +94,144 @@ func (c *StableTokenClient) getStableAccessTokenWithMode(ctx context.Context, mo
 
 	// 调用API获取稳定版access_token
 	var result StableAccessTokenResponse
-	err := c.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    URLStableAccessToken,
-		Body:   request,
-		Result: &result,
+	start := time.Now()
+	err := c.client.refreshToken(ctx, core.TokenKindStableAccessToken, func(ctx context.Context) error {
+		return c.client.req.Make(ctx, &core.ReqMakeOpt{
+			Method: "POST",
+			URL:    URLStableAccessToken,
+			Body:   request,
+			Result: &result,
+		})
 	})
 	if err != nil {
-		return nil, fmt.Errorf("获取稳定版access_token失败: %v", err)
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+		return nil, fmt.Errorf("获取稳定版access_token失败: %w", err)
 	}
 
 	// 构建token信息
 	tokenInfo := &StableAccessTokenInfo{
 		AccessToken: result.AccessToken,
 		ExpiresIn:   result.ExpiresIn,
-		ExpiresAt:   time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+		ExpiresAt:   c.client.expiresAt(start, result.ExpiresIn),
 		Mode:        mode,
 	}
 
-	// 保存到存储（如果有存储接口）
-	if c.client.storage != nil {
-		// 这里需要将StableAccessTokenInfo转换为存储格式
-		// 由于存储接口目前只支持AuthorizerAccessToken，我们需要扩展存储接口
-		// 暂时先不保存，后续可以扩展存储接口
+	if err := c.saveStableAccessToken(ctx, tokenInfo); err != nil {
+		return nil, fmt.Errorf("保存稳定版access_token失败: %w", err)
 	}
 
 	return tokenInfo, nil
 }
 
-// getValidStableAccessToken 获取有效的稳定版access_token
+// refreshStaleToken 清除被微信拒绝的稳定版access_token并获取新token
+// 如果存储中的token已被其他请求刷新，直接返回新token
+func (c *StableTokenClient) refreshStaleToken(ctx context.Context, staleToken string) (string, error) {
+	return storage.RefreshWithLock(ctx, &c.client.refreshGroup, c.client.storage, c.refreshKey(), func(ctx context.Context) (string, error) {
+		token, err := c.loadStableAccessToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		if token != nil && token.AccessToken != staleToken && time.Now().Before(token.ExpiresAt) {
+			return token.AccessToken, nil
+		}
+
+		if err := c.deleteStableAccessToken(ctx); err != nil {
+			return "", fmt.Errorf("清除失效的稳定版access_token失败: %w", err)
+		}
+
+		token, err = c.fetchStableAccessToken(ctx, StableAccessTokenModeNormal)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	})
+}
+
+// getValidStableAccessToken 获取有效的稳定版access_token，不存在或已过期时返回nil
 func (c *StableTokenClient) getValidStableAccessToken(ctx context.Context) (*StableAccessTokenInfo, error) {
-	// 这里需要从存储中获取token
-	// 由于存储接口目前不支持稳定版token，暂时返回nil
-	// 后续可以扩展存储接口来支持
-	return nil, nil
+	token, err := c.loadStableAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// ExpiresAt已按提前过期策略提前
+	if token == nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// stableStorage 返回支持稳定版access_token的存储，不支持时返回nil
+func (c *StableTokenClient) stableStorage() storage.StableTokenStorage {
+	stable, _ := c.client.storage.(storage.StableTokenStorage)
+	return stable
+}
+
+// loadStableAccessToken 从存储读取稳定版access_token，不存在时返回nil
+func (c *StableTokenClient) loadStableAccessToken(ctx context.Context) (*StableAccessTokenInfo, error) {
+	appID := c.client.config.AppID
+	if stable := c.stableStorage(); stable != nil {
+		token, err := stable.GetStableToken(ctx, appID)
+		if !errors.Is(err, storage.ErrStableTokenNotSupported) {
+			if err != nil || token == nil {
+				return nil, err
+			}
+			return &StableAccessTokenInfo{
+				AccessToken: token.AccessToken,
+				ExpiresIn:   token.ExpiresIn,
+				ExpiresAt:   token.ExpiresAt,
+				Mode:        StableAccessTokenModeNormal,
+			}, nil
+		}
+	}
+	return c.local.GetStableAccessToken(appID)
+}
+
+// saveStableAccessToken 保存稳定版access_token到存储
+func (c *StableTokenClient) saveStableAccessToken(ctx context.Context, token *StableAccessTokenInfo) error {
+	appID := c.client.config.AppID
+	if stable := c.stableStorage(); stable != nil {
+		err := stable.SaveStableToken(ctx, appID, &storage.StableAccessToken{
+			AppID:       appID,
+			AccessToken: token.AccessToken,
+			ExpiresIn:   token.ExpiresIn,
+			ExpiresAt:   token.ExpiresAt,
+		})
+		if !errors.Is(err, storage.ErrStableTokenNotSupported) {
+			return err
+		}
+	}
+	return c.local.SetStableAccessToken(appID, token)
+}
+
+// deleteStableAccessToken 从存储删除稳定版access_token
+func (c *StableTokenClient) deleteStableAccessToken(ctx context.Context) error {
+	appID := c.client.config.AppID
+	if stable := c.stableStorage(); stable != nil {
+		err := stable.DeleteStableToken(ctx, appID)
+		if !errors.Is(err, storage.ErrStableTokenNotSupported) {
+			return err
+		}
+	}
+	return c.local.DeleteStableAccessToken(appID)
+}
+
+// RefreshTarget 返回稳定版access_token的主动刷新信息，供 core.Refresher 使用
+func (c *StableTokenClient) RefreshTarget(ctx context.Context) (core.RefreshTarget, error) {
+	target := core.RefreshTarget{
+		Kind:  core.TokenKindStableAccessToken,
+		AppID: c.client.config.AppID,
+		Refresh: func(ctx context.Context, before time.Time) error {
+			_, err := c.refreshStableAccessToken(ctx, StableAccessTokenModeNormal, before)
+			return err
+		},
+	}
+	token, err := c.loadStableAccessToken(ctx)
+	if err != nil {
+		return target, err
+	}
+	if token != nil {
+		target.ExpiresAt = token.ExpiresAt
+	}
+	return target, nil
 }
 
 // IsStableAccessTokenValid 检查稳定版access_token是否有效
@@ -109,14 +249,7 @@ func (c *StableTokenClient) IsStableAccessTokenValid(ctx context.Context, token
 
 // RefreshStableAccessTokenIfNeeded 如果需要则刷新稳定版access_token
 func (c *StableTokenClient) RefreshStableAccessTokenIfNeeded(ctx context.Context) (string, error) {
-	// 检查当前token是否即将过期（提前5分钟）
-	if token, err := c.getValidStableAccessToken(ctx); err == nil && token != nil {
-		if time.Until(token.ExpiresAt) > 5*time.Minute {
-			return token.AccessToken, nil
-		}
-	}
-
-	// 获取新的稳定版access_token
+	// 存储中的token有效时直接返回，否则获取新的稳定版access_token
 	newToken, err := c.GetStableAccessTokenNormal(ctx)
 	if err != nil {
 		return "", err
@@ -135,7 +268,7 @@ func (c *StableTokenClient) MakeRequestWithStableToken(ctx context.Context, meth
 	// 获取稳定版access_token
 	token, err := c.RefreshStableAccessTokenIfNeeded(ctx)
 	if err != nil {
-		return fmt.Errorf("获取稳定版access_token失败: %v", err)
+		return fmt.Errorf("获取稳定版access_token失败: %w", err)
 	}
 
 	// 构建带token的URL
@@ -2,12 +2,23 @@ package official_account
 
 import (
 	"context"
-	"fmt"
-	"net/url"
+	"strconv"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointGetWxaPubNewTemplate      = core.Endpoint[core.NoParams, GetWxaPubNewTemplateResponse]{Method: "GET", URL: URLGetWxaPubTemplate, Token: core.ParamAccessToken}
+	endpointAddWxaNewTemplate         = core.Endpoint[AddWxaNewTemplateRequest, AddWxaNewTemplateResponse]{Method: "POST", URL: URLAddWxaNewTemplate, Token: core.ParamAccessToken}
+	endpointDelWxaNewTemplate         = core.Endpoint[DelWxaNewTemplateRequest, DelWxaNewTemplateResponse]{Method: "POST", URL: URLDelWxaNewTemplate, Token: core.ParamAccessToken}
+	endpointSendNewSubscribeMsg       = core.Endpoint[*SendNewSubscribeMsgRequest, SendNewSubscribeMsgResponse]{Method: "POST", URL: URLSendNewSubscribeMsg, Token: core.ParamAccessToken}
+	endpointTemplateSubscribe         = core.Endpoint[*TemplateSubscribeRequest, TemplateSubscribeResponse]{Method: "POST", URL: URLTemplateSubscribe, Token: core.ParamAccessToken}
+	endpointGetCategory               = core.Endpoint[core.NoParams, GetCategoryResponse]{Method: "GET", URL: "https://api.weixin.qq.com/cgi-bin/template/get_category", Token: core.ParamAccessToken}
+	endpointGetPubNewTemplateTitles   = core.Endpoint[map[string]string, GetPubNewTemplateTitlesResponse]{Method: "GET", URL: "https://api.weixin.qq.com/cgi-bin/template/get_pub_template_titles", Token: core.ParamAccessToken}
+	endpointGetPubNewTemplateKeywords = core.Endpoint[map[string]string, GetPubNewTemplateKeywordsResponse]{Method: "GET", URL: URLGetPubTemplateKeywords, Token: core.ParamAccessToken}
+)
+
 // SubscribeClient 订阅通知客户端
 type SubscribeClient struct {
 	Client *Client
@@ -125,27 +136,7 @@ type SendNewSubscribeMsgResponse struct {
 // 功能：获取小程序账号的类目
 // 请求方式：GET
 func (c *SubscribeClient) GetCategory(ctx context.Context) (*GetCategoryResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetCategoryResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", "https://api.weixin.qq.com/cgi-bin/template/get_category", url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetCategory, core.NoParams{})
 }
 
 // GetPubNewTemplateTitles 获取公共模板标题列表
@@ -153,28 +144,12 @@ func (c *SubscribeClient) GetCategory(ctx context.Context) (*GetCategoryResponse
 // 功能：获取公共模板标题列表
 // 请求方式：GET
 func (c *SubscribeClient) GetPubNewTemplateTitles(ctx context.Context, categoryID int, start, limit int) (*GetPubNewTemplateTitlesResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetPubNewTemplateTitlesResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s&ids=%d&start=%d&limit=%d",
-		"https://api.weixin.qq.com/cgi-bin/template/get_pub_template_titles", url.QueryEscape(accessToken), categoryID, start, limit)
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
+	query := map[string]string{
+		"ids":   strconv.Itoa(categoryID),
+		"start": strconv.Itoa(start),
+		"limit": strconv.Itoa(limit),
 	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetPubNewTemplateTitles, query)
 }
 
 // GetPubNewTemplateKeywords 获取公共模板关键词列表
@@ -182,28 +157,7 @@ func (c *SubscribeClient) GetPubNewTemplateTitles(ctx context.Context, categoryI
 // 功能：获取模板标题下的关键词列表
 // 请求方式：GET
 func (c *SubscribeClient) GetPubNewTemplateKeywords(ctx context.Context, tid int) (*GetPubNewTemplateKeywordsResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetPubNewTemplateKeywordsResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s&tid=%d",
-		URLGetPubTemplateKeywords, url.QueryEscape(accessToken), tid)
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetPubNewTemplateKeywords, map[string]string{"tid": strconv.Itoa(tid)})
 }
 
 // GetWxaPubNewTemplate 获取小程序公共模板列表
@@ -211,27 +165,7 @@ func (c *SubscribeClient) GetPubNewTemplateKeywords(ctx context.Context, tid int
 // 功能：获取当前帐号下的个人模板列表
 // 请求方式：GET
 func (c *SubscribeClient) GetWxaPubNewTemplate(ctx context.Context) (*GetWxaPubNewTemplateResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetWxaPubNewTemplateResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetWxaPubTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetWxaPubNewTemplate, core.NoParams{})
 }
 
 // AddWxaNewTemplate 添加小程序模板
@@ -239,34 +173,13 @@ func (c *SubscribeClient) GetWxaPubNewTemplate(ctx context.Context) (*GetWxaPubN
 // 功能：组合模板并添加至帐号下的个人模板库
 // 请求方式：POST
 func (c *SubscribeClient) AddWxaNewTemplate(ctx context.Context, tid int, kidList []int, sceneDesc string) (*AddWxaNewTemplateResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddWxaNewTemplateRequest{
 		TID:       tid,
 		KidList:   kidList,
 		SceneDesc: sceneDesc,
 	}
 
-	var result AddWxaNewTemplateResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLAddWxaNewTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddWxaNewTemplate, request)
 }
 
 // DelWxaNewTemplate 删除小程序模板
@@ -274,32 +187,11 @@ func (c *SubscribeClient) AddWxaNewTemplate(ctx context.Context, tid int, kidLis
 // 功能：删除帐号下的个人模板
 // 请求方式：POST
 func (c *SubscribeClient) DelWxaNewTemplate(ctx context.Context, priTmplID string) (*DelWxaNewTemplateResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DelWxaNewTemplateRequest{
 		PriTmplID: priTmplID,
 	}
 
-	var result DelWxaNewTemplateResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLDelWxaNewTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDelWxaNewTemplate, request)
 }
 
 // SendNewSubscribeMsg 发送订阅消息
@@ -307,28 +199,7 @@ func (c *SubscribeClient) DelWxaNewTemplate(ctx context.Context, priTmplID strin
 // 功能：发送订阅消息
 // 请求方式：POST
 func (c *SubscribeClient) SendNewSubscribeMsg(ctx context.Context, request *SendNewSubscribeMsgRequest) (*SendNewSubscribeMsgResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result SendNewSubscribeMsgResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLSendNewSubscribeMsg, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointSendNewSubscribeMsg, request)
 }
 
 // TemplateSubscribeData 一次性订阅消息数据
@@ -363,26 +234,5 @@ type TemplateSubscribeResponse struct {
 // 请求方式：POST
 // 注意：此接口用于发送一次性订阅消息，用户需要先通过授权URL进行订阅授权
 func (c *SubscribeClient) TemplateSubscribe(ctx context.Context, request *TemplateSubscribeRequest) (*TemplateSubscribeResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result TemplateSubscribeResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateSubscribe, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointTemplateSubscribe, request)
 }
@@ -2,12 +2,20 @@ package official_account
 
 import (
 	"context"
-	"fmt"
-	"net/url"
 
 	"github.com/jcbowen/wego/core"
 )
 
+// 接口描述
+var (
+	endpointAddTemplate       = core.Endpoint[AddTemplateRequest, AddTemplateResponse]{Method: "POST", URL: URLTemplateAddTemplate, Token: core.ParamAccessToken}
+	endpointQueryBlockTmplMsg = core.Endpoint[QueryBlockTmplMsgRequest, QueryBlockTmplMsgResponse]{Method: "POST", URL: URLTemplateGetIndustry, Token: core.ParamAccessToken}
+	endpointDeleteTemplate    = core.Endpoint[DeleteTemplateRequest, DeleteTemplateResponse]{Method: "POST", URL: URLTemplateDelPrivateTemplate, Token: core.ParamAccessToken}
+	endpointGetAllTemplates   = core.Endpoint[core.NoParams, GetAllTemplatesResponse]{Method: "GET", URL: URLTemplateGetAllPrivateTemplate, Token: core.ParamAccessToken}
+	endpointGetIndustry       = core.Endpoint[core.NoParams, GetIndustryResponse]{Method: "GET", URL: URLTemplateGetIndustry, Token: core.ParamAccessToken}
+	endpointSetIndustry       = core.Endpoint[SetIndustryRequest, SetIndustryResponse]{Method: "POST", URL: URLTemplateSetIndustry, Token: core.ParamAccessToken}
+)
+
 // TemplateClient 模板消息客户端
 type TemplateClient struct {
 	Client *Client
@@ -121,41 +129,15 @@ func (c *TemplateClient) SendTemplateMessage(ctx context.Context, request *Templ
 
 // AddTemplate 选用模板
 func (c *TemplateClient) AddTemplate(ctx context.Context, templateIDShort string) (*AddTemplateResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddTemplateRequest{
 		TemplateIDShort: templateIDShort,
 	}
 
-	var result AddTemplateResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateAddTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointAddTemplate, request)
 }
 
 // QueryBlockTmplMsg 查询拦截的模板消息
 func (c *TemplateClient) QueryBlockTmplMsg(ctx context.Context, beginDate, endDate string, offset, count int) (*QueryBlockTmplMsgResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := QueryBlockTmplMsgRequest{
 		BeginDate: beginDate,
 		EndDate:   endDate,
@@ -163,132 +145,34 @@ func (c *TemplateClient) QueryBlockTmplMsg(ctx context.Context, beginDate, endDa
 		Count:     count,
 	}
 
-	var result QueryBlockTmplMsgResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateGetIndustry, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointQueryBlockTmplMsg, request)
 }
 
 // DeleteTemplate 删除模板
 func (c *TemplateClient) DeleteTemplate(ctx context.Context, templateID string) (*DeleteTemplateResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteTemplateRequest{
 		TemplateID: templateID,
 	}
 
-	var result DeleteTemplateResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateDelPrivateTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointDeleteTemplate, request)
 }
 
 // GetAllTemplates 获取已选用模板列表
 func (c *TemplateClient) GetAllTemplates(ctx context.Context) (*GetAllTemplatesResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetAllTemplatesResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateGetAllPrivateTemplate, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetAllTemplates, core.NoParams{})
 }
 
 // GetIndustry 获取行业信息
 func (c *TemplateClient) GetIndustry(ctx context.Context) (*GetIndustryResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetIndustryResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateGetIndustry, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointGetIndustry, core.NoParams{})
 }
 
 // SetIndustry 设置所属行业
 func (c *TemplateClient) SetIndustry(ctx context.Context, industryID1, industryID2 string) (*SetIndustryResponse, error) {
-	accessToken, err := c.Client.GetAccessToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	request := SetIndustryRequest{
 		IndustryID1: industryID1,
 		IndustryID2: industryID2,
 	}
 
-	var result SetIndustryResponse
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLTemplateSetIndustry, url.QueryEscape(accessToken))
-	err = c.Client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c.Client, endpointSetIndustry, request)
 }
@@ -0,0 +1,86 @@
+package official_account
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// SelfTokenProvider 由客户端调用 /cgi-bin/token 获取和刷新access_token，是未设置token来源时的默认行为
+type SelfTokenProvider struct {
+	client *Client
+}
+
+// NewSelfTokenProvider 创建由客户端自行刷新的token来源
+// @param client *Client 公众号客户端
+// @return *SelfTokenProvider token来源
+func NewSelfTokenProvider(client *Client) *SelfTokenProvider {
+	return &SelfTokenProvider{client: client}
+}
+
+// AccessToken 实现 core.TokenProvider 接口
+func (p *SelfTokenProvider) AccessToken(ctx context.Context, appID string) (string, error) {
+	if err := p.client.checkAppID(appID); err != nil {
+		return "", err
+	}
+	return p.client.getSelfAccessToken(ctx)
+}
+
+// RefreshAccessToken 实现 core.TokenProvider 接口
+func (p *SelfTokenProvider) RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error) {
+	if err := p.client.checkAppID(appID); err != nil {
+		return "", err
+	}
+	return p.client.refreshStaleSelfToken(ctx, staleToken)
+}
+
+// RefreshTargets 实现 core.RefreshSource 接口
+func (p *SelfTokenProvider) RefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	return p.client.selfRefreshTargets(ctx)
+}
+
+// StableTokenProvider 使用稳定版access_token，与配置 Config.UseStableToken 相同
+type StableTokenProvider struct {
+	client *StableTokenClient
+}
+
+// NewStableTokenProvider 创建使用稳定版access_token的token来源
+// @param client *Client 公众号客户端
+// @return *StableTokenProvider token来源
+func NewStableTokenProvider(client *Client) *StableTokenProvider {
+	return &StableTokenProvider{client: client.GetStableTokenClient()}
+}
+
+// AccessToken 实现 core.TokenProvider 接口
+func (p *StableTokenProvider) AccessToken(ctx context.Context, appID string) (string, error) {
+	if err := p.client.client.checkAppID(appID); err != nil {
+		return "", err
+	}
+	return p.client.GetStableAccessTokenWithAutoRefresh(ctx)
+}
+
+// RefreshAccessToken 实现 core.TokenProvider 接口
+func (p *StableTokenProvider) RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error) {
+	if err := p.client.client.checkAppID(appID); err != nil {
+		return "", err
+	}
+	return p.client.refreshStaleToken(ctx, staleToken)
+}
+
+// RefreshTargets 实现 core.RefreshSource 接口
+func (p *StableTokenProvider) RefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	target, err := p.client.RefreshTarget(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取稳定版access_token失败: %w", err)
+	}
+	return []core.RefreshTarget{target}, nil
+}
+
+// checkAppID 内置的token来源只能获取当前公众号的token
+func (c *Client) checkAppID(appID string) error {
+	if appID != c.config.AppID {
+		return fmt.Errorf("公众号客户端 %s 不能获取 %s 的access_token", c.config.AppID, appID)
+	}
+	return nil
+}
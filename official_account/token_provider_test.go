@@ -0,0 +1,62 @@
+package official_account_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestReadOnlyTokenProviderNeverRefreshes(t *testing.T) {
+	server := wegotest.NewServer().AddApp("wx_test", "secret")
+	defer server.Close()
+
+	// 中控服务获取一次token后一直下发该token
+	resp, err := http.Get(server.URL + "/cgi-bin/token?grant_type=client_credential&appid=wx_test&secret=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	central := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer central.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_test", AppSecret: "secret"},
+		store, server.Resolver(), core.NewHTTPTokenProvider(central.URL))
+	ctx := context.Background()
+
+	menus := official_account.NewMenuClient(client)
+	menu := &official_account.Menu{Button: []official_account.Button{{Type: "click", Name: "菜单", Key: "K1"}}}
+	if _, err := menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("create menu: %v", err)
+	}
+
+	// token失效后只重新拉取，不调用微信接口刷新
+	server.ExpireTokens()
+	if _, err := menus.GetMenu(ctx); !errors.Is(err, core.ErrTokenInvalid) {
+		t.Fatalf("expected ErrTokenInvalid, got %v", err)
+	}
+	if n := len(server.Requests("/cgi-bin/token")); n != 1 {
+		t.Fatalf("expected only the central service to request a token, got %d requests", n)
+	}
+	targets, err := client.RefreshTargets(ctx)
+	if err != nil || len(targets) != 0 {
+		t.Fatalf("expected no refresh targets, got %v, %v", targets, err)
+	}
+}
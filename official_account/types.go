@@ -2,6 +2,7 @@ package official_account
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jcbowen/wego/core"
@@ -32,6 +33,9 @@ type OAuthAccessTokenResponse struct {
 	OpenID       string `json:"openid"`        // 用户唯一标识，请注意，在未关注公众号时，用户访问公众号的网页，也会产生一个用户和公众号唯一的OpenID
 	Scope        string `json:"scope"`         // 用户授权的作用域，使用逗号（,）分隔
 	UnionID      string `json:"unionid"`       // 只有在用户将公众号绑定到微信开放平台帐号后，才会出现该字段
+
+	// ExpiresAt 按提前过期策略计算的截止时间，缓存网页授权token时以此判断是否需要刷新
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // OAuthRefreshTokenRequest 刷新网页授权access_token请求参数
@@ -104,8 +108,9 @@ type StableAccessTokenStorage interface {
 	GetStableAccessToken(appID string) (*StableAccessTokenInfo, error)
 }
 
-// DefaultStableAccessTokenStorage 默认稳定版access_token存储实现（基于内存）
+// DefaultStableAccessTokenStorage 默认稳定版access_token存储实现（基于内存，并发安全）
 type DefaultStableAccessTokenStorage struct {
+	mu     sync.RWMutex
 	tokens map[string]*StableAccessTokenInfo
 }
 
@@ -118,6 +123,8 @@ func NewDefaultStableAccessTokenStorage() *DefaultStableAccessTokenStorage {
 
 // SetStableAccessToken 设置稳定版access_token
 func (s *DefaultStableAccessTokenStorage) SetStableAccessToken(appID string, token *StableAccessTokenInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.tokens[appID] = token
 	return nil
 }
@@ -139,6 +146,8 @@ type ClearQuotaResponse struct {
 
 // GetStableAccessToken 获取稳定版access_token
 func (s *DefaultStableAccessTokenStorage) GetStableAccessToken(appID string) (*StableAccessTokenInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	token, exists := s.tokens[appID]
 	if !exists {
 		return nil, nil
@@ -146,6 +155,14 @@ func (s *DefaultStableAccessTokenStorage) GetStableAccessToken(appID string) (*S
 	return token, nil
 }
 
+// DeleteStableAccessToken 删除稳定版access_token
+func (s *DefaultStableAccessTokenStorage) DeleteStableAccessToken(appID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, appID)
+	return nil
+}
+
 // StableAccessTokenConfig 稳定版access_token配置
 type StableAccessTokenConfig struct {
 	AppID       string                   `json:"app_id" ini:"app_id"`             // 公众号appid
@@ -10,8 +10,6 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
-	"mime/multipart"
-	"net/http"
 	"net/url"
 	"sort"
 	"strings"
@@ -22,6 +20,20 @@ import (
 	"github.com/jcbowen/wego/official_account"
 )
 
+// 授权方接口描述
+var (
+	endpointAuthorizerCustomSend = core.Endpoint[map[string]interface{}, core.APIResponse]{Method: "POST", URL: official_account.URLMessageCustomSend, Token: core.ParamAccessToken}
+	endpointAuthorizerCreateMenu = core.Endpoint[*Menu, core.APIResponse]{Method: "POST", URL: official_account.URLCreateMenu, Token: core.ParamAccessToken}
+	endpointAuthorizerGetMenu    = core.Endpoint[core.NoParams, getMenuResponse]{Method: "GET", URL: official_account.URLGetMenu, Token: core.ParamAccessToken}
+	endpointAuthorizerDeleteMenu = core.Endpoint[core.NoParams, core.APIResponse]{Method: "GET", URL: official_account.URLDeleteMenu, Token: core.ParamAccessToken}
+	endpointAuthorizerUserInfo   = core.Endpoint[userInfoRequest, UserInfo]{Method: "GET", URL: official_account.URLUserInfo, Token: core.ParamAccessToken}
+	endpointAuthorizerUserList   = core.Endpoint[userListRequest, UserList]{Method: "GET", URL: official_account.URLGetUserList, Token: core.ParamAccessToken}
+	endpointAuthorizerUpload     = core.Endpoint[*core.Multipart, MediaResponse]{Method: "POST", URL: official_account.URLUploadMaterial, Token: core.ParamAccessToken}
+	endpointGetWXACode           = core.Endpoint[*WXACodeRequest, WXACodeResponse]{Method: "POST", URL: URLGetWxaCode, Token: core.ParamAccessToken}
+	endpointAuthorizerGetTicket  = core.Endpoint[ticketRequest, ticketResponse]{Method: "GET", URL: official_account.URLGetTicket, Token: core.ParamAccessToken}
+	endpointAuthorizerGetMedia   = core.Endpoint[official_account.GetMediaRequest, core.Download]{Method: "GET", URL: official_account.URLGetMaterial, Token: core.ParamAccessToken}
+)
+
 // AuthClient 授权相关客户端
 type AuthClient struct {
 	client *Client
@@ -48,6 +60,25 @@ func (c *AuthClient) NewAuthorizerClient(authorizerAppID string) *AuthorizerClie
 	}
 }
 
+// Token 实现 core.Caller 接口
+// access_token返回授权方的access_token，component_access_token由第三方平台客户端提供
+func (c *AuthorizerClient) Token(ctx context.Context, param string) (string, error) {
+	if param == core.ParamAccessToken {
+		return c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
+	}
+	return c.authClient.client.Token(ctx, param)
+}
+
+// Make 实现 core.Caller 接口，使用第三方平台客户端的请求管道发送请求
+func (c *AuthorizerClient) Make(ctx context.Context, options *core.ReqMakeOpt) error {
+	return c.authClient.client.Make(ctx, options)
+}
+
+// Download 实现 core.Downloader 接口，使用第三方平台客户端的请求管道下载
+func (c *AuthorizerClient) Download(ctx context.Context, options *core.ReqMakeOpt) (*core.Download, error) {
+	return c.authClient.client.Download(ctx, options)
+}
+
 // TextMessage 文本消息
 type TextMessage struct {
 	Content string `json:"content"`
@@ -94,13 +125,6 @@ type Article struct {
 
 // SendCustomMessage 发送客服消息
 func (c *AuthorizerClient) SendCustomMessage(ctx context.Context, toUser string, message interface{}) error {
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", official_account.URLMessageCustomSend, url.QueryEscape(accessToken))
-
 	request := map[string]interface{}{
 		"touser":  toUser,
 		"msgtype": c.getMessageType(message),
@@ -116,22 +140,8 @@ func (c *AuthorizerClient) SendCustomMessage(ctx context.Context, toUser string,
 		return fmt.Errorf("不支持的客服消息类型")
 	}
 
-	var result core.APIResponse
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return err
-	}
-
-	if !result.IsSuccess() {
-		return &result
-	}
-
-	return nil
+	_, err := core.Call(ctx, c, endpointAuthorizerCustomSend, request)
+	return err
 }
 
 // getMessageType 获取消息类型
@@ -168,160 +178,57 @@ type Button struct {
 	SubButton []Button `json:"sub_button,omitempty"`
 }
 
+// getMenuResponse 获取菜单响应
+type getMenuResponse struct {
+	core.APIResponse
+	Menu Menu `json:"menu"`
+}
+
 // CreateMenu 创建自定义菜单
 func (c *AuthorizerClient) CreateMenu(ctx context.Context, menu *Menu) error {
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", official_account.URLCreateMenu, url.QueryEscape(accessToken))
-
-	var result core.APIResponse
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   menu,
-		Result: &result,
-	})
-	if err != nil {
-		return err
-	}
-
-	if !result.IsSuccess() {
-		return &result
-	}
-
-	return nil
+	_, err := core.Call(ctx, c, endpointAuthorizerCreateMenu, menu)
+	return err
 }
 
 // GetMenu 获取菜单
 func (c *AuthorizerClient) GetMenu(ctx context.Context) (*Menu, error) {
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", official_account.URLGetMenu, url.QueryEscape(accessToken))
-
-	var result struct {
-		core.APIResponse
-		Menu Menu `json:"menu"`
-	}
-
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
+	result, err := core.Call(ctx, c, endpointAuthorizerGetMenu, core.NoParams{})
 	if err != nil {
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	return &result.Menu, nil
 }
 
 // DeleteMenu 删除自定义菜单
 func (c *AuthorizerClient) DeleteMenu(ctx context.Context) error {
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", official_account.URLDeleteMenu, url.QueryEscape(accessToken))
-
-	var result core.APIResponse
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return err
-	}
-
-	if !result.IsSuccess() {
-		return &result
-	}
-
-	return nil
+	_, err := core.Call(ctx, c, endpointAuthorizerDeleteMenu, core.NoParams{})
+	return err
 }
 
 // CallAPI 代调用API（支持context）
+// apiURL 可以已携带查询参数，access_token 会自动追加；微信返回非0错误码时返回 *core.Error
+// 有类型的请求和响应可以直接使用 core.Call，AuthorizerClient 实现了 core.Caller 接口
 func (c *AuthorizerClient) CallAPI(ctx context.Context, apiURL string, params interface{}) ([]byte, error) {
-	// 1. 获取授权方AccessToken
-	token, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, fmt.Errorf("获取AccessToken失败: %v", err)
-	}
-
-	// 2. 构造完整URL
-	fullURL := fmt.Sprintf("%s?access_token=%s", apiURL, token)
-
-	// 3. 创建HTTP请求
-	var req *http.Request
+	// 根据是否有参数决定请求方法
+	method := "GET"
 	if params != nil {
-		// POST请求
-		jsonData, err := json.Marshal(params)
-		if err != nil {
-			return nil, fmt.Errorf("参数序列化失败: %v", err)
-		}
-
-		req, err = http.NewRequestWithContext(ctx, "POST", fullURL, strings.NewReader(string(jsonData)))
-		if err != nil {
-			return nil, fmt.Errorf("创建请求失败: %v", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-	} else {
-		// GET请求
-		req, err = http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("创建请求失败: %v", err)
-		}
-	}
-
-	// 4. 发送HTTP请求
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("API调用失败: %v", err)
+		method = "POST"
 	}
-	defer resp.Body.Close()
 
-	// 5. 读取响应
-	respBody, err := io.ReadAll(resp.Body)
+	endpoint := core.Endpoint[interface{}, json.RawMessage]{Method: method, URL: apiURL, Token: core.ParamAccessToken}
+	respBody, err := core.Call(ctx, c, endpoint, params)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	// 6. 解析响应并统一返回错误类型
-	var apiResp core.APIResponse
-	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return nil, fmt.Errorf("响应解析失败: %v", err)
-	}
-
-	// 7. 检查错误码
-	if !apiResp.IsSuccess() {
-		return nil, fmt.Errorf("API返回错误: %d - %s", apiResp.ErrCode, apiResp.ErrMsg)
+		return nil, err
 	}
 
-	return respBody, nil
+	return *respBody, nil
 }
 
 // CallAPIWithQuery 支持查询参数的API调用
 func (c *AuthorizerClient) CallAPIWithQuery(ctx context.Context, baseURL string, queryParams map[string]string, postData interface{}) ([]byte, error) {
-	// 获取授权方AccessToken
-	token, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, fmt.Errorf("获取AccessToken失败: %v", err)
-	}
-
-	// 构造查询参数
+	// 构造查询参数，access_token由CallAPI追加
 	params := url.Values{}
-	params.Set("access_token", token)
 	for key, value := range queryParams {
 		params.Set(key, value)
 	}
@@ -396,25 +303,15 @@ func (c *AuthorizerClient) DeleteConditionalMenu(ctx context.Context, menuID str
 }
 
 // CallAPIWithRetry 带重试的API调用
+// 基于客户端设置的重试策略（未设置时使用 core.DefaultRetryPolicy），最多尝试maxRetries次
+// 仅在系统繁忙，或无请求体（GET）时遇到网络错误、5xx响应才重试；token失效由请求层自动刷新
 func (c *AuthorizerClient) CallAPIWithRetry(ctx context.Context, apiURL string, params interface{}, maxRetries int) ([]byte, error) {
-	for i := 0; i < maxRetries; i++ {
-		resp, err := c.CallAPI(ctx, apiURL, params)
-		if err == nil {
-			return resp, nil
-		}
-
-		// 检查是否是Token过期错误
-		if strings.Contains(err.Error(), "40001") || strings.Contains(err.Error(), "42001") {
-			// Token过期，清除缓存并重试
-			// 这里需要实现缓存清除逻辑
-			continue
-		}
-
-		// 其他错误，直接返回
-		return nil, err
+	if maxRetries <= 0 {
+		return nil, fmt.Errorf("重试次数必须大于0")
 	}
 
-	return nil, fmt.Errorf("API调用重试%d次后失败", maxRetries)
+	policy := c.authClient.client.retryPolicyOrDefault().WithMaxAttempts(maxRetries)
+	return c.CallAPI(core.WithRetryPolicy(ctx, policy), apiURL, params)
 }
 
 // JSSDKCacher JS-SDK配置缓存器
@@ -454,7 +351,7 @@ func (c *JSSDKCacher) generateCacheKey(url string, jsAPIList []string) string {
 }
 
 // GetConfigOptimized 优化后的JS-SDK配置获取
-func (jm *JSSDKManager) GetConfigOptimized(url string, jsAPIList []string) (*JSSDKConfig, error) {
+func (jm *JSSDKManager) GetConfigOptimized(ctx context.Context, url string, jsAPIList []string) (*JSSDKConfig, error) {
 	// 验证参数
 	if url == "" {
 		return nil, fmt.Errorf("URL不能为空")
@@ -472,15 +369,9 @@ func (jm *JSSDKManager) GetConfigOptimized(url string, jsAPIList []string) (*JSS
 	}
 
 	// 缓存未命中，重新生成配置
-	ctx := context.Background()
-	accessToken, err := jm.authorizerClient.authClient.client.GetAuthorizerAccessToken(ctx, jm.authorizerClient.authorizerAppID)
-	if err != nil {
-		return nil, fmt.Errorf("获取AccessToken失败: %v", err)
-	}
-
-	ticket, err := jm.getJSAPITicket(ctx, accessToken)
+	ticket, err := jm.authorizerClient.GetTicket(ctx, TicketTypeJSAPI)
 	if err != nil {
-		return nil, fmt.Errorf("获取JSAPI Ticket失败: %v", err)
+		return nil, fmt.Errorf("获取JSAPI Ticket失败: %w", err)
 	}
 
 	config := jm.generateSignature(url, ticket, jsAPIList)
@@ -492,7 +383,8 @@ func (jm *JSSDKManager) GetConfigOptimized(url string, jsAPIList []string) (*JSS
 }
 
 // GetConfigWithRetry 带重试的JS-SDK配置获取
-func (jm *JSSDKManager) GetConfigWithRetry(url string, jsAPIList []string, maxRetries int) (*JSSDKConfig, error) {
+// 基于客户端设置的重试策略（未设置时使用 core.DefaultRetryPolicy），获取ticket最多尝试maxRetries次
+func (jm *JSSDKManager) GetConfigWithRetry(ctx context.Context, url string, jsAPIList []string, maxRetries int) (*JSSDKConfig, error) {
 	// 验证参数
 	if url == "" {
 		return nil, fmt.Errorf("URL不能为空")
@@ -507,19 +399,13 @@ func (jm *JSSDKManager) GetConfigWithRetry(url string, jsAPIList []string, maxRe
 		return nil, fmt.Errorf("重试次数必须大于0")
 	}
 
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		config, err := jm.GetConfigOptimized(url, jsAPIList)
-		if err == nil {
-			return config, nil
-		}
-
-		lastErr = err
-		time.Sleep(time.Duration(i+1) * time.Second) // 指数退避
+	// 获取ticket是幂等的GET请求，由请求层按重试策略退避重试
+	policy := jm.authorizerClient.authClient.client.retryPolicyOrDefault().WithMaxAttempts(maxRetries)
+	config, err := jm.GetConfigOptimized(core.WithRetryPolicy(ctx, policy), url, jsAPIList)
+	if err != nil {
+		return nil, fmt.Errorf("获取JS-SDK配置失败: %w", err)
 	}
-
-	return nil, fmt.Errorf("获取JS-SDK配置失败，重试%d次后仍然失败: %v", maxRetries, lastErr)
+	return config, nil
 }
 
 // validateURL 验证URL格式
@@ -557,6 +443,12 @@ type UserInfo struct {
 	QRSceneStr     string `json:"qr_scene_str"`
 }
 
+// userInfoRequest 获取用户信息请求参数
+type userInfoRequest struct {
+	OpenID string `json:"openid"`
+	Lang   string `json:"lang"`
+}
+
 // GetUserInfo 获取用户信息
 func (c *AuthorizerClient) GetUserInfo(ctx context.Context, openID string) (*UserInfo, error) {
 	// 验证参数
@@ -567,29 +459,7 @@ func (c *AuthorizerClient) GetUserInfo(ctx context.Context, openID string) (*Use
 		return nil, fmt.Errorf("授权方AppID不能为空")
 	}
 
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s&openid=%s&lang=zh_CN",
-		official_account.URLUserInfo, url.QueryEscape(accessToken), url.QueryEscape(openID))
-
-	var result UserInfo
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.ErrCode != 0 {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointAuthorizerUserInfo, userInfoRequest{OpenID: openID, Lang: "zh_CN"})
 }
 
 // UserList 用户列表
@@ -603,33 +473,14 @@ type UserList struct {
 	NextOpenID string `json:"next_openid"`
 }
 
+// userListRequest 获取用户列表请求参数
+type userListRequest struct {
+	NextOpenID string `json:"next_openid,omitempty"`
+}
+
 // GetUserList 获取用户列表
 func (c *AuthorizerClient) GetUserList(ctx context.Context, nextOpenID string) (*UserList, error) {
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", official_account.URLGetUserList, url.QueryEscape(accessToken))
-	if nextOpenID != "" {
-		apiURL += "&next_openid=" + url.QueryEscape(nextOpenID)
-	}
-
-	var result UserList
-	err = c.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.ErrCode != 0 {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointAuthorizerUserList, userListRequest{NextOpenID: nextOpenID})
 }
 
 // SendTemplateMessage 发送模板消息
@@ -656,6 +507,15 @@ type MediaResponse struct {
 
 // UploadMedia 上传临时素材
 func (c *AuthorizerClient) UploadMedia(ctx context.Context, mediaType, filename string, data []byte) (*MediaResponse, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("媒体数据不能为空")
+	}
+	return c.UploadMediaFromReader(ctx, mediaType, filename, bytes.NewReader(data))
+}
+
+// UploadMediaFromReader 从io.Reader上传临时素材
+// 文件内容以流的方式发送，reader实现 io.Seeker 时token失效后可自动重试
+func (c *AuthorizerClient) UploadMediaFromReader(ctx context.Context, mediaType, filename string, reader io.Reader) (*MediaResponse, error) {
 	// 验证参数
 	if mediaType == "" {
 		return nil, fmt.Errorf("媒体类型不能为空")
@@ -663,76 +523,38 @@ func (c *AuthorizerClient) UploadMedia(ctx context.Context, mediaType, filename
 	if filename == "" {
 		return nil, fmt.Errorf("文件名不能为空")
 	}
-	if len(data) == 0 {
+	if reader == nil {
 		return nil, fmt.Errorf("媒体数据不能为空")
 	}
 	if c.authorizerAppID == "" {
 		return nil, fmt.Errorf("授权方AppID不能为空")
 	}
 
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s&type=%s",
-		official_account.URLUploadMaterial, url.QueryEscape(accessToken), url.QueryEscape(mediaType))
-
-	// 创建multipart/form-data请求
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// 创建文件字段
-	part, err := writer.CreateFormFile("media", filename)
-	if err != nil {
-		return nil, fmt.Errorf("创建文件字段失败: %v", err)
-	}
-
-	// 写入文件数据
-	if _, err := part.Write(data); err != nil {
-		return nil, fmt.Errorf("写入文件数据失败: %v", err)
-	}
-
-	// 关闭writer
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("关闭multipart writer失败: %v", err)
-	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	endpoint := endpointAuthorizerUpload.WithQuery(map[string]string{"type": mediaType})
+	return core.Call(ctx, c, endpoint, &core.Multipart{
+		Files: []core.MultipartFile{{FieldName: "media", FileName: filename, Reader: reader}},
+	})
+}
 
-	// 发送请求
-	resp, err := c.authClient.client.req.MakeRaw(req)
+// GetMedia 获取临时素材
+func (c *AuthorizerClient) GetMedia(ctx context.Context, mediaID string) ([]byte, error) {
+	download, err := c.DownloadMedia(ctx, mediaID)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer download.Close()
 
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
+	mediaData, err := io.ReadAll(download)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+		return nil, fmt.Errorf("读取媒体文件失败: %w", err)
 	}
-
-	var result MediaResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return mediaData, nil
 }
 
-// GetMedia 获取临时素材
-func (c *AuthorizerClient) GetMedia(ctx context.Context, mediaID string) ([]byte, error) {
+// DownloadMedia 以流的方式下载临时素材
+// 微信返回JSON错误时返回 *core.Error，视频素材自动跟随返回的 video_url 下载
+// 注意：返回的 *core.Download 使用完毕后必须关闭
+func (c *AuthorizerClient) DownloadMedia(ctx context.Context, mediaID string) (*core.Download, error) {
 	// 验证参数
 	if mediaID == "" {
 		return nil, fmt.Errorf("媒体ID不能为空")
@@ -741,53 +563,7 @@ func (c *AuthorizerClient) GetMedia(ctx context.Context, mediaID string) ([]byte
 		return nil, fmt.Errorf("授权方AppID不能为空")
 	}
 
-	accessToken, err := c.authClient.client.GetAuthorizerAccessToken(ctx, c.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s&media_id=%s",
-		official_account.URLGetMaterial, url.QueryEscape(accessToken), url.QueryEscape(mediaID))
-
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
-	}
-
-	// 发送请求
-	resp, err := c.authClient.client.req.MakeRaw(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 检查响应类型
-	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") {
-		// 如果是JSON响应，说明有错误
-		respBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("读取响应失败: %v", err)
-		}
-
-		var result core.APIResponse
-		if err := json.Unmarshal(respBody, &result); err != nil {
-			return nil, fmt.Errorf("解析响应失败: %v", err)
-		}
-
-		if !result.IsSuccess() {
-			return nil, &result
-		}
-	}
-
-	// 读取媒体文件数据
-	mediaData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取媒体文件失败: %v", err)
-	}
-
-	return mediaData, nil
+	return core.CallDownload(ctx, c, endpointAuthorizerGetMedia, official_account.GetMediaRequest{MediaID: mediaID})
 }
 
 // OAuthClient 网页授权客户端
@@ -871,6 +647,9 @@ type OAuthToken struct {
 	RefreshToken string `json:"refresh_token"`
 	OpenID       string `json:"openid"`
 	Scope        string `json:"scope"`
+
+	// ExpiresAt 按提前过期策略计算的截止时间，缓存网页授权token时以此判断是否需要刷新
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // GetAccessToken 使用授权码获取AccessToken
@@ -892,7 +671,7 @@ func (oc *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthT
 	componentToken, err := oc.authorizerClient.authClient.client.GetComponentToken(ctx)
 	if err != nil {
 		oc.authorizerClient.authClient.client.logger.Error(fmt.Sprintf("获取ComponentAccessToken失败: %v", err))
-		return nil, fmt.Errorf("获取ComponentAccessToken失败: %v", err)
+		return nil, fmt.Errorf("获取ComponentAccessToken失败: %w", err)
 	}
 
 	// 检查组件令牌是否为空
@@ -901,7 +680,7 @@ func (oc *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthT
 		return nil, fmt.Errorf("ComponentAccessToken为空，请先获取有效的组件令牌")
 	}
 
-	oc.authorizerClient.authClient.client.logger.Info(fmt.Sprintf("获取到ComponentAccessToken: %s", componentToken.AccessToken))
+	oc.authorizerClient.authClient.client.logger.Info("获取到ComponentAccessToken")
 
 	// 严格按照微信官方文档要求的参数格式
 	params := map[string]string{
@@ -922,6 +701,7 @@ func (oc *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthT
 	})
 
 	var oauthToken OAuthToken
+	start := time.Now()
 	err = oc.authorizerClient.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
 		Method: "GET",
 		URL:    URLComponentOAuth2AccessToken,
@@ -931,10 +711,7 @@ func (oc *OAuthClient) GetAccessToken(ctx context.Context, code string) (*OAuthT
 	if err != nil {
 		return nil, err
 	}
-
-	if !oauthToken.IsSuccess() {
-		return nil, &oauthToken.APIResponse
-	}
+	oauthToken.ExpiresAt = oc.authorizerClient.authClient.client.expiresAt(start, int(oauthToken.ExpiresIn))
 
 	return &oauthToken, nil
 }
@@ -975,16 +752,10 @@ func (jm *JSSDKManager) GetConfig(ctx context.Context, url string, jsAPIList []s
 		return nil, fmt.Errorf("授权方AppID不能为空")
 	}
 
-	// 获取授权方AccessToken
-	accessToken, err := jm.authorizerClient.authClient.client.GetAuthorizerAccessToken(ctx, jm.authorizerClient.authorizerAppID)
-	if err != nil {
-		return nil, fmt.Errorf("获取AccessToken失败: %v", err)
-	}
-
 	// 获取JSAPI Ticket
-	ticket, err := jm.getJSAPITicket(ctx, accessToken)
+	ticket, err := jm.authorizerClient.GetTicket(ctx, TicketTypeJSAPI)
 	if err != nil {
-		return nil, fmt.Errorf("获取JSAPI Ticket失败: %v", err)
+		return nil, fmt.Errorf("获取JSAPI Ticket失败: %w", err)
 	}
 
 	// 生成签名
@@ -993,37 +764,171 @@ func (jm *JSSDKManager) GetConfig(ctx context.Context, url string, jsAPIList []s
 	return config, nil
 }
 
-// getJSAPITicket 获取JSAPI Ticket
-func (jm *JSSDKManager) getJSAPITicket(ctx context.Context, accessToken string) (string, error) {
-	apiURL := official_account.URLGetTicket
+// ticketRequest 获取ticket请求参数
+type ticketRequest struct {
+	Type string `json:"type"`
+}
 
-	params := map[string]interface{}{
-		"access_token": accessToken,
-		"type":         "jsapi",
+// ticketResponse 获取ticket响应
+type ticketResponse struct {
+	core.APIResponse
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// authorizerTicket 缓存的授权方ticket
+type authorizerTicket struct {
+	authorizerAppID string
+	ticketType      string
+	kind            string // token类型，如 core.TokenKindJSAPITicket
+	ticket          string
+	expiresAt       time.Time
+}
+
+// ticketKind 返回ticket类型对应的token类型
+func ticketKind(ticketType string) (string, error) {
+	switch ticketType {
+	case TicketTypeJSAPI:
+		return core.TokenKindJSAPITicket, nil
+	case TicketTypeWxCard:
+		return core.TokenKindCardTicket, nil
+	}
+	return "", fmt.Errorf("不支持的ticket类型: %s", ticketType)
+}
+
+// GetTicket 获取授权方的ticket，缓存到过期前，同一授权方并发的获取合并为一次请求
+// 存储中授权方的token已删除（如其他实例处理了取消授权）时清除缓存的ticket并返回错误
+// @param ctx context.Context 上下文
+// @param ticketType string ticket类型，TicketTypeJSAPI 或 TicketTypeWxCard
+// @return string ticket
+// @return error 错误信息
+func (c *AuthorizerClient) GetTicket(ctx context.Context, ticketType string) (string, error) {
+	if c.authorizerAppID == "" {
+		return "", fmt.Errorf("授权方AppID不能为空")
 	}
 
-	var result struct {
-		ErrCode   int    `json:"errcode"`
-		ErrMsg    string `json:"errmsg"`
-		Ticket    string `json:"ticket"`
-		ExpiresIn int    `json:"expires_in"`
+	client := c.authClient.client
+	ok, err := client.hasAuthorizerToken(ctx, c.authorizerAppID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		client.dropTickets(c.authorizerAppID)
+		return "", fmt.Errorf("授权方 %s 的token不存在，可能已取消授权", c.authorizerAppID)
 	}
 
-	err := jm.authorizerClient.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    apiURL,
-		Query:  params,
-		Result: &result,
-	})
+	if ticket, ok := client.cachedTicket(c.authorizerAppID, ticketType, time.Now()); ok {
+		return ticket, nil
+	}
+	return c.refreshTicket(ctx, ticketType, time.Now())
+}
+
+// RefreshTicket 清除失效的ticket并重新获取
+// 缓存的ticket已被其他请求刷新、不再是staleTicket时直接返回
+// @param ctx context.Context 上下文
+// @param ticketType string ticket类型，TicketTypeJSAPI 或 TicketTypeWxCard
+// @param staleTicket string 失效的ticket
+// @return string 新的ticket
+// @return error 错误信息
+func (c *AuthorizerClient) RefreshTicket(ctx context.Context, ticketType, staleTicket string) (string, error) {
+	if c.authorizerAppID == "" {
+		return "", fmt.Errorf("授权方AppID不能为空")
+	}
+	c.authClient.client.dropTicket(c.authorizerAppID, ticketType, staleTicket)
+	return c.refreshTicket(ctx, ticketType, time.Now())
+}
+
+// refreshTicket 从微信获取ticket并缓存
+// @param before time.Time 缓存的ticket在该时间之后才过期时直接返回，不调用微信接口
+func (c *AuthorizerClient) refreshTicket(ctx context.Context, ticketType string, before time.Time) (string, error) {
+	kind, err := ticketKind(ticketType)
 	if err != nil {
 		return "", err
 	}
 
-	if result.ErrCode != 0 {
-		return "", fmt.Errorf("获取JSAPI Ticket失败: %s", result.ErrMsg)
+	client := c.authClient.client
+	return core.RefreshOnce(ctx, &client.refreshGroup, core.RefreshKey(kind, c.authorizerAppID), func(ctx context.Context) (string, error) {
+		if ticket, ok := client.cachedTicket(c.authorizerAppID, ticketType, before); ok {
+			return ticket, nil
+		}
+
+		var result *ticketResponse
+		start := time.Now()
+		err := client.refreshToken(ctx, kind, func(ctx context.Context) (err error) {
+			result, err = core.Call(ctx, c, endpointAuthorizerGetTicket, ticketRequest{Type: ticketType})
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+		client.storeTicket(authorizerTicket{
+			authorizerAppID: c.authorizerAppID,
+			ticketType:      ticketType,
+			kind:            kind,
+			ticket:          result.Ticket,
+			expiresAt:       client.expiresAt(start, result.ExpiresIn),
+		})
+		return result.Ticket, nil
+	})
+}
+
+// cachedTicket 返回在before之后才过期的缓存ticket
+func (c *Client) cachedTicket(authorizerAppID, ticketType string, before time.Time) (string, bool) {
+	c.ticketMu.Lock()
+	defer c.ticketMu.Unlock()
+
+	ticket, ok := c.tickets[core.RefreshKey(ticketType, authorizerAppID)]
+	if !ok || !ticket.expiresAt.After(before) {
+		return "", false
+	}
+	return ticket.ticket, true
+}
+
+// storeTicket 缓存ticket
+func (c *Client) storeTicket(ticket authorizerTicket) {
+	c.ticketMu.Lock()
+	defer c.ticketMu.Unlock()
+
+	if c.tickets == nil {
+		c.tickets = make(map[string]authorizerTicket)
 	}
+	c.tickets[core.RefreshKey(ticket.ticketType, ticket.authorizerAppID)] = ticket
+}
+
+// dropTicket 缓存的ticket是staleTicket时清除
+func (c *Client) dropTicket(authorizerAppID, ticketType, staleTicket string) {
+	c.ticketMu.Lock()
+	defer c.ticketMu.Unlock()
 
-	return result.Ticket, nil
+	key := core.RefreshKey(ticketType, authorizerAppID)
+	if ticket, ok := c.tickets[key]; ok && ticket.ticket == staleTicket {
+		delete(c.tickets, key)
+	}
+}
+
+// dropTickets 清除授权方缓存的所有ticket
+func (c *Client) dropTickets(authorizerAppID string) {
+	c.ticketMu.Lock()
+	defer c.ticketMu.Unlock()
+
+	for key, ticket := range c.tickets {
+		if ticket.authorizerAppID == authorizerAppID {
+			delete(c.tickets, key)
+		}
+	}
+}
+
+// cachedTickets 返回所有缓存的ticket
+func (c *Client) cachedTickets() []authorizerTicket {
+	c.ticketMu.Lock()
+	defer c.ticketMu.Unlock()
+
+	tickets := make([]authorizerTicket, 0, len(c.tickets))
+	for _, ticket := range c.tickets {
+		tickets = append(tickets, ticket)
+	}
+	return tickets
 }
 
 // generateSignature 生成签名
@@ -1114,29 +1019,7 @@ func (mpc *MiniProgramClient) GetWXACode(ctx context.Context, request *WXACodeRe
 		return nil, fmt.Errorf("授权方AppID不能为空")
 	}
 
-	accessToken, err := mpc.authorizerClient.authClient.client.GetAuthorizerAccessToken(ctx, mpc.authorizerClient.authorizerAppID)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL := fmt.Sprintf("%s?access_token=%s", URLGetWxaCode, url.QueryEscape(accessToken))
-
-	var result WXACodeResponse
-	err = mpc.authorizerClient.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, mpc.authorizerClient, endpointGetWXACode, request)
 }
 
 // GetUserInfo 获取用户信息
@@ -1170,10 +1053,6 @@ func (oc *OAuthClient) GetUserInfo(ctx context.Context, accessToken, openID stri
 		return nil, err
 	}
 
-	if !userInfo.IsSuccess() {
-		return nil, &userInfo.APIResponse
-	}
-
 	return &userInfo, nil
 }
 
@@ -1190,7 +1069,7 @@ func (oc *OAuthClient) RefreshToken(ctx context.Context, refreshToken string) (*
 	// 需要先获取组件令牌
 	componentToken, err := oc.authorizerClient.authClient.client.GetComponentToken(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("获取ComponentAccessToken失败: %v", err)
+		return nil, fmt.Errorf("获取ComponentAccessToken失败: %w", err)
 	}
 
 	// 检查组件令牌是否为空
@@ -1199,7 +1078,7 @@ func (oc *OAuthClient) RefreshToken(ctx context.Context, refreshToken string) (*
 		return nil, fmt.Errorf("ComponentAccessToken为空，请先获取有效的组件令牌")
 	}
 
-	oc.authorizerClient.authClient.client.logger.Info(fmt.Sprintf("获取到ComponentAccessToken: %s", componentToken.AccessToken))
+	oc.authorizerClient.authClient.client.logger.Info("获取到ComponentAccessToken")
 
 	// 使用微信官方文档指定的URL
 	apiURL := URLRefreshComponentAccessToken
@@ -1214,6 +1093,7 @@ func (oc *OAuthClient) RefreshToken(ctx context.Context, refreshToken string) (*
 	}
 
 	var oauthToken OAuthToken
+	start := time.Now()
 	err = oc.authorizerClient.authClient.client.req.Make(ctx, &core.ReqMakeOpt{
 		Method: "GET",
 		URL:    apiURL,
@@ -1223,10 +1103,7 @@ func (oc *OAuthClient) RefreshToken(ctx context.Context, refreshToken string) (*
 	if err != nil {
 		return nil, err
 	}
-
-	if !oauthToken.IsSuccess() {
-		return nil, &oauthToken.APIResponse
-	}
+	oauthToken.ExpiresAt = oc.authorizerClient.authClient.client.expiresAt(start, int(oauthToken.ExpiresIn))
 
 	return &oauthToken, nil
 }
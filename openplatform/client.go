@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/jcbowen/jcbaseGo/component/debugger"
@@ -18,6 +19,23 @@ import (
 	"github.com/jcbowen/wego/storage"
 )
 
+// 第三方平台接口描述
+var (
+	endpointQueryAuth            = core.Endpoint[QueryAuthRequest, QueryAuthResponse]{Method: "POST", URL: URLQueryAuth, Token: core.ParamComponentAccessToken}
+	endpointAuthorizerToken      = core.Endpoint[AuthorizerTokenRequest, authorizerTokenResponse]{Method: "POST", URL: URLAuthorizerToken, Token: core.ParamComponentAccessToken}
+	endpointGetAuthorizerInfo    = core.Endpoint[GetAuthorizerInfoRequest, GetAuthorizerInfoResponse]{Method: "POST", URL: URLGetAuthorizerInfo, Token: core.ParamComponentAccessToken}
+	endpointGetAuthorizerList    = core.Endpoint[GetAuthorizerListRequest, GetAuthorizerListResponse]{Method: "POST", URL: URLGetAuthorizerList, Token: core.ParamComponentAccessToken}
+	endpointClearQuota           = core.Endpoint[ClearQuotaRequest, core.APIResponse]{Method: "POST", URL: URLClearQuota, Token: core.ParamAccessToken}
+	endpointGetApiQuota          = core.Endpoint[GetApiQuotaRequest, GetApiQuotaResponse]{Method: "POST", URL: URLGetApiQuota, Token: core.ParamAccessToken}
+	endpointGetRidInfo           = core.Endpoint[GetRidInfoRequest, GetRidInfoResponse]{Method: "POST", URL: URLGetRidInfo, Token: core.ParamAccessToken}
+	endpointSetAuthorizerOption  = core.Endpoint[SetAuthorizerOptionRequest, core.APIResponse]{Method: "POST", URL: URLSetAuthorizerOption, Token: core.ParamComponentAccessToken}
+	endpointGetAuthorizerOption  = core.Endpoint[GetAuthorizerOptionRequest, GetAuthorizerOptionResponse]{Method: "POST", URL: URLGetAuthorizerOption, Token: core.ParamComponentAccessToken}
+	endpointGetTemplateDraftList = core.Endpoint[core.NoParams, GetTemplateDraftListResponse]{Method: "GET", URL: URLWxaGetTemplateDraftList, Token: core.ParamAccessToken}
+	endpointAddToTemplate        = core.Endpoint[AddToTemplateRequest, core.APIResponse]{Method: "POST", URL: URLWxaAddToTemplate, Token: core.ParamAccessToken}
+	endpointGetTemplateList      = core.Endpoint[core.NoParams, GetTemplateListResponse]{Method: "GET", URL: URLWxaGetTemplateList, Token: core.ParamAccessToken}
+	endpointDeleteTemplate       = core.Endpoint[DeleteTemplateRequest, core.APIResponse]{Method: "POST", URL: URLWxaDeleteTemplate, Token: core.ParamAccessToken}
+)
+
 // Client API客户端
 type Client struct {
 	config       *Config
@@ -27,6 +45,32 @@ type Client struct {
 	eventHandler EventHandler          // 事件处理器
 	crypt        *crypto.WXBizMsgCrypt // 消息加解密实例
 	req          *core.Request
+	middlewares  []core.Middleware     // 请求中间件
+	redactor     *core.Redactor        // 日志脱敏器
+	rateLimiter  *core.RateLimiter     // 限流器
+	retryPolicy  *core.RetryPolicy     // 重试策略
+	expiryPolicy *core.ExpiryPolicy    // 凭证提前过期策略
+	breaker      *core.CircuitBreaker  // 熔断器
+	resolver     core.EndpointResolver // 接口域名解析器
+	metrics      core.Metrics          // 指标收集器
+	tracer       core.Tracer           // 链路追踪
+	refreshGroup core.RefreshGroup     // 合并并发的token刷新
+
+	tokenProvider core.TokenProvider // 授权方access_token来源，为nil时使用refresh_token自行刷新
+
+	legacyStorage storage.TokenStorage // 升级前未使用命名空间的存储，只读，存储不支持命名空间时为nil
+
+	issuedMu     sync.Mutex
+	issuedTokens map[string][2]string // 授权方appid -> 最近下发的两个access_token，用于定位失效token所属的授权方
+
+	ticketMu sync.Mutex
+	tickets  map[string]authorizerTicket // ticket类型和授权方appid -> 缓存的ticket
+
+	verifyTicketWait    time.Duration // 验证票据不存在时触发推送后等待的时长，为0时不触发推送
+	verifyTicketLead    time.Duration // 验证票据剩余有效期小于该时长时在后台触发推送
+	verifyTicketMu      sync.Mutex
+	verifyTicketArrived chan struct{} // 收到验证票据时关闭
+	lastPushTicket      time.Time     // 最近一次触发推送的时间
 }
 
 // NewClient 创建新的API客户端（使用默认文件存储）
@@ -35,6 +79,16 @@ type Client struct {
 //   - debugger.LoggerInterface: 自定义日志器
 //   - HTTPClient: 自定义HTTP客户端
 //   - EventHandler: 自定义事件处理器
+//   - core.Middleware、[]core.Middleware: 请求中间件
+//   - *core.Redactor: 日志脱敏器，默认隐藏凭证和用户隐私信息
+//   - *core.RateLimiter: 限流器，按appid和接口限制调用频率和每日调用次数
+//   - *core.RetryPolicy: 重试策略，系统繁忙和GET请求的临时故障按指数退避重试
+//   - *core.ExpiryPolicy: 凭证提前过期策略，默认提前5分钟或有效期的5%
+//   - *core.CircuitBreaker: 熔断器，接口连续故障时快速失败
+//   - core.EndpointResolver: 接口域名解析器，如 *core.DomainResolver，用于故障转移到备用域名
+//   - core.Metrics: 指标收集器，记录接口耗时、错误码、token刷新和存储耗时
+//   - core.Tracer: 链路追踪，为接口调用、token刷新和存储操作创建span
+//   - core.TokenProvider: 授权方access_token来源，如 *core.StaticTokenProvider、*core.HTTPTokenProvider，token由外部系统维护时使用
 //
 // @return *Client API客户端实例
 func NewClient(config *Config, opt ...any) (apiClient *Client) {
@@ -47,14 +101,22 @@ func NewClient(config *Config, opt ...any) (apiClient *Client) {
 }
 
 // NewClientWithStorage 创建新的API客户端（使用自定义存储）
+// 存储实现 storage.NamespacedStorage 时数据保存在 storage.ComponentNamespace(config.ComponentAppID)，多个第三方平台共用同一存储时互不影响；
+// 命名空间中没有component_access_token、验证票据或授权方token时，读取升级前保存在默认命名空间的数据，新数据只写入命名空间
 func NewClientWithStorage(config *Config, storage storage.TokenStorage, opt ...any) *Client {
 	client := &Client{
 		config:     config,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		storage:    storage,
 		logger:     logger.NewDefaultLoggerInterface(),
+		metrics:    core.NopMetrics{},
+		tracer:     core.NopTracer{},
 		crypt:      crypto.NewWXBizMsgCrypt(config.ComponentToken, config.EncodingAESKey, config.ComponentAppID),
+
+		verifyTicketWait: DefaultVerifyTicketWait,
+		verifyTicketLead: DefaultVerifyTicketLead,
 	}
+	client.scopeStorage()
 
 	// 遍历所有可选参数，根据类型进行相应设置
 	if len(opt) > 0 {
@@ -66,6 +128,37 @@ func NewClientWithStorage(config *Config, storage storage.TokenStorage, opt ...a
 			case core.HTTPClient:
 				// 设置自定义HTTP客户端
 				client.SetHTTPClient(v)
+			case core.Middleware, func(core.RoundTrip) core.RoundTrip, []core.Middleware:
+				// 添加请求中间件
+				middlewares, _ := core.ParseMiddlewares(v)
+				client.Use(middlewares...)
+			case *core.Redactor:
+				// 设置日志脱敏器
+				client.SetRedactor(v)
+			case *core.RateLimiter:
+				// 设置限流器
+				client.SetRateLimiter(v)
+			case *core.RetryPolicy:
+				// 设置重试策略
+				client.SetRetryPolicy(v)
+			case *core.ExpiryPolicy:
+				// 设置凭证提前过期策略
+				client.SetExpiryPolicy(v)
+			case *core.CircuitBreaker:
+				// 设置熔断器
+				client.SetCircuitBreaker(v)
+			case core.EndpointResolver:
+				// 设置接口域名解析器
+				client.SetEndpointResolver(v)
+			case core.Metrics:
+				// 设置指标收集器
+				client.SetMetrics(v)
+			case core.Tracer:
+				// 设置链路追踪
+				client.SetTracer(v)
+			case core.TokenProvider:
+				// 设置授权方access_token来源
+				client.SetTokenProvider(v)
 			case EventHandler:
 				// 设置自定义事件处理器
 				client.SetEventHandler(v)
@@ -76,11 +169,58 @@ func NewClientWithStorage(config *Config, storage storage.TokenStorage, opt ...a
 		}
 	}
 
-	client.req = core.NewRequest(client.httpClient, client.logger)
+	client.req = client.newRequest()
 
 	return client
 }
 
+// scopeStorage 存储支持命名空间时使用第三方平台的命名空间，原存储保留为 legacyStorage 以读取升级前的数据
+func (c *Client) scopeStorage() {
+	scoped, err := storage.Scope(c.storage, storage.ComponentNamespace(c.config.ComponentAppID))
+	if err != nil {
+		return
+	}
+	c.legacyStorage = c.storage
+	c.storage = scoped
+}
+
+// getComponentToken 从存储读取component_access_token，命名空间中没有时读取默认命名空间中升级前保存的token
+func (c *Client) getComponentToken(ctx context.Context) (*storage.ComponentAccessToken, error) {
+	token, err := c.storage.GetComponentToken(ctx)
+	if err != nil || token != nil || c.legacyStorage == nil {
+		return token, err
+	}
+	return c.legacyStorage.GetComponentToken(ctx)
+}
+
+// getVerifyTicket 从存储读取验证票据
+// 命名空间中没有未过期的票据时读取默认命名空间中的票据，接收推送的服务尚未升级、仍写入默认命名空间时也能获取token
+func (c *Client) getVerifyTicket(ctx context.Context) (*storage.ComponentVerifyTicket, error) {
+	ticket, err := c.storage.GetComponentVerifyTicket(ctx)
+	if err != nil || c.legacyStorage == nil || (ticket != nil && time.Now().Before(ticket.ExpiresAt)) {
+		return ticket, err
+	}
+	legacy, err := c.legacyStorage.GetComponentVerifyTicket(ctx)
+	if err != nil || legacy == nil {
+		return ticket, err
+	}
+	return legacy, nil
+}
+
+// getAuthorizerToken 从存储读取授权方token
+// 命名空间中没有时读取默认命名空间中升级前保存的token，没有refresh_token的是公众号自身的token，不使用
+func (c *Client) getAuthorizerToken(ctx context.Context, authorizerAppID string) (*storage.AuthorizerAccessToken, error) {
+	token, err := c.storage.GetAuthorizerToken(ctx, authorizerAppID)
+	if err != nil || token != nil || c.legacyStorage == nil {
+		return token, err
+	}
+	legacy, err := c.legacyStorage.GetAuthorizerToken(ctx, authorizerAppID)
+	if err != nil || legacy == nil || legacy.AuthorizerRefreshToken == "" {
+		return nil, err
+	}
+	return legacy, nil
+}
+
 // SetLogger 设置日志器
 func (c *Client) SetLogger(log any) {
 	if log == nil {
@@ -97,7 +237,7 @@ func (c *Client) SetLogger(log any) {
 	}
 	// 同时更新请求对象中的日志器
 	if c.req != nil {
-		c.req = core.NewRequest(c.httpClient, c.logger)
+		c.req = c.newRequest()
 	}
 }
 
@@ -106,10 +246,181 @@ func (c *Client) SetHTTPClient(client core.HTTPClient) {
 	c.httpClient = client
 	// 同时更新请求对象中的HTTP客户端
 	if c.req != nil {
-		c.req = core.NewRequest(client, c.logger)
+		c.req = c.newRequest()
+	}
+}
+
+// Use 添加请求中间件，作用于该客户端发出的所有请求
+func (c *Client) Use(middlewares ...core.Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	if c.req != nil {
+		c.req.Use(middlewares...)
+	}
+}
+
+// SetRedactor 设置日志脱敏器，为nil时使用默认规则
+func (c *Client) SetRedactor(redactor *core.Redactor) {
+	c.redactor = redactor
+	if c.req != nil {
+		c.req.SetRedactor(redactor)
+	}
+}
+
+// getRedactor 获取日志脱敏器，未设置时使用默认规则
+func (c *Client) getRedactor() *core.Redactor {
+	if c.redactor == nil {
+		return core.NewRedactor()
+	}
+	return c.redactor
+}
+
+// SetRateLimiter 设置限流器，为nil时不限流
+func (c *Client) SetRateLimiter(limiter *core.RateLimiter) {
+	c.rateLimiter = limiter
+	if c.req != nil {
+		c.req.SetRateLimiter(limiter)
+	}
+}
+
+// GetRateLimiter 获取限流器，未设置时返回nil
+func (c *Client) GetRateLimiter() *core.RateLimiter {
+	return c.rateLimiter
+}
+
+// SetRetryPolicy 设置重试策略，为nil时不重试
+func (c *Client) SetRetryPolicy(policy *core.RetryPolicy) {
+	c.retryPolicy = policy
+	if c.req != nil {
+		c.req.SetRetryPolicy(policy)
+	}
+}
+
+// GetRetryPolicy 获取重试策略，未设置时返回nil
+func (c *Client) GetRetryPolicy() *core.RetryPolicy {
+	return c.retryPolicy
+}
+
+// retryPolicyOrDefault 获取重试策略，未设置时返回默认策略
+func (c *Client) retryPolicyOrDefault() *core.RetryPolicy {
+	if c.retryPolicy != nil {
+		return c.retryPolicy
+	}
+	return core.DefaultRetryPolicy()
+}
+
+// SetExpiryPolicy 设置凭证提前过期策略，为nil时使用 core.DefaultExpiryPolicy
+func (c *Client) SetExpiryPolicy(policy *core.ExpiryPolicy) {
+	c.expiryPolicy = policy
+}
+
+// GetExpiryPolicy 获取凭证提前过期策略，未设置时返回nil
+func (c *Client) GetExpiryPolicy() *core.ExpiryPolicy {
+	return c.expiryPolicy
+}
+
+// expiresAt 按提前过期策略计算凭证的截止时间
+// @param start time.Time 发起获取凭证请求的时间
+// @param expiresIn int 微信返回的有效期，单位秒
+func (c *Client) expiresAt(start time.Time, expiresIn int) time.Time {
+	return c.expiryPolicy.ExpiresAt(start, expiresIn)
+}
+
+// SetCircuitBreaker 设置熔断器，为nil时不熔断
+func (c *Client) SetCircuitBreaker(breaker *core.CircuitBreaker) {
+	c.breaker = breaker
+	if c.req != nil {
+		c.req.SetCircuitBreaker(breaker)
+	}
+}
+
+// SetEndpointResolver 设置接口域名解析器，为nil时直接请求 core.BaseAPIURL
+func (c *Client) SetEndpointResolver(resolver core.EndpointResolver) {
+	c.resolver = resolver
+	if c.req != nil {
+		c.req.SetEndpointResolver(resolver)
 	}
 }
 
+// GetEndpointResolver 获取接口域名解析器，未设置时返回nil
+func (c *Client) GetEndpointResolver() core.EndpointResolver {
+	return c.resolver
+}
+
+// SetMetrics 设置指标收集器，为nil时不收集
+// 设置后存储会被包装为 *storage.InstrumentedStorage 以记录存储操作耗时
+func (c *Client) SetMetrics(metrics core.Metrics) {
+	if metrics == nil {
+		metrics = core.NopMetrics{}
+	}
+	c.metrics = metrics
+	c.instrumentStorage()
+	if c.req != nil {
+		c.req.SetMetrics(metrics)
+	}
+}
+
+// GetMetrics 获取指标收集器
+func (c *Client) GetMetrics() core.Metrics {
+	return c.metrics
+}
+
+// SetTracer 设置链路追踪，为nil时不追踪
+// 设置后存储会被包装为 *storage.InstrumentedStorage 以追踪存储操作
+func (c *Client) SetTracer(tracer core.Tracer) {
+	if tracer == nil {
+		tracer = core.NopTracer{}
+	}
+	c.tracer = tracer
+	c.instrumentStorage()
+	if c.req != nil {
+		c.req.SetTracer(tracer)
+	}
+}
+
+// GetTracer 获取链路追踪
+func (c *Client) GetTracer() core.Tracer {
+	return c.tracer
+}
+
+// instrumentStorage 设置了指标收集器或链路追踪时包装存储，都未设置时还原为原始存储
+func (c *Client) instrumentStorage() {
+	if instrumented, ok := c.storage.(*storage.InstrumentedStorage); ok {
+		c.storage = instrumented.Unwrap()
+	}
+	_, nopMetrics := c.metrics.(core.NopMetrics)
+	_, nopTracer := c.tracer.(core.NopTracer)
+	if !nopMetrics || !nopTracer {
+		c.storage = storage.NewInstrumentedStorage(c.storage, c.metrics, c.tracer)
+	}
+}
+
+// refreshToken 从微信获取token，记录刷新指标并创建span
+// @param kind string token类型，如 core.TokenKindAccessToken
+// @param fetch func(ctx context.Context) error 获取token的请求
+func (c *Client) refreshToken(ctx context.Context, kind string, fetch func(ctx context.Context) error) error {
+	ctx, span := c.tracer.Start(ctx, core.SpanTokenRefresh, core.Attr(core.AttrTokenKind, kind))
+	err := fetch(ctx)
+	c.metrics.IncCounter(core.MetricTokenRefresh, core.Labels{"kind": kind, "result": core.ResultLabel(err)})
+	core.EndSpan(span, err)
+	return err
+}
+
+// newRequest 创建请求对象，token失效时自动刷新并重试
+func (c *Client) newRequest() *core.Request {
+	req := core.NewRequest(c.httpClient, c.logger)
+	req.SetTokenRefresher(c.refreshStaleToken)
+	req.SetRedactor(c.redactor)
+	req.SetRateLimiter(c.rateLimiter)
+	req.SetRetryPolicy(c.retryPolicy)
+	req.SetCircuitBreaker(c.breaker)
+	req.SetEndpointResolver(c.resolver)
+	req.SetMetrics(c.metrics)
+	req.SetTracer(c.tracer)
+	req.SetAppIDResolver(c.resolveAppID)
+	req.Use(c.middlewares...)
+	return req
+}
+
 // SetEventHandler 设置事件处理器
 func (c *Client) SetEventHandler(handler EventHandler) {
 	c.eventHandler = handler
@@ -134,8 +445,8 @@ func (c *Client) GetLogger() logger.LoggerInterface {
 }
 
 // SetComponentToken 设置开放平台令牌
-func (c *Client) SetComponentToken(token *storage.ComponentAccessToken) error {
-	return c.storage.SaveComponentToken(context.Background(), token)
+func (c *Client) SetComponentToken(ctx context.Context, token *storage.ComponentAccessToken) error {
+	return c.storage.SaveComponentToken(ctx, token)
 }
 
 // GetComponentToken 获取开放平台令牌
@@ -144,7 +455,7 @@ func (c *Client) SetComponentToken(token *storage.ComponentAccessToken) error {
 // @return error 错误信息，如果令牌获取失败或自动刷新失败则返回错误
 func (c *Client) GetComponentToken(ctx context.Context) (*storage.ComponentAccessToken, error) {
 	// 从存储获取令牌
-	token, err := c.storage.GetComponentToken(ctx)
+	token, err := c.getComponentToken(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -174,23 +485,54 @@ func (c *Client) GetPreAuthCode(ctx context.Context) (*storage.PreAuthCode, erro
 	return c.storage.GetPreAuthCode(ctx)
 }
 
-// SetAuthorizerToken 设置授权方token信息
-func (c *Client) SetAuthorizerToken(authorizerAppID, accessToken, refreshToken string, expiresIn int) error {
+// SetAuthorizerToken 设置授权方token信息，有效期从当前时间开始计算
+func (c *Client) SetAuthorizerToken(ctx context.Context, authorizerAppID, accessToken, refreshToken string, expiresIn int) error {
+	return c.saveAuthorizerToken(ctx, authorizerAppID, accessToken, refreshToken, expiresIn, time.Now())
+}
+
+// saveAuthorizerToken 保存授权方token信息
+// @param start time.Time 发起获取token请求的时间，按提前过期策略计算截止时间
+func (c *Client) saveAuthorizerToken(ctx context.Context, authorizerAppID, accessToken, refreshToken string, expiresIn int, start time.Time) error {
 	token := &storage.AuthorizerAccessToken{
 		AuthorizerAppID:        authorizerAppID,
 		AuthorizerAccessToken:  accessToken,
 		ExpiresIn:              expiresIn,
-		ExpiresAt:              time.Now().Add(time.Duration(expiresIn) * time.Second),
+		ExpiresAt:              c.expiresAt(start, expiresIn),
 		AuthorizerRefreshToken: refreshToken,
 	}
 
-	return c.storage.SaveAuthorizerToken(context.Background(), authorizerAppID, token)
+	return c.storage.SaveAuthorizerToken(ctx, authorizerAppID, token)
+}
+
+// SetTokenProvider 设置授权方access_token来源，为nil时恢复默认
+// 默认使用存储中的authorizer_refresh_token调用微信接口刷新；token由外部系统维护时设置只读来源，
+// 客户端不再调用微信接口获取授权方token，也不会被 core.Refresher 主动刷新
+func (c *Client) SetTokenProvider(provider core.TokenProvider) {
+	c.tokenProvider = provider
+}
+
+// GetTokenProvider 获取授权方access_token来源，未设置时返回 *SelfTokenProvider
+func (c *Client) GetTokenProvider() core.TokenProvider {
+	if c.tokenProvider != nil {
+		return c.tokenProvider
+	}
+	return NewSelfTokenProvider(c)
 }
 
-// GetAuthorizerAccessToken 获取授权方access_token
+// GetAuthorizerAccessToken 获取授权方access_token，从 GetTokenProvider 返回的来源获取
 func (c *Client) GetAuthorizerAccessToken(ctx context.Context, authorizerAppID string) (string, error) {
+	accessToken, err := c.GetTokenProvider().AccessToken(ctx, authorizerAppID)
+	if err != nil {
+		return "", err
+	}
+	c.recordIssuedToken(authorizerAppID, accessToken)
+	return accessToken, nil
+}
+
+// getSelfAuthorizerAccessToken 从存储获取授权方access_token，过期时使用refresh_token刷新
+func (c *Client) getSelfAuthorizerAccessToken(ctx context.Context, authorizerAppID string) (string, error) {
 	// 从存储中获取授权方token
-	token, err := c.storage.GetAuthorizerToken(ctx, authorizerAppID)
+	token, err := c.getAuthorizerToken(ctx, authorizerAppID)
 	if err != nil {
 		return "", err
 	}
@@ -200,7 +542,223 @@ func (c *Client) GetAuthorizerAccessToken(ctx context.Context, authorizerAppID s
 	}
 
 	// 重新获取授权方access_token
-	return c.refreshAuthorizerAccessToken(ctx, authorizerAppID)
+	return c.refreshAuthorizerAccessToken(ctx, authorizerAppID, time.Now())
+}
+
+// recordIssuedToken 记录下发给调用方的授权方access_token
+func (c *Client) recordIssuedToken(authorizerAppID, accessToken string) {
+	c.issuedMu.Lock()
+	defer c.issuedMu.Unlock()
+
+	if c.issuedTokens == nil {
+		c.issuedTokens = make(map[string][2]string)
+	}
+	issued := c.issuedTokens[authorizerAppID]
+	if issued[0] != accessToken {
+		c.issuedTokens[authorizerAppID] = [2]string{accessToken, issued[0]}
+	}
+}
+
+// resolveAppID 根据请求携带的access_token解析调用方appid
+// 授权方的access_token解析为授权方appid，其他请求属于第三方平台
+func (c *Client) resolveAppID(requestURL *url.URL) string {
+	if token := requestURL.Query().Get("access_token"); token != "" {
+		if appID, ok := c.findIssuedToken(token); ok {
+			return appID
+		}
+	}
+	return c.config.ComponentAppID
+}
+
+// findIssuedToken 根据access_token查找所属的授权方appid
+func (c *Client) findIssuedToken(accessToken string) (string, bool) {
+	c.issuedMu.Lock()
+	defer c.issuedMu.Unlock()
+
+	for appID, issued := range c.issuedTokens {
+		if issued[0] == accessToken || issued[1] == accessToken {
+			return appID, true
+		}
+	}
+	return "", false
+}
+
+// refreshStaleToken 清除被微信拒绝的token并获取新token
+// 如果存储中的token已被其他请求刷新，直接返回新token
+func (c *Client) refreshStaleToken(ctx context.Context, param, staleToken string) (string, error) {
+	switch param {
+	case "component_access_token":
+		token, err := c.RefreshComponentAccessToken(ctx, staleToken)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+
+	case "access_token":
+		authorizerAppID, ok := c.findIssuedToken(staleToken)
+		if !ok {
+			return "", nil
+		}
+		accessToken, err := c.GetTokenProvider().RefreshAccessToken(ctx, authorizerAppID, staleToken)
+		if err != nil || accessToken == "" {
+			return "", err
+		}
+		c.recordIssuedToken(authorizerAppID, accessToken)
+		return accessToken, nil
+	}
+
+	return "", nil
+}
+
+// refreshStaleAuthorizerToken 将失效的授权方access_token标记为过期，并使用refresh_token获取新token
+func (c *Client) refreshStaleAuthorizerToken(ctx context.Context, authorizerAppID, staleToken string) (string, error) {
+	// 与 refreshAuthorizerAccessToken 使用同一个刷新key，避免将其他请求刚刷新的token标记为过期
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, authorizerTokenRefreshKey(authorizerAppID), func(ctx context.Context) (string, error) {
+		token, err := c.getAuthorizerToken(ctx, authorizerAppID)
+		if err != nil {
+			return "", err
+		}
+		if token == nil {
+			return "", nil
+		}
+		if token.AuthorizerAccessToken == staleToken {
+			// 保留refresh_token，仅将access_token标记为过期，升级前的token由此写入命名空间
+			token.ExpiresAt = time.Time{}
+			if err := c.storage.SaveAuthorizerToken(ctx, authorizerAppID, token); err != nil {
+				return "", fmt.Errorf("清除失效的授权方token失败: %w", err)
+			}
+		}
+		return c.fetchAuthorizerAccessToken(ctx, authorizerAppID, time.Now())
+	})
+}
+
+// RefreshTargets 实现 core.RefreshSource 接口，供 core.Refresher 主动刷新
+// 包括component_access_token、存储中所有授权方的access_token，以及已缓存的授权方ticket。
+// 授权方token保存在存储中时，token已被删除的授权方（如在其他实例上调用了 PurgeAuthorizer）的ticket从缓存中清除，不再刷新
+// 授权方token来源未实现 core.RefreshSource（如只读来源）时不主动刷新授权方access_token
+func (c *Client) RefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	componentToken, err := c.storage.GetComponentToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取ComponentAccessToken失败: %w", err)
+	}
+	component := core.RefreshTarget{
+		Kind:  core.TokenKindComponentAccessToken,
+		AppID: c.config.ComponentAppID,
+		Refresh: func(ctx context.Context, before time.Time) error {
+			_, err := c.refreshComponentAccessToken(ctx, "", before)
+			return err
+		},
+	}
+	if componentToken != nil {
+		component.ExpiresAt = componentToken.ExpiresAt
+	}
+	targets := []core.RefreshTarget{component}
+
+	if source, ok := c.GetTokenProvider().(core.RefreshSource); ok {
+		authorizerTargets, err := source.RefreshTargets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, authorizerTargets...)
+	}
+
+	// 只刷新使用过的ticket，未使用JS-SDK或卡券的授权方不获取ticket
+	authClient := NewAuthClient(c)
+	authorized := make(map[string]bool)
+	for _, ticket := range c.cachedTickets() {
+		ok, checked := authorized[ticket.authorizerAppID]
+		if !checked {
+			if ok, err = c.hasAuthorizerToken(ctx, ticket.authorizerAppID); err != nil {
+				return nil, err
+			}
+			authorized[ticket.authorizerAppID] = ok
+			if !ok {
+				c.dropTickets(ticket.authorizerAppID)
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		authorizer := authClient.NewAuthorizerClient(ticket.authorizerAppID)
+		ticketType := ticket.ticketType
+		targets = append(targets, core.RefreshTarget{
+			Kind:      ticket.kind,
+			AppID:     ticket.authorizerAppID,
+			ExpiresAt: ticket.expiresAt,
+			Refresh: func(ctx context.Context, before time.Time) error {
+				_, err := authorizer.refreshTicket(ctx, ticketType, before)
+				return err
+			},
+		})
+	}
+
+	return targets, nil
+}
+
+// hasAuthorizerToken 判断存储中是否仍有授权方的token
+// 设置了token来源时授权方token不保存在存储中，始终返回true
+func (c *Client) hasAuthorizerToken(ctx context.Context, authorizerAppID string) (bool, error) {
+	if c.tokenProvider != nil {
+		return true, nil
+	}
+	token, err := c.getAuthorizerToken(ctx, authorizerAppID)
+	if err != nil {
+		return false, fmt.Errorf("获取授权方token失败: %w", err)
+	}
+	return token != nil, nil
+}
+
+// selfRefreshTargets 返回存储中所有授权方access_token的主动刷新信息
+func (c *Client) selfRefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	authorizerAppIDs, err := c.listAuthorizerAppIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取授权方列表失败: %w", err)
+	}
+
+	var targets []core.RefreshTarget
+	for _, authorizerAppID := range authorizerAppIDs {
+		token, err := c.getAuthorizerToken(ctx, authorizerAppID)
+		if err != nil {
+			return nil, fmt.Errorf("获取授权方token失败: %w", err)
+		}
+		if token == nil {
+			continue
+		}
+		targets = append(targets, core.RefreshTarget{
+			Kind:      core.TokenKindAuthorizerToken,
+			AppID:     authorizerAppID,
+			ExpiresAt: token.ExpiresAt,
+			Refresh: func(ctx context.Context, before time.Time) error {
+				_, err := c.refreshAuthorizerAccessToken(ctx, authorizerAppID, before)
+				return err
+			},
+		})
+	}
+	return targets, nil
+}
+
+// listAuthorizerAppIDs 返回命名空间和默认命名空间中保存了token的授权方appid
+func (c *Client) listAuthorizerAppIDs(ctx context.Context) ([]string, error) {
+	authorizerAppIDs, err := c.storage.ListAuthorizerTokens(ctx)
+	if err != nil || c.legacyStorage == nil {
+		return authorizerAppIDs, err
+	}
+	legacy, err := c.legacyStorage.ListAuthorizerTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(authorizerAppIDs))
+	for _, authorizerAppID := range authorizerAppIDs {
+		seen[authorizerAppID] = true
+	}
+	for _, authorizerAppID := range legacy {
+		if !seen[authorizerAppID] {
+			authorizerAppIDs = append(authorizerAppIDs, authorizerAppID)
+		}
+	}
+	return authorizerAppIDs, nil
 }
 
 // GetComponentVerifyTicket 获取验证票据
@@ -208,7 +766,7 @@ func (c *Client) GetAuthorizerAccessToken(ctx context.Context, authorizerAppID s
 // @return *storage.ComponentVerifyTicket 验证票据结构，包含票据内容和有效期信息
 // @return error 错误信息
 func (c *Client) GetComponentVerifyTicket(ctx context.Context) (*storage.ComponentVerifyTicket, error) {
-	return c.storage.GetComponentVerifyTicket(ctx)
+	return c.getVerifyTicket(ctx)
 }
 
 // SaveComponentVerifyTicket 保存验证票据
@@ -216,25 +774,47 @@ func (c *Client) GetComponentVerifyTicket(ctx context.Context) (*storage.Compone
 // @param ticket string 票据内容
 // @return error 错误信息
 func (c *Client) SaveComponentVerifyTicket(ctx context.Context, ticket string) error {
-	return c.storage.SaveComponentVerifyTicket(ctx, ticket)
+	if err := c.storage.SaveComponentVerifyTicket(ctx, ticket); err != nil {
+		return err
+	}
+	c.notifyVerifyTicket()
+	return nil
+}
+
+// refreshAuthorizerAccessToken 刷新授权方access_token，同一授权方并发的刷新合并为一次请求，存储支持加锁时多个实例间互斥
+// @param before time.Time 存储中的token在该时间之后才过期时直接返回，不调用微信接口
+func (c *Client) refreshAuthorizerAccessToken(ctx context.Context, authorizerAppID string, before time.Time) (string, error) {
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, authorizerTokenRefreshKey(authorizerAppID), func(ctx context.Context) (string, error) {
+		return c.fetchAuthorizerAccessToken(ctx, authorizerAppID, before)
+	})
+}
+
+// authorizerTokenRefreshKey 返回授权方access_token的刷新key
+func authorizerTokenRefreshKey(authorizerAppID string) string {
+	return core.RefreshKey(core.TokenKindAuthorizerToken, authorizerAppID)
 }
 
-// refreshAuthorizerAccessToken 刷新授权方access_token
-func (c *Client) refreshAuthorizerAccessToken(ctx context.Context, authorizerAppID string) (string, error) {
+// fetchAuthorizerAccessToken 使用refresh_token从微信获取授权方access_token并保存到存储
+func (c *Client) fetchAuthorizerAccessToken(ctx context.Context, authorizerAppID string, before time.Time) (string, error) {
 	// 双重检查：再次从存储中获取
-	token, err := c.storage.GetAuthorizerToken(ctx, authorizerAppID)
+	token, err := c.getAuthorizerToken(ctx, authorizerAppID)
 	if err != nil {
 		return "", err
 	}
 
-	if token != nil && time.Now().Before(token.ExpiresAt) {
+	if token != nil && before.Before(token.ExpiresAt) {
 		return token.AuthorizerAccessToken, nil
 	}
 
 	// 调用微信API刷新授权方access_token
 	if token != nil && token.AuthorizerRefreshToken != "" {
 		// 使用refresh_token刷新access_token
-		result, err := c.RefreshAuthorizerToken(ctx, authorizerAppID, token.AuthorizerRefreshToken)
+		var result *AuthorizationInfo
+		start := time.Now()
+		err := c.refreshToken(ctx, core.TokenKindAuthorizerToken, func(ctx context.Context) (err error) {
+			result, err = c.RefreshAuthorizerToken(ctx, authorizerAppID, token.AuthorizerRefreshToken)
+			return err
+		})
 		if err != nil {
 			return "", err
 		}
@@ -244,12 +824,12 @@ func (c *Client) refreshAuthorizerAccessToken(ctx context.Context, authorizerApp
 			AuthorizerAppID:        authorizerAppID,
 			AuthorizerAccessToken:  result.AuthorizerAccessToken,
 			ExpiresIn:              result.ExpiresIn,
-			ExpiresAt:              time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+			ExpiresAt:              c.expiresAt(start, result.ExpiresIn),
 			AuthorizerRefreshToken: result.AuthorizerRefreshToken,
 		}
 
 		if err := c.storage.SaveAuthorizerToken(ctx, authorizerAppID, newToken); err != nil {
-			return "", fmt.Errorf("保存授权方token失败: %v", err)
+			return "", fmt.Errorf("保存授权方token失败: %w", err)
 		}
 
 		return result.AuthorizerAccessToken, nil
@@ -304,6 +884,12 @@ type AuthorizerTokenResponse struct {
 	AuthorizerRefreshToken string `json:"authorizer_refresh_token"`
 }
 
+// authorizerTokenResponse 刷新授权方token接口的完整响应，包含权限集信息
+type authorizerTokenResponse struct {
+	core.APIResponse
+	AuthorizationInfo
+}
+
 // GetAuthorizerInfoRequest 获取授权方信息请求参数
 type GetAuthorizerInfoRequest struct {
 	ComponentAppID  string `json:"component_appid"`
@@ -337,7 +923,7 @@ type GetAuthorizerListResponse struct {
 // GetComponentAccessToken 获取第三方平台access_token
 func (c *Client) GetComponentAccessToken(ctx context.Context, verifyTicket string) (*storage.ComponentAccessToken, error) {
 	// 直接从存储中获取令牌，不调用GetComponentToken避免递归
-	token, err := c.storage.GetComponentToken(ctx)
+	token, err := c.getComponentToken(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -347,9 +933,85 @@ func (c *Client) GetComponentAccessToken(ctx context.Context, verifyTicket strin
 		return token, nil
 	}
 
+	return c.refreshComponentAccessToken(ctx, verifyTicket, time.Now())
+}
+
+// RefreshComponentAccessToken 清除失效的component_access_token并重新获取
+// 存储中的token已被其他请求刷新、不再是staleToken时直接返回
+// @param ctx context.Context 上下文
+// @param staleToken string 失效的component_access_token
+// @return *storage.ComponentAccessToken 新的component_access_token
+// @return error 错误信息
+func (c *Client) RefreshComponentAccessToken(ctx context.Context, staleToken string) (*storage.ComponentAccessToken, error) {
+	if err := c.ensureVerifyTicket(ctx); err != nil {
+		return nil, err
+	}
+
+	// 与 GetComponentAccessToken 使用同一个刷新key，避免清除其他请求刚刷新的token
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, c.componentTokenRefreshKey(), func(ctx context.Context) (*storage.ComponentAccessToken, error) {
+		token, err := c.storage.GetComponentToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			// 命名空间中还没有token时，失效的可能是升级前的token，只读不删除
+			if token, err = c.getComponentToken(ctx); err != nil {
+				return nil, err
+			}
+		} else if token.AccessToken == staleToken || !token.ExpiresAt.After(time.Now()) {
+			if err := c.storage.DeleteComponentToken(ctx); err != nil {
+				return nil, fmt.Errorf("清除失效的ComponentAccessToken失败: %w", err)
+			}
+		}
+		if token != nil && token.AccessToken != staleToken && token.ExpiresAt.After(time.Now()) {
+			return token, nil
+		}
+
+		// 删除后仍可能读到升级前的token，只使用比失效token更晚过期的token
+		before := time.Now()
+		if token != nil && token.ExpiresAt.After(before) {
+			before = token.ExpiresAt
+		}
+		return c.fetchComponentAccessToken(ctx, "", before)
+	})
+}
+
+// refreshComponentAccessToken 刷新component_access_token，并发的刷新合并为一次请求，存储支持加锁时多个实例间互斥
+// @param verifyTicket string 验证票据，为空时从存储中获取
+// @param before time.Time 存储中的token在该时间之后才过期时直接返回，不调用微信接口
+func (c *Client) refreshComponentAccessToken(ctx context.Context, verifyTicket string, before time.Time) (*storage.ComponentAccessToken, error) {
+	if verifyTicket == "" {
+		// 在刷新锁之外等待验证票据，避免长时间占用锁
+		if err := c.ensureVerifyTicket(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return storage.RefreshWithLock(ctx, &c.refreshGroup, c.storage, c.componentTokenRefreshKey(), func(ctx context.Context) (*storage.ComponentAccessToken, error) {
+		return c.fetchComponentAccessToken(ctx, verifyTicket, before)
+	})
+}
+
+// componentTokenRefreshKey 返回component_access_token的刷新key
+func (c *Client) componentTokenRefreshKey() string {
+	return core.RefreshKey(core.TokenKindComponentAccessToken, c.config.ComponentAppID)
+}
+
+// fetchComponentAccessToken 从微信获取component_access_token并保存到存储
+// @param verifyTicket string 验证票据，为空时从存储中获取
+// @param before time.Time 存储中的token在该时间之后才过期时直接返回，不调用微信接口
+func (c *Client) fetchComponentAccessToken(ctx context.Context, verifyTicket string, before time.Time) (*storage.ComponentAccessToken, error) {
+	// 双重检查：再次从存储中获取
+	token, err := c.getComponentToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil && token.ExpiresAt.After(before) {
+		return token, nil
+	}
+
 	// 如果verifyTicket为空，从存储中获取验证票据
 	if verifyTicket == "" {
-		verifyTicketObj, err := c.storage.GetComponentVerifyTicket(ctx)
+		verifyTicketObj, err := c.getVerifyTicket(ctx)
 		if err != nil {
 			wd, _ := os.Getwd()
 			return nil, fmt.Errorf("获取验证票据失败: %v [storage=%T wd=%s]", err, c.storage, wd)
@@ -373,28 +1035,27 @@ func (c *Client) GetComponentAccessToken(ctx context.Context, verifyTicket strin
 		ExpiresIn            int    `json:"expires_in"`
 	}
 
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    URLComponentToken,
-		Body:   request,
-		Result: &result,
+	start := time.Now()
+	err = c.refreshToken(ctx, core.TokenKindComponentAccessToken, func(ctx context.Context) error {
+		return c.req.Make(ctx, &core.ReqMakeOpt{
+			Method: "POST",
+			URL:    URLComponentToken,
+			Body:   request,
+			Result: &result,
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	token = &storage.ComponentAccessToken{
 		AccessToken: result.ComponentAccessToken,
 		ExpiresIn:   result.ExpiresIn,
-		ExpiresAt:   time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+		ExpiresAt:   c.expiresAt(start, result.ExpiresIn),
 	}
 
 	// 保存到存储
-	if err := c.SetComponentToken(token); err != nil {
+	if err := c.SetComponentToken(ctx, token); err != nil {
 		c.logger.Warn(fmt.Sprintf("保存开放平台令牌失败: %v", err))
 	}
 
@@ -423,25 +1084,24 @@ func (c *Client) GetPreAuthCodeFromAPI(ctx context.Context) (*PreAuthCodeRespons
 
 	var result PreAuthCodeResponse
 	apiURL := fmt.Sprintf("%s?component_access_token=%s", URLPreAuthCode, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
+	start := time.Now()
+	err = c.refreshToken(ctx, core.TokenKindPreAuthCode, func(ctx context.Context) error {
+		return c.req.Make(ctx, &core.ReqMakeOpt{
+			Method: "POST",
+			URL:    apiURL,
+			Body:   request,
+			Result: &result,
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	// 保存到存储
 	preAuthCode := &storage.PreAuthCode{
 		PreAuthCode: result.PreAuthCode,
 		ExpiresIn:   result.ExpiresIn,
-		ExpiresAt:   time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+		ExpiresAt:   c.expiresAt(start, result.ExpiresIn),
 	}
 
 	// 保存到存储
@@ -465,6 +1125,22 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		CreateTime int64    `xml:"CreateTime"`
 	}
 
+	// 记录回调处理耗时，处理过程中出现错误时result为error
+	start := time.Now()
+	infoType := "unknown"
+	callbackErr := false
+	defer func() {
+		result := "success"
+		if callbackErr {
+			result = "error"
+		}
+		c.metrics.ObserveHistogram(core.MetricCallbackDuration, time.Since(start).Seconds(), core.Labels{
+			"source": "openplatform",
+			"type":   infoType,
+			"result": result,
+		})
+	}()
+
 	// 记录接收到的参数用于调试
 	c.logger.Info(fmt.Sprintf("处理授权事件，参数 - timestamp: %s, nonce: %s, encrypt_type: %s, msg_signature: %s",
 		timestamp, nonce, encryptType, msgSignature))
@@ -479,6 +1155,7 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		// 尝试解析XML获取加密内容
 		if err := xml.Unmarshal(xmlData, &encryptedMsg); err != nil {
 			c.logger.Error(fmt.Sprintf("解析加密消息XML失败: %v", err))
+			callbackErr = true
 			return "success", nil // 即使解析失败也返回success
 		}
 
@@ -490,13 +1167,15 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		c.logger.Info(fmt.Sprintf("检测到加密消息，开始解密处理，AppId: %s", encryptedMsg.AppId))
 
 		// 解密消息
-		decryptedData, err := c.DecryptMessage(encryptedMsg.Encrypt, msgSignature, timestamp, nonce)
+		decryptedData, err := c.DecryptMessageContext(ctx, encryptedMsg.Encrypt, msgSignature, timestamp, nonce)
 		if err != nil {
+			c.metrics.IncCounter(core.MetricCallbackDecryptFailures, core.Labels{"source": "openplatform"})
 			c.logger.Error(fmt.Sprintf("解密授权事件消息失败: %v", err))
+			callbackErr = true
 			return "success", nil // 即使解密失败也返回success
 		}
 
-		c.logger.Info(fmt.Sprintf("解密成功，解密后内容: %s", string(decryptedData)))
+		c.logger.Info("解密授权事件消息成功")
 
 		// 使用解密后的数据继续处理
 		xmlData = decryptedData
@@ -513,15 +1192,25 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 	err := xml.Unmarshal(xmlData, &baseEvent)
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("解析授权事件XML失败: %v", err))
+		callbackErr = true
 		return "success", nil // 即使解析失败也返回success
 	}
 
+	// 事件中包含验证票据、授权码等凭证，完整内容脱敏后以 Debug 级别记录
+	logger.Debug(c.logger, "授权事件内容", map[string]interface{}{
+		"info_type": baseEvent.InfoType,
+		"content":   c.getRedactor().Body(xmlData),
+	})
+
 	// 验证事件签名和时间戳
 	if err = c.validateAuthorizationEvent(&baseEvent); err != nil {
 		c.logger.Error(fmt.Sprintf("授权事件验证失败: %v", err))
+		callbackErr = true
 		return "success", nil // 即使验证失败也返回success
 	}
 
+	infoType = baseEvent.InfoType
+
 	// 根据事件类型进行处理
 	switch baseEvent.InfoType {
 	case "authorized":
@@ -529,11 +1218,13 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		err = xml.Unmarshal(xmlData, &event)
 		if err != nil {
 			c.logger.Error(fmt.Sprintf("解析授权成功事件失败: %v", err))
+			callbackErr = true
 			break
 		}
-		c.logger.Info(fmt.Sprintf("解析授权成功事件成功，事件内容: %+v", event))
+		c.logger.Info(fmt.Sprintf("解析授权成功事件成功，授权方AppID: %s", event.AuthorizerAppid))
 		if err := c.GetEventHandler().HandleAuthorized(ctx, &event); err != nil {
 			c.logger.Error(fmt.Sprintf("处理授权成功事件失败: %v", err))
+			callbackErr = true
 		}
 
 	case "unauthorized":
@@ -541,11 +1232,13 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		err = xml.Unmarshal(xmlData, &event)
 		if err != nil {
 			c.logger.Error(fmt.Sprintf("解析取消授权事件失败: %v", err))
+			callbackErr = true
 			break
 		}
-		c.logger.Info(fmt.Sprintf("解析取消授权事件成功，事件内容: %+v", event))
+		c.logger.Info(fmt.Sprintf("解析取消授权事件成功，授权方AppID: %s", event.AuthorizerAppid))
 		if err := c.GetEventHandler().HandleUnauthorized(ctx, &event); err != nil {
 			c.logger.Error(fmt.Sprintf("处理取消授权事件失败: %v", err))
+			callbackErr = true
 		}
 
 	case "updateauthorized":
@@ -553,11 +1246,13 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		err := xml.Unmarshal(xmlData, &event)
 		if err != nil {
 			c.logger.Error(fmt.Sprintf("解析授权更新事件失败: %v", err))
+			callbackErr = true
 			break
 		}
-		c.logger.Info(fmt.Sprintf("解析授权更新事件成功，事件内容: %+v", event))
+		c.logger.Info(fmt.Sprintf("解析授权更新事件成功，授权方AppID: %s", event.AuthorizerAppid))
 		if err = c.GetEventHandler().HandleUpdateAuthorized(ctx, &event); err != nil {
 			c.logger.Error(fmt.Sprintf("处理授权更新事件失败: %v", err))
+			callbackErr = true
 		}
 
 	case "component_verify_ticket":
@@ -565,18 +1260,21 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		err := xml.Unmarshal(xmlData, &event)
 		if err != nil {
 			c.logger.Error(fmt.Sprintf("解析验证票据事件失败: %v", err))
+			callbackErr = true
 			// 根据微信官方文档要求，即使解析失败也必须返回success
 			break
 		}
-		c.logger.Info(fmt.Sprintf("解析验证票据事件成功，事件内容: %+v", event))
-		// 存储验证票据
-		if err := c.storage.SaveComponentVerifyTicket(ctx, event.ComponentVerifyTicket); err != nil {
+		c.logger.Info(fmt.Sprintf("解析验证票据事件成功，AppId: %s", event.AppId))
+		// 存储验证票据，并通知等待票据的请求
+		if err := c.SaveComponentVerifyTicket(ctx, event.ComponentVerifyTicket); err != nil {
 			c.logger.Error(fmt.Sprintf("存储验证票据失败: %v", err))
+			callbackErr = true
 			// 根据微信官方文档要求，即使存储失败也必须返回success
 		}
 
 		if err := c.GetEventHandler().HandleComponentVerifyTicket(ctx, &event); err != nil {
 			c.logger.Error(fmt.Sprintf("处理验证票据事件失败: %v", err))
+			callbackErr = true
 			// 根据微信官方文档要求，即使处理失败也必须返回success
 		}
 
@@ -585,14 +1283,16 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 		err := xml.Unmarshal(xmlData, &event)
 		if err != nil {
 			c.logger.Error(fmt.Sprintf("解析EncodingAESKey变更事件失败: %v", err))
+			callbackErr = true
 			break
 		}
-		c.logger.Info(fmt.Sprintf("解析EncodingAESKey变更事件成功，事件内容: %+v", event))
+		c.logger.Info(fmt.Sprintf("解析EncodingAESKey变更事件成功，AppId: %s", event.AppId))
 		// 保存上一次的EncodingAESKey
 		if c.crypt != nil {
-			err2 := c.crypt.SetPrevEncodingAESKey(c.config.EncodingAESKey)
+			err2 := c.crypt.SetPrevEncodingAESKey(ctx, c.config.EncodingAESKey)
 			if err2 != nil {
 				c.logger.Error(fmt.Sprintf("设置上一次EncodingAESKey失败: %v", err2))
+				callbackErr = true
 				break
 			}
 		}
@@ -602,6 +1302,7 @@ func (c *Client) HandleAuthorizationEvent(ctx context.Context, xmlData []byte, m
 
 		if err := c.GetEventHandler().HandleEncodingAESKeyChanged(ctx, &event); err != nil {
 			c.logger.Error(fmt.Sprintf("处理EncodingAESKey变更事件失败: %v", err))
+			callbackErr = true
 		}
 
 	default:
@@ -638,18 +1339,24 @@ func (c *Client) validateAuthorizationEvent(event *AuthorizationEvent) error {
 
 // DecryptMessage 解密消息（用于处理加密的授权事件）
 func (c *Client) DecryptMessage(encryptedMsg, msgSignature, timestamp, nonce string) ([]byte, error) {
+	return c.DecryptMessageContext(context.Background(), encryptedMsg, msgSignature, timestamp, nonce)
+}
+
+// DecryptMessageContext 解密消息，ctx用于读写存储中上一次的EncodingAESKey
+func (c *Client) DecryptMessageContext(ctx context.Context, encryptedMsg, msgSignature, timestamp, nonce string) ([]byte, error) {
 	// 验证消息签名
 	if err := c.verifySignature(msgSignature, timestamp, nonce, encryptedMsg); err != nil {
-		return nil, fmt.Errorf("消息签名验证失败: %v", err)
+		return nil, fmt.Errorf("消息签名验证失败: %w", err)
 	}
 
 	// 使用crypto包中的解密实现
 	wxCrypt := crypto.NewWXBizMsgCrypt(c.config.ComponentToken, c.config.EncodingAESKey, c.config.ComponentAppID)
+	wxCrypt.SetLogger(c.logger)
 
 	// 解密消息
-	decryptedMsg, err := wxCrypt.DecryptMsg(msgSignature, timestamp, nonce, encryptedMsg)
+	decryptedMsg, err := wxCrypt.DecryptMsgContext(ctx, msgSignature, timestamp, nonce, encryptedMsg)
 	if err != nil {
-		return nil, fmt.Errorf("消息解密失败: %v", err)
+		return nil, fmt.Errorf("消息解密失败: %w", err)
 	}
 
 	return []byte(decryptedMsg), nil
@@ -674,88 +1381,79 @@ func (c *Client) verifySignature(signature, timestamp, nonce, encryptedMsg strin
 	return nil
 }
 
-// QueryAuth 使用授权码换取授权信息
-func (c *Client) QueryAuth(ctx context.Context, authorizationCode string) (*QueryAuthResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
+// Token 实现 core.Caller 接口，返回第三方平台的component_access_token
+// 第三方平台接口中名为access_token的参数同样携带component_access_token
+func (c *Client) Token(ctx context.Context, param string) (string, error) {
+	switch param {
+	case core.ParamComponentAccessToken, core.ParamAccessToken:
+		token, err := c.GetComponentAccessToken(ctx, "")
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	default:
+		return "", fmt.Errorf("第三方平台接口不支持 %s", param)
 	}
+}
+
+// Make 实现 core.Caller 接口，使用客户端配置的请求管道发送请求
+func (c *Client) Make(ctx context.Context, options *core.ReqMakeOpt) error {
+	return c.req.Make(ctx, options)
+}
 
+// Download 实现 core.Downloader 接口，使用客户端配置的请求管道下载
+func (c *Client) Download(ctx context.Context, options *core.ReqMakeOpt) (*core.Download, error) {
+	return c.req.Download(ctx, options)
+}
+
+// QueryAuth 使用授权码换取授权信息
+func (c *Client) QueryAuth(ctx context.Context, authorizationCode string) (*QueryAuthResponse, error) {
 	request := QueryAuthRequest{
 		ComponentAppID:    c.config.ComponentAppID,
 		AuthorizationCode: authorizationCode,
 	}
 
-	var result QueryAuthResponse
-	queryAuthUrl := fmt.Sprintf("%s?component_access_token=%s", URLQueryAuth, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    queryAuthUrl,
-		Body:   request,
-		Result: &result,
-	})
+	start := time.Now()
+	result, err := core.Call(ctx, c, endpointQueryAuth, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	// 缓存授权方token
-	if err := c.SetAuthorizerToken(
+	if err := c.saveAuthorizerToken(ctx,
 		result.AuthorizationInfo.AuthorizerAppID,
 		result.AuthorizationInfo.AuthorizerAccessToken,
 		result.AuthorizationInfo.AuthorizerRefreshToken,
 		result.AuthorizationInfo.ExpiresIn,
+		start,
 	); err != nil {
 		c.logger.Warn(fmt.Sprintf("缓存授权方token失败: %v", err))
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // RefreshAuthorizerToken 刷新授权方access_token
 func (c *Client) RefreshAuthorizerToken(ctx context.Context, authorizerAppID, refreshToken string) (*AuthorizationInfo, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := AuthorizerTokenRequest{
 		ComponentAppID:         c.config.ComponentAppID,
 		AuthorizerAppID:        authorizerAppID,
 		AuthorizerRefreshToken: refreshToken,
 	}
 
-	var result struct {
-		core.APIResponse
-		AuthorizationInfo
-	}
-
-	apiURL := fmt.Sprintf("%s?component_access_token=%s", URLAuthorizerToken,
-		url.QueryEscape(componentToken.AccessToken))
-
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    apiURL,
-		Body:   request,
-		Result: &result,
-	})
+	start := time.Now()
+	result, err := core.Call(ctx, c, endpointAuthorizerToken, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
 	// 更新缓存
-	if err := c.SetAuthorizerToken(
+	if err := c.saveAuthorizerToken(ctx,
 		authorizerAppID,
 		result.AuthorizerAccessToken,
 		result.AuthorizerRefreshToken,
 		result.ExpiresIn,
+		start,
 	); err != nil {
 		c.logger.Warn(fmt.Sprintf("更新授权方token失败: %v", err))
 	}
@@ -765,65 +1463,23 @@ func (c *Client) RefreshAuthorizerToken(ctx context.Context, authorizerAppID, re
 
 // GetAuthorizerInfo 获取授权方信息
 func (c *Client) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*GetAuthorizerInfoResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetAuthorizerInfoRequest{
 		ComponentAppID:  c.config.ComponentAppID,
 		AuthorizerAppID: authorizerAppID,
 	}
 
-	var result GetAuthorizerInfoResponse
-	getAuthorizerInfoUrl := fmt.Sprintf("%s?component_access_token=%s", URLGetAuthorizerInfo, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    getAuthorizerInfoUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetAuthorizerInfo, request)
 }
 
 // GetAuthorizerList 获取授权方列表
 func (c *Client) GetAuthorizerList(ctx context.Context, offset, count int) (*GetAuthorizerListResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetAuthorizerListRequest{
 		ComponentAppID: c.config.ComponentAppID,
 		Offset:         offset,
 		Count:          count,
 	}
 
-	var result GetAuthorizerListResponse
-	getAuthorizerListUrl := fmt.Sprintf("%s?component_access_token=%s", URLGetAuthorizerList, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    getAuthorizerListUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetAuthorizerList, request)
 }
 
 // GetAllAuthorizers 获取所有授权方列表，自动处理分页
@@ -834,30 +1490,22 @@ func (c *Client) GetAllAuthorizers(ctx context.Context) (allAuthorizers []struct
 	RefreshToken    string `json:"refresh_token,omitempty"` // 虽然文档写了，但是实际上获取不到
 	AuthTime        int64  `json:"auth_time"`
 }, err error) {
-	const (
-		pageSize = 500 // 每页获取的数量，微信API最大支持500
-		maxRetry = 3   // 最大重试次数
-	)
+	const pageSize = 500 // 每页获取的数量，微信API最大支持500
 
+	// 查询授权方列表是只读操作，按幂等请求重试
+	policy := c.retryPolicyOrDefault()
 	offset := 0
 
 	for {
 		var response *GetAuthorizerListResponse
 
-		// 重试机制
-		for retry := 0; retry < maxRetry; retry++ {
+		err = policy.Do(ctx, "GET", func() error {
+			var err error
 			response, err = c.GetAuthorizerList(ctx, offset, pageSize)
-			if err == nil {
-				break
-			}
-
-			// 如果是最后一次重试仍然失败，则返回错误
-			if retry == maxRetry-1 {
-				return nil, fmt.Errorf("获取授权方列表失败，重试%d次后仍然失败: %v", maxRetry, err)
-			}
-
-			// 等待一段时间后重试
-			time.Sleep(time.Duration(retry+1) * time.Second)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取授权方列表失败: %w", err)
 		}
 
 		// 检查响应是否有效
@@ -949,93 +1597,56 @@ func (c *Client) GenerateMobileAuthURL(preAuthCode string, authType int, bizAppI
 
 // ClearQuota 重置API调用次数
 func (c *Client) ClearQuota(ctx context.Context) (*core.APIResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := ClearQuotaRequest{
 		ComponentAppID: c.config.ComponentAppID,
 	}
 
-	var result core.APIResponse
-	clearQuotaUrl := fmt.Sprintf("%s?access_token=%s", URLClearQuota, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    clearQuotaUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointClearQuota, request)
 }
 
 // GetApiQuota 查询API调用额度
 func (c *Client) GetApiQuota(ctx context.Context, authorizerAppID string) (*GetApiQuotaResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetApiQuotaRequest{
 		ComponentAppID:  c.config.ComponentAppID,
 		AuthorizerAppID: authorizerAppID,
 	}
 
-	var result GetApiQuotaResponse
-	getApiQuotaUrl := fmt.Sprintf("%s?access_token=%s", URLGetApiQuota, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    getApiQuotaUrl,
-		Body:   request,
-		Result: &result,
-	})
+	return core.Call(ctx, c, endpointGetApiQuota, request)
+}
+
+// SyncApiQuota 查询授权方的API调用额度并同步到限流器
+// 未设置限流器时仅查询额度，可定时调用以保持本地计数与微信一致
+// @param ctx context.Context 上下文
+// @param authorizerAppID string 授权方appid
+// @return *GetApiQuotaResponse 额度查询结果
+// @return error 错误信息
+func (c *Client) SyncApiQuota(ctx context.Context, authorizerAppID string) (*GetApiQuotaResponse, error) {
+	result, err := c.GetApiQuota(ctx, authorizerAppID)
 	if err != nil {
 		return nil, err
 	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
+	if c.rateLimiter == nil {
+		return result, nil
 	}
 
-	return &result, nil
+	for _, item := range result.Quota {
+		if item.API == "" || item.DailyQuota <= 0 {
+			continue
+		}
+		if err := c.rateLimiter.SyncDaily(ctx, authorizerAppID, item.API, item.DailyQuota, item.DailyUsed); err != nil {
+			return result, fmt.Errorf("同步接口%s的调用额度失败: %w", item.API, err)
+		}
+	}
+	return result, nil
 }
 
 // GetRidInfo 查询rid信息
 func (c *Client) GetRidInfo(ctx context.Context, rid string) (*GetRidInfoResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetRidInfoRequest{
 		RID: rid,
 	}
 
-	var result GetRidInfoResponse
-	getRidInfoUrl := fmt.Sprintf("%s?access_token=%s", URLGetRidInfo, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    getRidInfoUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetRidInfo, request)
 }
 
 // ClearComponentQuota 使用AppSecret重置第三方平台API调用次数
@@ -1046,7 +1657,7 @@ func (c *Client) ClearComponentQuota(ctx context.Context) (*core.APIResponse, er
 	}
 
 	var result core.APIResponse
-	err := core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
+	err := c.req.Make(ctx, &core.ReqMakeOpt{
 		Method: "POST",
 		URL:    URLClearComponentQuota,
 		Body:   request,
@@ -1056,20 +1667,11 @@ func (c *Client) ClearComponentQuota(ctx context.Context) (*core.APIResponse, er
 		return nil, err
 	}
 
-	if !result.IsSuccess() {
-		return nil, &result
-	}
-
 	return &result, nil
 }
 
 // SetAuthorizerOption 设置授权方选项信息
 func (c *Client) SetAuthorizerOption(ctx context.Context, authorizerAppID, optionName, optionValue string) (*core.APIResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := SetAuthorizerOptionRequest{
 		ComponentAppID:  c.config.ComponentAppID,
 		AuthorizerAppID: authorizerAppID,
@@ -1077,164 +1679,45 @@ func (c *Client) SetAuthorizerOption(ctx context.Context, authorizerAppID, optio
 		OptionValue:     optionValue,
 	}
 
-	var result core.APIResponse
-	setAuthorizerOptionUrl := fmt.Sprintf("%s?component_access_token=%s", URLSetAuthorizerOption, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    setAuthorizerOptionUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointSetAuthorizerOption, request)
 }
 
 // GetAuthorizerOption 获取授权方选项信息
 func (c *Client) GetAuthorizerOption(ctx context.Context, authorizerAppID, optionName string) (*GetAuthorizerOptionResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := GetAuthorizerOptionRequest{
 		ComponentAppID:  c.config.ComponentAppID,
 		AuthorizerAppID: authorizerAppID,
 		OptionName:      optionName,
 	}
 
-	var result GetAuthorizerOptionResponse
-	getAuthorizerOptionUrl := fmt.Sprintf("%s?component_access_token=%s", URLGetAuthorizerOption, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    getAuthorizerOptionUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetAuthorizerOption, request)
 }
 
 // GetTemplateDraftList 获取草稿箱列表
 func (c *Client) GetTemplateDraftList(ctx context.Context) (*GetTemplateDraftListResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetTemplateDraftListResponse
-	getTemplateDraftListUrl := fmt.Sprintf("%s?access_token=%s", URLWxaGetTemplateDraftList, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    getTemplateDraftListUrl,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetTemplateDraftList, core.NoParams{})
 }
 
 // AddToTemplate 将草稿添加到模板库
 func (c *Client) AddToTemplate(ctx context.Context, draftID int64, templateType int) (*core.APIResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := AddToTemplateRequest{
 		DraftID:      draftID,
 		TemplateType: templateType,
 	}
 
-	var result core.APIResponse
-	addToTemplateUrl := fmt.Sprintf("%s?access_token=%s", URLWxaAddToTemplate, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    addToTemplateUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointAddToTemplate, request)
 }
 
 // GetTemplateList 获取模板列表
 func (c *Client) GetTemplateList(ctx context.Context) (*GetTemplateListResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
-	var result GetTemplateListResponse
-	getTemplateListUrl := fmt.Sprintf("%s?access_token=%s", URLWxaGetTemplateList, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "GET",
-		URL:    getTemplateListUrl,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result.APIResponse
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointGetTemplateList, core.NoParams{})
 }
 
 // DeleteTemplate 删除代码模板
 func (c *Client) DeleteTemplate(ctx context.Context, templateID int64) (*core.APIResponse, error) {
-	componentToken, err := c.GetComponentAccessToken(ctx, "")
-	if err != nil {
-		return nil, err
-	}
-
 	request := DeleteTemplateRequest{
 		TemplateID: templateID,
 	}
 
-	var result core.APIResponse
-	deleteTemplateUrl := fmt.Sprintf("%s?access_token=%s", URLWxaDeleteTemplate, url.QueryEscape(componentToken.AccessToken))
-	err = core.NewRequest(c.httpClient, c.logger).Make(ctx, &core.ReqMakeOpt{
-		Method: "POST",
-		URL:    deleteTemplateUrl,
-		Body:   request,
-		Result: &result,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if !result.IsSuccess() {
-		return nil, &result
-	}
-
-	return &result, nil
+	return core.Call(ctx, c, endpointDeleteTemplate, request)
 }
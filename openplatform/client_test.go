@@ -0,0 +1,68 @@
+package openplatform_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jcbowen/wego/openplatform"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestClientScopesStorageAndReadsLegacyData(t *testing.T) {
+	server := wegotest.NewServer().
+		AddComponent("wx_component", "secret").
+		AddComponent("wx_other", "secret").
+		SetVerifyTicket("wx_component", "ticket").
+		SetVerifyTicket("wx_other", "other_ticket")
+	defer server.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 升级前的推送接收服务将验证票据写入默认命名空间
+	if err := store.SaveComponentVerifyTicket(ctx, "ticket"); err != nil {
+		t.Fatal(err)
+	}
+	client := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_component", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	token, err := client.GetComponentAccessToken(ctx, "")
+	if err != nil {
+		t.Fatalf("get component access token with legacy ticket: %v", err)
+	}
+	if n := len(server.Requests("/cgi-bin/component/api_start_push_ticket")); n != 0 {
+		t.Fatalf("legacy ticket should be used without a push, got %d push requests", n)
+	}
+
+	// 新数据只写入命名空间
+	scoped, err := storage.Scope(store, storage.ComponentNamespace("wx_component"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := scoped.GetComponentToken(ctx); err != nil || stored == nil || stored.AccessToken != token.AccessToken {
+		t.Fatalf("component token should be saved in the namespace, got %+v, %v", stored, err)
+	}
+	if stored, err := store.GetComponentToken(ctx); err != nil || stored != nil {
+		t.Fatalf("default namespace should not be written, got %+v, %v", stored, err)
+	}
+
+	// 另一个第三方平台的验证票据和令牌互不影响
+	other := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_other", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	if err := other.SaveComponentVerifyTicket(ctx, "other_ticket"); err != nil {
+		t.Fatal(err)
+	}
+	otherToken, err := other.GetComponentAccessToken(ctx, "")
+	if err != nil {
+		t.Fatalf("get other component access token: %v", err)
+	}
+	if otherToken.AccessToken == token.AccessToken {
+		t.Fatal("component tokens should not be shared between components")
+	}
+	if ticket, err := client.GetComponentVerifyTicket(ctx); err != nil || ticket == nil || ticket.Ticket != "ticket" {
+		t.Fatalf("unexpected verify ticket: %+v, %v", ticket, err)
+	}
+}
@@ -69,3 +69,12 @@ const (
 	// AuthTypeLiveStreamingAssistant 带货助手账号
 	AuthTypeLiveStreamingAssistant = 8
 )
+
+// 授权方ticket类型
+const (
+	// TicketTypeJSAPI JS-SDK使用的jsapi_ticket
+	TicketTypeJSAPI = "jsapi"
+
+	// TicketTypeWxCard 卡券使用的api_ticket
+	TicketTypeWxCard = "wx_card"
+)
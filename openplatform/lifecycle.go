@@ -0,0 +1,292 @@
+package openplatform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/jcbowen/wego/storage"
+)
+
+// AuthorizerGrantedEvent 授权方完成授权，授权信息已保存
+type AuthorizerGrantedEvent struct {
+	AuthorizerAppID   string
+	AuthorizationInfo *AuthorizationInfo // 授权码换取的授权信息，包含token和权限集
+	AuthorizerInfo    *AuthorizerInfo    // 授权方账号信息，获取失败时为nil
+	Event             *AuthorizedEvent   // 微信推送的授权成功事件
+}
+
+// AuthorizerUpdatedEvent 授权方更新授权，新的授权信息已保存
+type AuthorizerUpdatedEvent struct {
+	AuthorizerAppID   string
+	AuthorizationInfo *AuthorizationInfo     // 授权码换取的授权信息，包含token和新的权限集
+	AuthorizerInfo    *AuthorizerInfo        // 授权方账号信息，获取失败时为nil
+	PrevFuncScopeIDs  []int                  // 更新前的权限集id，没有授权记录时为nil
+	Event             *UpdateAuthorizedEvent // 微信推送的授权更新事件
+}
+
+// AuthorizerRevokedEvent 授权方取消授权，授权方的凭证已清除
+type AuthorizerRevokedEvent struct {
+	AuthorizerAppID string
+	Event           *UnauthorizedEvent // 微信推送的取消授权事件
+}
+
+// LifecycleHandler 授权生命周期事件处理器，实现 EventHandler 接口
+//
+// 授权成功和授权更新时使用授权码换取并保存授权方token，保存授权的权限集和授权方账号信息；
+// 取消授权时调用 Client.PurgeAuthorizer 清除该授权方的凭证。处理完成后发布对应的领域事件，
+// 再调用next处理原始事件。需要通过 Client.SetEventHandler 显式启用：
+//
+//	handler := openplatform.NewLifecycleHandler(client, nil).
+//		OnGranted(func(ctx context.Context, event *openplatform.AuthorizerGrantedEvent) error {
+//			return nil
+//		})
+//	client.SetEventHandler(handler)
+type LifecycleHandler struct {
+	client *Client
+	next   EventHandler
+
+	mu        sync.RWMutex
+	onGranted []func(ctx context.Context, event *AuthorizerGrantedEvent) error
+	onUpdated []func(ctx context.Context, event *AuthorizerUpdatedEvent) error
+	onRevoked []func(ctx context.Context, event *AuthorizerRevokedEvent) error
+}
+
+// NewLifecycleHandler 创建授权生命周期事件处理器
+// @param client *Client 第三方平台客户端
+// @param next EventHandler 处理原始事件的处理器，为nil时使用 DefaultEventHandler
+// @return *LifecycleHandler 事件处理器
+func NewLifecycleHandler(client *Client, next EventHandler) *LifecycleHandler {
+	if next == nil {
+		next = &DefaultEventHandler{}
+	}
+	return &LifecycleHandler{client: client, next: next}
+}
+
+// OnGranted 订阅授权成功事件
+// @return *LifecycleHandler 事件处理器，便于链式调用
+func (h *LifecycleHandler) OnGranted(fn func(ctx context.Context, event *AuthorizerGrantedEvent) error) *LifecycleHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onGranted = append(h.onGranted, fn)
+	return h
+}
+
+// OnUpdated 订阅授权更新事件
+// @return *LifecycleHandler 事件处理器，便于链式调用
+func (h *LifecycleHandler) OnUpdated(fn func(ctx context.Context, event *AuthorizerUpdatedEvent) error) *LifecycleHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onUpdated = append(h.onUpdated, fn)
+	return h
+}
+
+// OnRevoked 订阅取消授权事件
+// @return *LifecycleHandler 事件处理器，便于链式调用
+func (h *LifecycleHandler) OnRevoked(fn func(ctx context.Context, event *AuthorizerRevokedEvent) error) *LifecycleHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.onRevoked = append(h.onRevoked, fn)
+	return h
+}
+
+// HandleAuthorized 实现 EventHandler 接口，保存授权信息后发布 AuthorizerGrantedEvent
+func (h *LifecycleHandler) HandleAuthorized(ctx context.Context, event *AuthorizedEvent) error {
+	authorization, authorizerInfo, _, err := h.persist(ctx, event.AuthorizerAppid, event.AuthorizationCode)
+	if err == nil {
+		h.mu.RLock()
+		listeners := h.onGranted
+		h.mu.RUnlock()
+
+		granted := &AuthorizerGrantedEvent{
+			AuthorizerAppID:   authorization.AuthorizerAppID,
+			AuthorizationInfo: authorization,
+			AuthorizerInfo:    authorizerInfo,
+			Event:             event,
+		}
+		for _, fn := range listeners {
+			err = errors.Join(err, fn(ctx, granted))
+		}
+	}
+	return errors.Join(err, h.next.HandleAuthorized(ctx, event))
+}
+
+// HandleUpdateAuthorized 实现 EventHandler 接口，保存新的授权信息后发布 AuthorizerUpdatedEvent
+func (h *LifecycleHandler) HandleUpdateAuthorized(ctx context.Context, event *UpdateAuthorizedEvent) error {
+	authorization, authorizerInfo, prevScopeIDs, err := h.persist(ctx, event.AuthorizerAppid, event.AuthorizationCode)
+	if err == nil {
+		h.mu.RLock()
+		listeners := h.onUpdated
+		h.mu.RUnlock()
+
+		updated := &AuthorizerUpdatedEvent{
+			AuthorizerAppID:   authorization.AuthorizerAppID,
+			AuthorizationInfo: authorization,
+			AuthorizerInfo:    authorizerInfo,
+			PrevFuncScopeIDs:  prevScopeIDs,
+			Event:             event,
+		}
+		for _, fn := range listeners {
+			err = errors.Join(err, fn(ctx, updated))
+		}
+	}
+	return errors.Join(err, h.next.HandleUpdateAuthorized(ctx, event))
+}
+
+// HandleUnauthorized 实现 EventHandler 接口，清除授权方凭证后发布 AuthorizerRevokedEvent
+// 部分凭证清除失败时仍会发布事件，授权已经失效
+func (h *LifecycleHandler) HandleUnauthorized(ctx context.Context, event *UnauthorizedEvent) error {
+	err := h.client.PurgeAuthorizer(ctx, event.AuthorizerAppid)
+
+	h.mu.RLock()
+	listeners := h.onRevoked
+	h.mu.RUnlock()
+
+	revoked := &AuthorizerRevokedEvent{AuthorizerAppID: event.AuthorizerAppid, Event: event}
+	for _, fn := range listeners {
+		err = errors.Join(err, fn(ctx, revoked))
+	}
+	return errors.Join(err, h.next.HandleUnauthorized(ctx, event))
+}
+
+// HandleComponentVerifyTicket 实现 EventHandler 接口，交给next处理
+func (h *LifecycleHandler) HandleComponentVerifyTicket(ctx context.Context, event *ComponentVerifyTicketEvent) error {
+	return h.next.HandleComponentVerifyTicket(ctx, event)
+}
+
+// HandleEncodingAESKeyChanged 实现 EventHandler 接口，交给next处理
+func (h *LifecycleHandler) HandleEncodingAESKeyChanged(ctx context.Context, event *EncodingAESKeyChangedEvent) error {
+	return h.next.HandleEncodingAESKeyChanged(ctx, event)
+}
+
+// persist 使用授权码换取并保存授权方token，获取授权方账号信息并保存授权记录
+// 返回授权信息、授权方账号信息（获取失败时为nil）和之前保存的权限集id
+func (h *LifecycleHandler) persist(ctx context.Context, authorizerAppID, authorizationCode string) (*AuthorizationInfo, *AuthorizerInfo, []int, error) {
+	if authorizationCode == "" {
+		return nil, nil, nil, fmt.Errorf("授权方 %s 的授权事件缺少授权码", authorizerAppID)
+	}
+
+	// QueryAuth 会保存授权方的access_token和refresh_token
+	result, err := h.client.QueryAuth(ctx, authorizationCode)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("使用授权码换取授权信息失败: %w", err)
+	}
+	authorization := &result.AuthorizationInfo
+	if authorization.AuthorizerAppID == "" {
+		authorization.AuthorizerAppID = authorizerAppID
+	}
+
+	var authorizerInfo *AuthorizerInfo
+	var rawInfo json.RawMessage
+	if info, err := h.client.GetAuthorizerInfo(ctx, authorization.AuthorizerAppID); err != nil {
+		h.client.logger.Warn(fmt.Sprintf("获取授权方 %s 的账号信息失败: %v", authorization.AuthorizerAppID, err))
+	} else {
+		authorizerInfo = &info.AuthorizerInfo
+		if rawInfo, err = json.Marshal(authorizerInfo); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	prev, err := h.client.GetStoredAuthorizerInfo(ctx, authorization.AuthorizerAppID)
+	if err != nil && !errors.Is(err, storage.ErrAuthorizerInfoNotSupported) {
+		return nil, nil, nil, fmt.Errorf("读取授权记录失败: %w", err)
+	}
+
+	now := time.Now()
+	record := &storage.AuthorizerInfo{
+		AuthorizerAppID: authorization.AuthorizerAppID,
+		FuncScopeIDs:    authorization.FuncScopeIDs(),
+		Info:            rawInfo,
+		AuthorizedAt:    now,
+		UpdatedAt:       now,
+	}
+	var prevScopeIDs []int
+	if prev != nil {
+		record.AuthorizedAt = prev.AuthorizedAt
+		if record.Info == nil {
+			record.Info = prev.Info
+		}
+		prevScopeIDs = prev.FuncScopeIDs
+	}
+	if err := h.client.saveAuthorizerInfo(ctx, record); err != nil {
+		if !errors.Is(err, storage.ErrAuthorizerInfoNotSupported) {
+			return nil, nil, nil, fmt.Errorf("保存授权记录失败: %w", err)
+		}
+		h.client.logger.Warn("存储不支持授权记录，只保存授权方token")
+	}
+
+	return authorization, authorizerInfo, prevScopeIDs, nil
+}
+
+// GetStoredAuthorizerInfo 获取 LifecycleHandler 保存的授权方授权记录
+// @param ctx context.Context 上下文
+// @param authorizerAppID string 授权方appid
+// @return *storage.AuthorizerInfo 授权记录，不存在时返回nil
+// @return error 错误信息，存储不支持时返回 storage.ErrAuthorizerInfoNotSupported
+func (c *Client) GetStoredAuthorizerInfo(ctx context.Context, authorizerAppID string) (*storage.AuthorizerInfo, error) {
+	infos, ok := c.storage.(storage.AuthorizerInfoStorage)
+	if !ok {
+		return nil, storage.ErrAuthorizerInfoNotSupported
+	}
+	return infos.GetAuthorizerInfo(ctx, authorizerAppID)
+}
+
+// saveAuthorizerInfo 保存授权方授权记录
+func (c *Client) saveAuthorizerInfo(ctx context.Context, info *storage.AuthorizerInfo) error {
+	infos, ok := c.storage.(storage.AuthorizerInfoStorage)
+	if !ok {
+		return storage.ErrAuthorizerInfoNotSupported
+	}
+	return infos.SaveAuthorizerInfo(ctx, info.AuthorizerAppID, info)
+}
+
+// PurgeAuthorizer 清除授权方的凭证，用于授权方取消授权后
+// 删除存储中的access_token、refresh_token、授权记录和上一次的EncodingAESKey，以及缓存的jsapi_ticket和卡券ticket，
+// 删除后 core.Refresher 不再刷新该授权方的token。ticket只缓存在各实例内存中，其他实例在 GetTicket 或 RefreshTargets 时
+// 发现存储中的授权方token已删除，清除该授权方的ticket。不存在的凭证会被忽略，部分删除失败时返回合并的错误
+// @param ctx context.Context 上下文
+// @param authorizerAppID string 授权方appid
+// @return error 错误信息
+func (c *Client) PurgeAuthorizer(ctx context.Context, authorizerAppID string) error {
+	c.dropTickets(authorizerAppID)
+
+	var errs []error
+	if err := c.storage.DeleteAuthorizerToken(ctx, authorizerAppID); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("删除授权方token失败: %w", err))
+	}
+	if err := c.purgeLegacyAuthorizerToken(ctx, authorizerAppID); err != nil {
+		errs = append(errs, fmt.Errorf("删除升级前的授权方token失败: %w", err))
+	}
+	if err := c.storage.DeletePrevEncodingAESKey(ctx, authorizerAppID); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("删除上一次EncodingAESKey失败: %w", err))
+	}
+	if infos, ok := c.storage.(storage.AuthorizerInfoStorage); ok {
+		if err := infos.DeleteAuthorizerInfo(ctx, authorizerAppID); err != nil && !errors.Is(err, storage.ErrAuthorizerInfoNotSupported) {
+			errs = append(errs, fmt.Errorf("删除授权记录失败: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purgeLegacyAuthorizerToken 删除默认命名空间中升级前保存的授权方token，避免清除后仍被 getAuthorizerToken 读取
+// 没有refresh_token的是公众号自身的token，保留
+func (c *Client) purgeLegacyAuthorizerToken(ctx context.Context, authorizerAppID string) error {
+	if c.legacyStorage == nil {
+		return nil
+	}
+	token, err := c.legacyStorage.GetAuthorizerToken(ctx, authorizerAppID)
+	if err != nil || token == nil || token.AuthorizerRefreshToken == "" {
+		return err
+	}
+	if err := c.legacyStorage.DeleteAuthorizerToken(ctx, authorizerAppID); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
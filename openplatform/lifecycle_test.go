@@ -0,0 +1,120 @@
+package openplatform_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jcbowen/wego/openplatform"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestLifecycleHandlerPersistsAndPurgesAuthorizer(t *testing.T) {
+	server := wegotest.NewServer().AddComponent("wx_component", "secret")
+	defer server.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_component", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	ctx := context.Background()
+	if err := client.SaveComponentVerifyTicket(ctx, "ticket"); err != nil {
+		t.Fatal(err)
+	}
+
+	var granted *openplatform.AuthorizerGrantedEvent
+	var updated *openplatform.AuthorizerUpdatedEvent
+	var revoked *openplatform.AuthorizerRevokedEvent
+	handler := openplatform.NewLifecycleHandler(client, nil).
+		OnGranted(func(ctx context.Context, event *openplatform.AuthorizerGrantedEvent) error {
+			granted = event
+			return nil
+		}).
+		OnUpdated(func(ctx context.Context, event *openplatform.AuthorizerUpdatedEvent) error {
+			updated = event
+			return nil
+		}).
+		OnRevoked(func(ctx context.Context, event *openplatform.AuthorizerRevokedEvent) error {
+			revoked = event
+			return nil
+		})
+
+	event := openplatform.AuthorizationEvent{AppId: "wx_component", AuthorizerAppid: "wx_auth"}
+
+	event.InfoType, event.AuthorizationCode = openplatform.InfoTypeAuthorized, server.Authorize("wx_auth", 1, 15)
+	if err := handler.HandleAuthorized(ctx, &openplatform.AuthorizedEvent{AuthorizationEvent: event}); err != nil {
+		t.Fatalf("handle authorized: %v", err)
+	}
+	if granted == nil || granted.AuthorizerInfo == nil || granted.AuthorizerInfo.UserName != "gh_wx_auth" {
+		t.Fatalf("unexpected granted event: %+v", granted)
+	}
+	if _, err := client.GetAuthorizerAccessToken(ctx, "wx_auth"); err != nil {
+		t.Fatalf("authorizer token not stored: %v", err)
+	}
+
+	// 另外两个实例使用同一存储，缓存了授权方的jsapi_ticket，其中standby没有运行 core.Refresher
+	other := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_component", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	standby := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_component", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	for _, instance := range []*openplatform.Client{other, standby} {
+		if _, err := openplatform.NewAuthClient(instance).NewAuthorizerClient("wx_auth").GetTicket(ctx, openplatform.TicketTypeJSAPI); err != nil {
+			t.Fatalf("get ticket: %v", err)
+		}
+	}
+
+	event.InfoType, event.AuthorizationCode = openplatform.InfoTypeUpdateAuthorized, server.Authorize("wx_auth", 1)
+	if err := handler.HandleUpdateAuthorized(ctx, &openplatform.UpdateAuthorizedEvent{AuthorizationEvent: event}); err != nil {
+		t.Fatalf("handle update authorized: %v", err)
+	}
+	if updated == nil || !reflect.DeepEqual(updated.PrevFuncScopeIDs, []int{1, 15}) {
+		t.Fatalf("unexpected updated event: %+v", updated)
+	}
+	record, err := client.GetStoredAuthorizerInfo(ctx, "wx_auth")
+	if err != nil || record == nil || !reflect.DeepEqual(record.FuncScopeIDs, []int{1}) || len(record.Info) == 0 {
+		t.Fatalf("unexpected authorizer record: %+v, %v", record, err)
+	}
+
+	// 客户端的数据保存在第三方平台的命名空间
+	component, err := storage.Scope(store, storage.ComponentNamespace("wx_component"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := component.SavePrevEncodingAESKey(ctx, "wx_auth", "prev_key"); err != nil {
+		t.Fatal(err)
+	}
+	server.Unauthorize("wx_auth")
+	event.InfoType, event.AuthorizationCode = openplatform.InfoTypeUnauthorized, ""
+	if err := handler.HandleUnauthorized(ctx, &openplatform.UnauthorizedEvent{AuthorizationEvent: event}); err != nil {
+		t.Fatalf("handle unauthorized: %v", err)
+	}
+	if revoked == nil || revoked.AuthorizerAppID != "wx_auth" {
+		t.Fatalf("unexpected revoked event: %+v", revoked)
+	}
+	if token, _ := component.GetAuthorizerToken(ctx, "wx_auth"); token != nil {
+		t.Fatal("authorizer token should be purged")
+	}
+	if key, _ := component.GetPrevEncodingAESKey(ctx, "wx_auth"); key != nil {
+		t.Fatal("previous encoding aes key should be purged")
+	}
+	if record, _ := client.GetStoredAuthorizerInfo(ctx, "wx_auth"); record != nil {
+		t.Fatal("authorizer record should be purged")
+	}
+	if ticket, err := openplatform.NewAuthClient(standby).NewAuthorizerClient("wx_auth").GetTicket(ctx, openplatform.TicketTypeJSAPI); err == nil {
+		t.Fatalf("purged authorizer should not get cached ticket: %s", ticket)
+	}
+	for _, instance := range []*openplatform.Client{client, other} {
+		targets, err := instance.RefreshTargets(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, target := range targets {
+			if target.AppID == "wx_auth" {
+				t.Fatalf("purged authorizer should not be refreshed: %+v", target)
+			}
+		}
+	}
+}
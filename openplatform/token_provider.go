@@ -0,0 +1,35 @@
+package openplatform
+
+import (
+	"context"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// SelfTokenProvider 使用存储中的authorizer_refresh_token调用微信接口获取和刷新授权方access_token，
+// 是未设置token来源时的默认行为
+type SelfTokenProvider struct {
+	client *Client
+}
+
+// NewSelfTokenProvider 创建由客户端自行刷新的授权方token来源
+// @param client *Client 第三方平台客户端
+// @return *SelfTokenProvider token来源
+func NewSelfTokenProvider(client *Client) *SelfTokenProvider {
+	return &SelfTokenProvider{client: client}
+}
+
+// AccessToken 实现 core.TokenProvider 接口
+func (p *SelfTokenProvider) AccessToken(ctx context.Context, authorizerAppID string) (string, error) {
+	return p.client.getSelfAuthorizerAccessToken(ctx, authorizerAppID)
+}
+
+// RefreshAccessToken 实现 core.TokenProvider 接口
+func (p *SelfTokenProvider) RefreshAccessToken(ctx context.Context, authorizerAppID, staleToken string) (string, error) {
+	return p.client.refreshStaleAuthorizerToken(ctx, authorizerAppID, staleToken)
+}
+
+// RefreshTargets 实现 core.RefreshSource 接口
+func (p *SelfTokenProvider) RefreshTargets(ctx context.Context) ([]core.RefreshTarget, error) {
+	return p.client.selfRefreshTargets(ctx)
+}
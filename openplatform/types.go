@@ -34,6 +34,15 @@ type AuthorizationInfo struct {
 	FuncInfo               []FuncInfo `json:"func_info"`
 }
 
+// FuncScopeIDs 返回授权的权限集id
+func (i *AuthorizationInfo) FuncScopeIDs() []int {
+	ids := make([]int, 0, len(i.FuncInfo))
+	for _, info := range i.FuncInfo {
+		ids = append(ids, info.FuncScopeCategory.Id)
+	}
+	return ids
+}
+
 // FuncScopeCategory 授权给开发者的权限集
 type FuncScopeCategory struct {
 	Id int `json:"id"`
@@ -0,0 +1,198 @@
+package openplatform
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/storage"
+)
+
+// 验证票据推送的默认参数
+const (
+	DefaultVerifyTicketWait = 15 * time.Second // 验证票据不存在时触发推送后等待票据到达的时长
+	DefaultVerifyTicketLead = time.Hour        // 验证票据剩余有效期小于该时长时在后台触发推送
+
+	verifyTicketPushInterval = time.Minute // 两次触发推送的最小间隔
+	verifyTicketPollInterval = time.Second // 等待期间检查存储的间隔，由其他实例接收推送时通过存储获知
+)
+
+var endpointStartPushTicket = core.Endpoint[StartPushTicketRequest, core.APIResponse]{Method: "POST", URL: URLStartPushTicket}
+
+// StartPushTicketRequest 启动验证票据推送请求参数
+type StartPushTicketRequest struct {
+	ComponentAppID  string `json:"component_appid"`
+	ComponentSecret string `json:"component_secret"`
+}
+
+// VerifyTicketStatus 验证票据状态，用于健康检查
+// 微信每10分钟推送一次验证票据，Age 持续超过10分钟说明推送没有送达
+type VerifyTicketStatus struct {
+	Present   bool          // 存储中是否有未过期的验证票据
+	CreatedAt time.Time     // 收到验证票据的时间
+	ExpiresAt time.Time     // 验证票据的过期时间
+	Age       time.Duration // 距收到验证票据的时长，没有验证票据时为0
+	Stale     bool          // 验证票据不存在、已过期或剩余有效期小于提前推送时长
+}
+
+// SetVerifyTicketWait 设置验证票据不存在或已过期时，触发推送后等待票据到达的时长，默认 DefaultVerifyTicketWait
+// 为0时不自动触发推送，获取component_access_token直接返回错误
+func (c *Client) SetVerifyTicketWait(wait time.Duration) {
+	if wait < 0 {
+		wait = 0
+	}
+	c.verifyTicketWait = wait
+}
+
+// SetVerifyTicketLead 设置验证票据剩余有效期小于多久时在后台触发推送，默认 DefaultVerifyTicketLead
+func (c *Client) SetVerifyTicketLead(lead time.Duration) {
+	if lead > 0 {
+		c.verifyTicketLead = lead
+	}
+}
+
+// StartPushTicket 请求微信立即推送验证票据
+// 票据通过 HandleAuthorizationEvent 接收并保存，本方法不等待票据到达
+// @param ctx context.Context 上下文
+// @return error 错误信息
+func (c *Client) StartPushTicket(ctx context.Context) error {
+	request := StartPushTicketRequest{
+		ComponentAppID:  c.config.ComponentAppID,
+		ComponentSecret: c.config.ComponentAppSecret,
+	}
+
+	c.verifyTicketMu.Lock()
+	c.lastPushTicket = time.Now()
+	c.verifyTicketMu.Unlock()
+
+	if _, err := core.Call(ctx, c, endpointStartPushTicket, request); err != nil {
+		return fmt.Errorf("启动验证票据推送失败: %w", err)
+	}
+	return nil
+}
+
+// GetVerifyTicketStatus 获取验证票据状态
+// @param ctx context.Context 上下文
+// @return *VerifyTicketStatus 验证票据状态
+// @return error 错误信息
+func (c *Client) GetVerifyTicketStatus(ctx context.Context) (*VerifyTicketStatus, error) {
+	ticket, err := c.getVerifyTicket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取验证票据失败: %w", err)
+	}
+	return c.verifyTicketStatus(ticket, time.Now()), nil
+}
+
+// verifyTicketStatus 计算验证票据状态
+func (c *Client) verifyTicketStatus(ticket *storage.ComponentVerifyTicket, now time.Time) *VerifyTicketStatus {
+	status := &VerifyTicketStatus{Stale: true}
+	if ticket == nil || ticket.Ticket == "" {
+		return status
+	}
+	status.CreatedAt = ticket.CreatedAt
+	status.ExpiresAt = ticket.ExpiresAt
+	status.Age = now.Sub(ticket.CreatedAt)
+	status.Present = now.Before(ticket.ExpiresAt)
+	status.Stale = ticket.ExpiresAt.Sub(now) < c.verifyTicketLead
+	return status
+}
+
+// ensureVerifyTicket 获取component_access_token前检查验证票据
+// 验证票据不存在或已过期时触发推送并等待票据到达，即将过期时在后台触发推送
+func (c *Client) ensureVerifyTicket(ctx context.Context) error {
+	ticket, err := c.getVerifyTicket(ctx)
+	if err != nil {
+		// 由获取token时报告存储错误
+		return nil
+	}
+
+	status := c.verifyTicketStatus(ticket, time.Now())
+	if status.Present {
+		if status.Stale && c.verifyTicketWait > 0 && c.pushTicketDue() {
+			go func() {
+				if err := c.StartPushTicket(context.WithoutCancel(ctx)); err != nil {
+					c.logger.Warn(fmt.Sprintf("验证票据即将过期，%v", err))
+				}
+			}()
+		}
+		return nil
+	}
+	if c.verifyTicketWait <= 0 {
+		return nil
+	}
+
+	// 并发的等待合并为一次推送
+	_, err = core.RefreshOnce(ctx, &c.refreshGroup, core.RefreshKey(core.TokenKindComponentVerifyTicket, c.config.ComponentAppID), func(ctx context.Context) (string, error) {
+		return "", c.waitVerifyTicket(ctx)
+	})
+	return err
+}
+
+// waitVerifyTicket 触发推送并等待验证票据到达
+func (c *Client) waitVerifyTicket(ctx context.Context) error {
+	if c.pushTicketDue() {
+		c.logger.Info("验证票据不存在或已过期，请求微信推送验证票据")
+		if err := c.StartPushTicket(ctx); err != nil {
+			return fmt.Errorf("验证票据不存在或已过期，%w", err)
+		}
+	}
+
+	deadline := time.NewTimer(c.verifyTicketWait)
+	defer deadline.Stop()
+	poll := time.NewTicker(verifyTicketPollInterval)
+	defer poll.Stop()
+
+	for {
+		// 先取得通知channel再检查存储，避免错过检查之后到达的票据
+		arrived := c.verifyTicketNotify()
+		ticket, err := c.getVerifyTicket(ctx)
+		if err == nil && c.verifyTicketStatus(ticket, time.Now()).Present {
+			return nil
+		}
+
+		select {
+		case <-arrived:
+		case <-poll.C:
+		case <-deadline.C:
+			return fmt.Errorf("验证票据不存在或已过期，已请求微信推送，%s 内未收到，请检查消息接收URL", c.verifyTicketWait)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pushTicketDue 距上次触发推送超过最小间隔时记录本次触发并返回true
+func (c *Client) pushTicketDue() bool {
+	c.verifyTicketMu.Lock()
+	defer c.verifyTicketMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastPushTicket) < verifyTicketPushInterval {
+		return false
+	}
+	c.lastPushTicket = now
+	return true
+}
+
+// verifyTicketNotify 返回收到验证票据时关闭的channel
+func (c *Client) verifyTicketNotify() <-chan struct{} {
+	c.verifyTicketMu.Lock()
+	defer c.verifyTicketMu.Unlock()
+
+	if c.verifyTicketArrived == nil {
+		c.verifyTicketArrived = make(chan struct{})
+	}
+	return c.verifyTicketArrived
+}
+
+// notifyVerifyTicket 通知等待中的请求验证票据已保存
+func (c *Client) notifyVerifyTicket() {
+	c.verifyTicketMu.Lock()
+	defer c.verifyTicketMu.Unlock()
+
+	if c.verifyTicketArrived != nil {
+		close(c.verifyTicketArrived)
+		c.verifyTicketArrived = nil
+	}
+}
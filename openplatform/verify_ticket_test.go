@@ -0,0 +1,49 @@
+package openplatform_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jcbowen/wego/openplatform"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestMissingVerifyTicketTriggersPush(t *testing.T) {
+	server := wegotest.NewServer().AddComponent("wx_component", "secret")
+	defer server.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := openplatform.NewClientWithStorage(&openplatform.Config{ComponentAppID: "wx_component", ComponentAppSecret: "secret"},
+		store, server.Resolver())
+	ctx := context.Background()
+
+	// 模拟微信将验证票据推送到消息接收URL
+	server.OnTicketPush(func(componentAppID, ticket string) {
+		xmlData := fmt.Sprintf("<xml><AppId>%s</AppId><CreateTime>%d</CreateTime><InfoType>component_verify_ticket</InfoType><ComponentVerifyTicket>%s</ComponentVerifyTicket></xml>",
+			componentAppID, time.Now().Unix(), ticket)
+		_, _ = client.HandleAuthorizationEvent(ctx, []byte(xmlData), "", "", "", "")
+	})
+
+	status, err := client.GetVerifyTicketStatus(ctx)
+	if err != nil || status.Present || !status.Stale {
+		t.Fatalf("expected missing ticket, got %+v, %v", status, err)
+	}
+
+	if _, err := client.GetComponentAccessToken(ctx, ""); err != nil {
+		t.Fatalf("get component access token: %v", err)
+	}
+	if n := len(server.Requests("/cgi-bin/component/api_start_push_ticket")); n != 1 {
+		t.Fatalf("expected 1 push request, got %d", n)
+	}
+
+	status, err = client.GetVerifyTicketStatus(ctx)
+	if err != nil || !status.Present || status.Stale || status.Age > time.Minute {
+		t.Fatalf("expected fresh ticket, got %+v, %v", status, err)
+	}
+}
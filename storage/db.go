@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -14,7 +15,8 @@ import (
 // DBStorage 数据库存储实现
 // 将令牌数据持久化到关系型数据库
 type DBStorage struct {
-	db *gorm.DB
+	db        *gorm.DB
+	namespace string // 命名空间，为空时是默认命名空间
 }
 
 // NewDBStorage 创建数据库存储实例
@@ -40,6 +42,17 @@ func NewDBStorage(dbConfig jcbaseGo.DbStruct, opts ...string) (*DBStorage, error
 		&DBAuthorizerToken{},
 		&DBPrevEncodingAESKey{},
 		&DBComponentVerifyTicket{},
+		&DBLock{},
+		&DBStableToken{},
+		&DBAuthorizerInfo{},
+	); err != nil {
+		return nil, err
+	}
+	if err := dropAppIDUniqueIndexes(db,
+		&DBAuthorizerToken{},
+		&DBPrevEncodingAESKey{},
+		&DBStableToken{},
+		&DBAuthorizerInfo{},
 	); err != nil {
 		return nil, err
 	}
@@ -47,11 +60,49 @@ func NewDBStorage(dbConfig jcbaseGo.DbStruct, opts ...string) (*DBStorage, error
 	return &DBStorage{db: db}, nil
 }
 
+// Namespace 实现 NamespacedStorage 接口，返回共享数据库连接、按namespace列隔离的存储
+func (s *DBStorage) Namespace(namespace string) TokenStorage {
+	if namespace == "" || inNamespace(s.namespace, namespace) {
+		return s
+	}
+	return &DBStorage{db: s.db, namespace: joinNamespace(s.namespace, namespace)}
+}
+
+// scoped 返回限定在当前命名空间的查询
+func (s *DBStorage) scoped() *gorm.DB {
+	return s.db.Where("namespace = ?", s.namespace)
+}
+
+// dropAppIDUniqueIndexes 删除旧版本按appid建立的唯一索引
+// 加入命名空间后改为命名空间和appid联合唯一，旧索引会阻止不同命名空间保存同一appid的数据
+func dropAppIDUniqueIndexes(db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		for _, column := range []string{"authorizer_app_id", "app_id"} {
+			if stmt.Schema.LookUpField(column) == nil {
+				continue
+			}
+			name := db.NamingStrategy.IndexName(stmt.Schema.Table, column)
+			if !db.Migrator().HasIndex(model, name) {
+				continue
+			}
+			if err := db.Migrator().DropIndex(model, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // DBComponentToken 组件令牌数据库模型
 type DBComponentToken struct {
 	base.MysqlBaseModel
 
 	ID          uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index;comment:命名空间" json:"namespace"`
 	AccessToken string    `gorm:"column:access_token;type:varchar(512);not null;comment:访问令牌" json:"access_token"`
 	ExpiresIn   int       `gorm:"column:expires_in;not null;comment:有效期限" json:"expires_in"`
 	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index;comment:过期时间" json:"expires_at"`
@@ -64,6 +115,7 @@ type DBPreAuthCode struct {
 	base.MysqlBaseModel
 
 	ID          uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index;comment:命名空间" json:"namespace"`
 	PreAuthCode string    `gorm:"column:pre_auth_code;type:varchar(256);not null;comment:预授权码" json:"pre_auth_code"`
 	ExpiresIn   int       `gorm:"column:expires_in;not null;comment:有效期限" json:"expires_in"`
 	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index;comment:过期时间" json:"expires_at"`
@@ -76,7 +128,8 @@ type DBAuthorizerToken struct {
 	base.MysqlBaseModel
 
 	ID                     uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
-	AuthorizerAppID        string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex" json:"authorizer_app_id"`
+	Namespace              string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_authorizer_token_namespace;comment:命名空间" json:"namespace"`
+	AuthorizerAppID        string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex:idx_authorizer_token_namespace" json:"authorizer_app_id"`
 	AuthorizerAccessToken  string    `gorm:"column:authorizer_access_token;type:varchar(512);not null" json:"authorizer_access_token"`
 	AuthorizerRefreshToken string    `gorm:"column:authorizer_refresh_token;type:varchar(512)" json:"authorizer_refresh_token"`
 	ExpiresIn              int       `gorm:"column:expires_in;not null;comment:有效期限" json:"expires_in"`
@@ -90,7 +143,8 @@ type DBPrevEncodingAESKey struct {
 	base.MysqlBaseModel
 
 	ID              uint      `gorm:"primaryKey"`
-	AppID           string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Namespace       string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_prev_aes_key_namespace;comment:命名空间" json:"namespace"`
+	AppID           string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_prev_aes_key_namespace"`
 	PrevEncodingKey string    `gorm:"column:prev_encoding_key;type:varchar(256);not null;comment:上一次EncodingAESKey" json:"prev_encoding_key"`
 	CreatedAt       time.Time `gorm:"column:created_at;type:DATETIME;default:NULL;comment:创建时间" json:"created_at"`
 	UpdatedAt       time.Time `gorm:"column:updated_at;type:DATETIME;default:NULL;comment:更新时间" json:"updated_at"`
@@ -101,14 +155,54 @@ type DBComponentVerifyTicket struct {
 	base.MysqlBaseModel
 
 	ID        uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+	Namespace string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index;comment:命名空间" json:"namespace"`
 	Ticket    string    `gorm:"column:ticket;type:varchar(512);not null;comment:票据内容" json:"ticket"`                    // 票据内容
 	ExpiresAt time.Time `gorm:"column:expires_at;type:DATETIME;default:NULL;comment:过期时间（创建时间+12小时）" json:"expires_at"` // 过期时间（创建时间+12小时）
 	CreatedAt time.Time `gorm:"column:created_at;type:DATETIME;default:NULL;comment:创建时间" json:"created_at"`            // 创建时间
 }
 
+// DBLock 分布式锁数据库模型
+type DBLock struct {
+	base.MysqlBaseModel
+
+	Name      string    `gorm:"column:name;type:varchar(128);primaryKey;comment:锁名称" json:"name"`
+	Owner     string    `gorm:"column:owner;type:varchar(64);not null;comment:持有者" json:"owner"`
+	Fence     int64     `gorm:"column:fence;not null;default:0;comment:防护令牌" json:"fence"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:DATETIME(3);not null;comment:租约过期时间" json:"expires_at"`
+}
+
+// DBStableToken 公众号稳定版access_token数据库模型
+type DBStableToken struct {
+	base.MysqlBaseModel
+
+	ID          uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_stable_token_namespace;comment:命名空间" json:"namespace"`
+	AppID       string    `gorm:"column:app_id;type:varchar(64);not null;uniqueIndex:idx_stable_token_namespace" json:"app_id"`
+	AccessToken string    `gorm:"column:access_token;type:varchar(512);not null;comment:稳定版access_token" json:"access_token"`
+	ExpiresIn   int       `gorm:"column:expires_in;not null;comment:有效期限" json:"expires_in"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index;comment:过期时间" json:"expires_at"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:DATETIME;default:NULL;comment:创建时间" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:DATETIME;default:NULL;comment:更新时间" json:"updated_at"`
+}
+
+// DBAuthorizerInfo 授权方授权记录数据库模型
+type DBAuthorizerInfo struct {
+	base.MysqlBaseModel
+
+	ID              uint      `gorm:"column:id;type:INT(11) UNSIGNED;primaryKey;autoIncrement" json:"id"`
+	Namespace       string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_authorizer_info_namespace;comment:命名空间" json:"namespace"`
+	AuthorizerAppID string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex:idx_authorizer_info_namespace" json:"authorizer_app_id"`
+	FuncScopeIDs    string    `gorm:"column:func_scope_ids;type:varchar(1024);not null;comment:授权的权限集id，JSON数组" json:"func_scope_ids"`
+	Info            string    `gorm:"column:info;type:TEXT;comment:授权方账号信息" json:"info"`
+	AuthorizedAt    time.Time `gorm:"column:authorized_at;not null;comment:首次授权时间" json:"authorized_at"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:DATETIME;default:NULL;comment:创建时间" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;type:DATETIME;default:NULL;comment:更新时间" json:"updated_at"`
+}
+
 // SaveComponentToken 保存组件令牌到数据库
 func (s *DBStorage) SaveComponentToken(ctx context.Context, token *ComponentAccessToken) error {
 	dbToken := &DBComponentToken{
+		Namespace:   s.namespace,
 		AccessToken: token.AccessToken,
 		ExpiresIn:   token.ExpiresIn,
 		ExpiresAt:   token.ExpiresAt,
@@ -117,7 +211,7 @@ func (s *DBStorage) SaveComponentToken(ctx context.Context, token *ComponentAcce
 	// 使用事务确保数据一致性
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 先删除旧的令牌
-		if err := tx.Where("1 = 1").Delete(&DBComponentToken{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBComponentToken{}).Error; err != nil {
 			return err
 		}
 
@@ -131,7 +225,7 @@ func (s *DBStorage) GetComponentToken(ctx context.Context) (*ComponentAccessToke
 	var dbToken DBComponentToken
 
 	// 获取最新的令牌记录
-	if err := s.db.Order("created_at DESC").First(&dbToken).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbToken).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -147,12 +241,13 @@ func (s *DBStorage) GetComponentToken(ctx context.Context) (*ComponentAccessToke
 
 // DeleteComponentToken 删除组件令牌
 func (s *DBStorage) DeleteComponentToken(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBComponentToken{}).Error
+	return s.scoped().Delete(&DBComponentToken{}).Error
 }
 
 // SavePreAuthCode 保存预授权码到数据库
 func (s *DBStorage) SavePreAuthCode(ctx context.Context, code *PreAuthCode) error {
 	dbCode := &DBPreAuthCode{
+		Namespace:   s.namespace,
 		PreAuthCode: code.PreAuthCode,
 		ExpiresIn:   code.ExpiresIn,
 		ExpiresAt:   code.ExpiresAt,
@@ -161,7 +256,7 @@ func (s *DBStorage) SavePreAuthCode(ctx context.Context, code *PreAuthCode) erro
 	// 使用事务确保数据一致性
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 先删除旧的预授权码
-		if err := tx.Where("1 = 1").Delete(&DBPreAuthCode{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBPreAuthCode{}).Error; err != nil {
 			return err
 		}
 
@@ -175,7 +270,7 @@ func (s *DBStorage) GetPreAuthCode(ctx context.Context) (*PreAuthCode, error) {
 	var dbCode DBPreAuthCode
 
 	// 获取最新的预授权码记录
-	if err := s.db.Order("created_at DESC").First(&dbCode).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbCode).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -191,27 +286,27 @@ func (s *DBStorage) GetPreAuthCode(ctx context.Context) (*PreAuthCode, error) {
 
 // DeletePreAuthCode 删除预授权码
 func (s *DBStorage) DeletePreAuthCode(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBPreAuthCode{}).Error
+	return s.scoped().Delete(&DBPreAuthCode{}).Error
 }
 
 // SaveAuthorizerToken 保存授权方令牌到数据库
 func (s *DBStorage) SaveAuthorizerToken(ctx context.Context, authorizerAppID string, token *AuthorizerAccessToken) error {
 	// 使用upsert操作（存在则更新，不存在则插入）
-	return s.db.Where(DBAuthorizerToken{AuthorizerAppID: authorizerAppID}).
+	return s.scoped().Where(DBAuthorizerToken{AuthorizerAppID: authorizerAppID}).
 		Assign(DBAuthorizerToken{
 			AuthorizerAccessToken:  token.AuthorizerAccessToken,
 			AuthorizerRefreshToken: token.AuthorizerRefreshToken,
 			ExpiresIn:              token.ExpiresIn,
 			ExpiresAt:              token.ExpiresAt,
 		}).
-		FirstOrCreate(&DBAuthorizerToken{}, DBAuthorizerToken{AuthorizerAppID: authorizerAppID}).Error
+		FirstOrCreate(&DBAuthorizerToken{}, DBAuthorizerToken{Namespace: s.namespace, AuthorizerAppID: authorizerAppID}).Error
 }
 
 // GetAuthorizerToken 从数据库读取授权方令牌
 func (s *DBStorage) GetAuthorizerToken(ctx context.Context, authorizerAppID string) (*AuthorizerAccessToken, error) {
 	var dbToken DBAuthorizerToken
 
-	if err := s.db.Where("authorizer_app_id = ?", authorizerAppID).First(&dbToken).Error; err != nil {
+	if err := s.scoped().Where("authorizer_app_id = ?", authorizerAppID).First(&dbToken).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -229,18 +324,18 @@ func (s *DBStorage) GetAuthorizerToken(ctx context.Context, authorizerAppID stri
 
 // DeleteAuthorizerToken 删除授权方令牌
 func (s *DBStorage) DeleteAuthorizerToken(ctx context.Context, authorizerAppID string) error {
-	return s.db.Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerToken{}).Error
+	return s.scoped().Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerToken{}).Error
 }
 
 // ClearAuthorizerTokens 清除所有授权方令牌
 func (s *DBStorage) ClearAuthorizerTokens(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBAuthorizerToken{}).Error
+	return s.scoped().Delete(&DBAuthorizerToken{}).Error
 }
 
 // ListAuthorizerTokens 列出所有已存储的授权方appid
 func (s *DBStorage) ListAuthorizerTokens(ctx context.Context) ([]string, error) {
 	var tokens []DBAuthorizerToken
-	if err := s.db.Select("authorizer_app_id").Find(&tokens).Error; err != nil {
+	if err := s.scoped().Select("authorizer_app_id").Find(&tokens).Error; err != nil {
 		return nil, err
 	}
 
@@ -264,18 +359,18 @@ func (s *DBStorage) Ping(ctx context.Context) error {
 // SavePrevEncodingAESKey 保存上一次EncodingAESKey到数据库
 func (s *DBStorage) SavePrevEncodingAESKey(ctx context.Context, appID string, prevKey string) error {
 	// 使用upsert操作（存在则更新，不存在则插入）
-	return s.db.Where(DBPrevEncodingAESKey{AppID: appID}).
+	return s.scoped().Where(DBPrevEncodingAESKey{AppID: appID}).
 		Assign(DBPrevEncodingAESKey{
 			PrevEncodingKey: prevKey,
 		}).
-		FirstOrCreate(&DBPrevEncodingAESKey{}, DBPrevEncodingAESKey{AppID: appID}).Error
+		FirstOrCreate(&DBPrevEncodingAESKey{}, DBPrevEncodingAESKey{Namespace: s.namespace, AppID: appID}).Error
 }
 
 // GetPrevEncodingAESKey 从数据库读取上一次EncodingAESKey
 func (s *DBStorage) GetPrevEncodingAESKey(ctx context.Context, appID string) (*PrevEncodingAESKey, error) {
 	var dbKey DBPrevEncodingAESKey
 
-	if err := s.db.Where("app_id = ?", appID).First(&dbKey).Error; err != nil {
+	if err := s.scoped().Where("app_id = ?", appID).First(&dbKey).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -291,7 +386,97 @@ func (s *DBStorage) GetPrevEncodingAESKey(ctx context.Context, appID string) (*P
 
 // DeletePrevEncodingAESKey 删除上一次EncodingAESKey
 func (s *DBStorage) DeletePrevEncodingAESKey(ctx context.Context, appID string) error {
-	return s.db.Where("app_id = ?", appID).Delete(&DBPrevEncodingAESKey{}).Error
+	return s.scoped().Where("app_id = ?", appID).Delete(&DBPrevEncodingAESKey{}).Error
+}
+
+// SaveStableToken 保存稳定版access_token到数据库
+func (s *DBStorage) SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error {
+	// 使用upsert操作（存在则更新，不存在则插入）
+	return s.scoped().Where(DBStableToken{AppID: appID}).
+		Assign(DBStableToken{
+			AccessToken: token.AccessToken,
+			ExpiresIn:   token.ExpiresIn,
+			ExpiresAt:   token.ExpiresAt,
+		}).
+		FirstOrCreate(&DBStableToken{}, DBStableToken{Namespace: s.namespace, AppID: appID}).Error
+}
+
+// GetStableToken 从数据库读取稳定版access_token
+func (s *DBStorage) GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) {
+	var dbToken DBStableToken
+
+	if err := s.scoped().Where("app_id = ?", appID).First(&dbToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &StableAccessToken{
+		AppID:       appID,
+		AccessToken: dbToken.AccessToken,
+		ExpiresIn:   dbToken.ExpiresIn,
+		ExpiresAt:   dbToken.ExpiresAt,
+	}, nil
+}
+
+// DeleteStableToken 删除稳定版access_token
+func (s *DBStorage) DeleteStableToken(ctx context.Context, appID string) error {
+	return s.scoped().Where("app_id = ?", appID).Delete(&DBStableToken{}).Error
+}
+
+// SaveAuthorizerInfo 保存授权方授权记录到数据库
+func (s *DBStorage) SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error {
+	scopeIDs, err := json.Marshal(info.FuncScopeIDs)
+	if err != nil {
+		return err
+	}
+
+	// 使用upsert操作（存在则更新，不存在则插入）
+	return s.scoped().Where(DBAuthorizerInfo{AuthorizerAppID: authorizerAppID}).
+		Assign(DBAuthorizerInfo{
+			FuncScopeIDs: string(scopeIDs),
+			Info:         string(info.Info),
+			AuthorizedAt: info.AuthorizedAt,
+		}).
+		FirstOrCreate(&DBAuthorizerInfo{}, DBAuthorizerInfo{Namespace: s.namespace, AuthorizerAppID: authorizerAppID}).Error
+}
+
+// GetAuthorizerInfo 从数据库读取授权方授权记录
+func (s *DBStorage) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) {
+	var dbInfo DBAuthorizerInfo
+
+	if err := s.scoped().Where("authorizer_app_id = ?", authorizerAppID).First(&dbInfo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return decodeAuthorizerInfo(authorizerAppID, dbInfo.FuncScopeIDs, dbInfo.Info, dbInfo.AuthorizedAt, dbInfo.UpdatedAt)
+}
+
+// DeleteAuthorizerInfo 删除授权方授权记录
+func (s *DBStorage) DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error {
+	return s.scoped().Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerInfo{}).Error
+}
+
+// decodeAuthorizerInfo 将数据库中的授权记录转换为 AuthorizerInfo
+func decodeAuthorizerInfo(authorizerAppID, scopeIDs, info string, authorizedAt, updatedAt time.Time) (*AuthorizerInfo, error) {
+	result := &AuthorizerInfo{
+		AuthorizerAppID: authorizerAppID,
+		AuthorizedAt:    authorizedAt,
+		UpdatedAt:       updatedAt,
+	}
+	if scopeIDs != "" {
+		if err := json.Unmarshal([]byte(scopeIDs), &result.FuncScopeIDs); err != nil {
+			return nil, err
+		}
+	}
+	if info != "" {
+		result.Info = json.RawMessage(info)
+	}
+	return result, nil
 }
 
 // SaveComponentVerifyTicket 保存验证票据到数据库
@@ -301,16 +486,26 @@ func (s *DBStorage) DeletePrevEncodingAESKey(ctx context.Context, appID string)
 func (s *DBStorage) SaveComponentVerifyTicket(ctx context.Context, ticket string) error {
 	// 票据有效期为12小时
 	expiresAt := time.Now().Add(12 * time.Hour)
-	dbTicket := &DBComponentVerifyTicket{
+	return s.putComponentVerifyTicket(ctx, &ComponentVerifyTicket{
 		Ticket:    ticket,
 		CreatedAt: time.Now(),
 		ExpiresAt: expiresAt,
+	})
+}
+
+// putComponentVerifyTicket 按原有的创建时间和过期时间保存验证票据
+func (s *DBStorage) putComponentVerifyTicket(ctx context.Context, ticket *ComponentVerifyTicket) error {
+	dbTicket := &DBComponentVerifyTicket{
+		Namespace: s.namespace,
+		Ticket:    ticket.Ticket,
+		CreatedAt: ticket.CreatedAt,
+		ExpiresAt: ticket.ExpiresAt,
 	}
 
 	// 使用事务确保数据一致性
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 先删除旧的票据
-		if err := tx.Where("1 = 1").Delete(&DBComponentVerifyTicket{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBComponentVerifyTicket{}).Error; err != nil {
 			return err
 		}
 
@@ -327,7 +522,7 @@ func (s *DBStorage) GetComponentVerifyTicket(ctx context.Context) (*ComponentVer
 	var dbTicket DBComponentVerifyTicket
 
 	// 获取最新的票据记录
-	if err := s.db.Order("created_at DESC").First(&dbTicket).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbTicket).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -345,5 +540,10 @@ func (s *DBStorage) GetComponentVerifyTicket(ctx context.Context) (*ComponentVer
 // @param ctx context.Context 上下文
 // @return error 错误信息
 func (s *DBStorage) DeleteComponentVerifyTicket(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBComponentVerifyTicket{}).Error
+	return s.scoped().Delete(&DBComponentVerifyTicket{}).Error
+}
+
+// Lock 实现 Locker 接口，基于 DBLock 表的行租约
+func (s *DBStorage) Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	return lockRow(ctx, s.db, &DBLock{}, lockName(s.namespace, name), ttl)
 }
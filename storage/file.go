@@ -19,6 +19,9 @@ type FileStorage struct {
 	componentVerifyTicketFile string
 	authorizerTokensDir       string
 	prevEncodingAESKeysDir    string // 上一次EncodingAESKey存储目录
+	stableTokensDir           string // 稳定版access_token存储目录
+	authorizerInfosDir        string // 授权方授权记录存储目录
+	namespace                 string // 命名空间，默认命名空间为空
 }
 
 // NewFileStorage 创建文件存储实例
@@ -32,14 +35,7 @@ func NewFileStorage(baseDir string) (*FileStorage, error) {
 		baseDir = fallback
 	}
 
-	storage := &FileStorage{
-		baseDir:                   baseDir,
-		componentTokenFile:        filepath.Join(baseDir, "component_token.json"),
-		preAuthCodeFile:           filepath.Join(baseDir, "pre_auth_code.json"),
-		componentVerifyTicketFile: filepath.Join(baseDir, "component_verify_ticket.json"),
-		authorizerTokensDir:       filepath.Join(baseDir, "authorizer_tokens"),
-		prevEncodingAESKeysDir:    filepath.Join(baseDir, "prev_encoding_aes_keys"),
-	}
+	storage := newFileStorage(baseDir)
 
 	// 确保授权方令牌目录存在
 	if err := os.MkdirAll(storage.authorizerTokensDir, 0755); err != nil {
@@ -51,9 +47,43 @@ func NewFileStorage(baseDir string) (*FileStorage, error) {
 		return nil, err
 	}
 
+	// 确保稳定版access_token存储目录存在
+	if err := os.MkdirAll(storage.stableTokensDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// 确保授权方授权记录存储目录存在
+	if err := os.MkdirAll(storage.authorizerInfosDir, 0755); err != nil {
+		return nil, err
+	}
+
 	return storage, nil
 }
 
+// newFileStorage 创建文件存储实例，不创建目录，目录在首次写入时创建
+func newFileStorage(baseDir string) *FileStorage {
+	return &FileStorage{
+		baseDir:                   baseDir,
+		componentTokenFile:        filepath.Join(baseDir, "component_token.json"),
+		preAuthCodeFile:           filepath.Join(baseDir, "pre_auth_code.json"),
+		componentVerifyTicketFile: filepath.Join(baseDir, "component_verify_ticket.json"),
+		authorizerTokensDir:       filepath.Join(baseDir, "authorizer_tokens"),
+		prevEncodingAESKeysDir:    filepath.Join(baseDir, "prev_encoding_aes_keys"),
+		stableTokensDir:           filepath.Join(baseDir, "stable_tokens"),
+		authorizerInfosDir:        filepath.Join(baseDir, "authorizer_infos"),
+	}
+}
+
+// Namespace 实现 NamespacedStorage 接口，命名空间的数据保存在 {baseDir}/namespaces/{namespace} 目录
+func (s *FileStorage) Namespace(namespace string) TokenStorage {
+	if namespace == "" || inNamespace(s.namespace, namespace) {
+		return s
+	}
+	scoped := newFileStorage(filepath.Join(s.baseDir, "namespaces", unsafeLockNameChars.ReplaceAllString(namespace, "_")))
+	scoped.namespace = joinNamespace(s.namespace, namespace)
+	return scoped
+}
+
 // SaveComponentToken 保存组件令牌到文件
 func (s *FileStorage) SaveComponentToken(ctx context.Context, token *ComponentAccessToken) error {
 	s.mu.Lock()
@@ -120,9 +150,6 @@ func (s *FileStorage) DeletePreAuthCode(ctx context.Context) error {
 
 // SaveVerifyTicket 保存验证票据到文件
 func (s *FileStorage) SaveComponentVerifyTicket(ctx context.Context, ticket string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// 创建票据结构，记录创建时间和过期时间
 	verifyTicket := &ComponentVerifyTicket{
 		Ticket:    ticket,
@@ -130,7 +157,15 @@ func (s *FileStorage) SaveComponentVerifyTicket(ctx context.Context, ticket stri
 		ExpiresAt: time.Now().Add(12 * time.Hour), // 12小时有效期
 	}
 
-	return s.saveToFile(s.componentVerifyTicketFile, verifyTicket)
+	return s.putComponentVerifyTicket(ctx, verifyTicket)
+}
+
+// putComponentVerifyTicket 按原有的创建时间和过期时间保存验证票据
+func (s *FileStorage) putComponentVerifyTicket(ctx context.Context, ticket *ComponentVerifyTicket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.saveToFile(s.componentVerifyTicketFile, ticket)
 }
 
 // GetVerifyTicket 从文件读取验证票据
@@ -242,6 +277,9 @@ func (s *FileStorage) ListAuthorizerTokens(ctx context.Context) ([]string, error
 // Ping 存储健康检查
 func (s *FileStorage) Ping(ctx context.Context) error {
 	// 检查基础目录是否可写
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return err
+	}
 	testFile := filepath.Join(s.baseDir, ".ping_test")
 	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
 		return err
@@ -252,6 +290,11 @@ func (s *FileStorage) Ping(ctx context.Context) error {
 
 // saveToFile 将数据保存到文件
 func (s *FileStorage) saveToFile(filename string, data interface{}) error {
+	// 命名空间的目录在首次写入时创建
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
 	// 创建临时文件
 	tempFile := filename + ".tmp"
 
@@ -329,3 +372,79 @@ func (s *FileStorage) DeletePrevEncodingAESKey(ctx context.Context, appID string
 	filename := filepath.Join(s.prevEncodingAESKeysDir, appID+".json")
 	return os.Remove(filename)
 }
+
+// SaveStableToken 保存稳定版access_token到文件
+func (s *FileStorage) SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filename := filepath.Join(s.stableTokensDir, appID+".json")
+	return s.saveToFile(filename, token)
+}
+
+// GetStableToken 从文件读取稳定版access_token
+func (s *FileStorage) GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filename := filepath.Join(s.stableTokensDir, appID+".json")
+	var token StableAccessToken
+	if err := s.loadFromFile(filename, &token); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteStableToken 删除稳定版access_token文件，文件不存在时不报错
+func (s *FileStorage) DeleteStableToken(ctx context.Context, appID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filename := filepath.Join(s.stableTokensDir, appID+".json")
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SaveAuthorizerInfo 保存授权方授权记录到文件
+func (s *FileStorage) SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filename := filepath.Join(s.authorizerInfosDir, authorizerAppID+".json")
+	return s.saveToFile(filename, info)
+}
+
+// GetAuthorizerInfo 从文件读取授权方授权记录
+func (s *FileStorage) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filename := filepath.Join(s.authorizerInfosDir, authorizerAppID+".json")
+	var info AuthorizerInfo
+	if err := s.loadFromFile(filename, &info); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// DeleteAuthorizerInfo 删除授权方授权记录文件，文件不存在时不报错
+func (s *FileStorage) DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filename := filepath.Join(s.authorizerInfosDir, authorizerAppID+".json")
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unsafeLockNameChars 锁名称中不能用于文件名的字符
+var unsafeLockNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// fileLock 基于flock的文件锁
+type fileLock struct {
+	file  *os.File
+	fence int64
+}
+
+// Lock 实现 Locker 接口，基于flock，共享同一存储目录的多个进程互斥
+// ttl 不生效，持有锁的进程退出时由操作系统释放锁；不支持flock的平台返回 ErrLockNotSupported
+func (s *FileStorage) Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	dir := filepath.Join(s.baseDir, "locks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, unsafeLockNameChars.ReplaceAllString(name, "_")+".lock"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := waitLock(ctx, func() (Lock, bool, error) {
+		ok, err := tryFlock(file)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		fence, err := nextFence(file)
+		if err != nil {
+			_ = unflock(file)
+			return nil, false, err
+		}
+		return &fileLock{file: file, fence: fence}, true, nil
+	})
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// nextFence 递增锁文件中保存的防护令牌
+func nextFence(file *os.File) (int64, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	fence, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	fence++
+
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := file.WriteAt([]byte(strconv.FormatInt(fence, 10)), 0); err != nil {
+		return 0, fmt.Errorf("failed to write lock fence: %w", err)
+	}
+	return fence, nil
+}
+
+// Fence 实现 Lock 接口
+func (l *fileLock) Fence() int64 {
+	return l.fence
+}
+
+// Unlock 实现 Lock 接口
+func (l *fileLock) Unlock(ctx context.Context) error {
+	err := unflock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package storage
+
+import "os"
+
+// tryFlock 当前平台不支持flock
+func tryFlock(file *os.File) (bool, error) {
+	return false, ErrLockNotSupported
+}
+
+// unflock 当前平台不支持flock
+func unflock(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// InstrumentedStorage 带指标收集和链路追踪的存储包装器
+// 记录每次存储操作的耗时，标签为 backend、operation、result，并为每次操作创建span
+type InstrumentedStorage struct {
+	storage TokenStorage
+	backend string
+	metrics core.Metrics
+	tracer  core.Tracer
+}
+
+// NewInstrumentedStorage 创建带指标收集和链路追踪的存储
+// @param storage TokenStorage 被包装的存储实例，已经是 *InstrumentedStorage 时只替换指标收集器和链路追踪
+// @param metrics core.Metrics 指标收集器，为nil时不收集
+// @param tracer core.Tracer 链路追踪，为nil时不追踪
+// @return *InstrumentedStorage 带指标收集和链路追踪的存储
+func NewInstrumentedStorage(storage TokenStorage, metrics core.Metrics, tracer core.Tracer) *InstrumentedStorage {
+	if instrumented, ok := storage.(*InstrumentedStorage); ok {
+		storage = instrumented.storage
+	}
+	if metrics == nil {
+		metrics = core.NopMetrics{}
+	}
+	if tracer == nil {
+		tracer = core.NopTracer{}
+	}
+	return &InstrumentedStorage{
+		storage: storage,
+		backend: BackendName(storage),
+		metrics: metrics,
+		tracer:  tracer,
+	}
+}
+
+// Unwrap 返回被包装的存储实例
+func (s *InstrumentedStorage) Unwrap() TokenStorage {
+	return s.storage
+}
+
+// BackendName 返回存储实现的名称，用于指标的backend标签
+func BackendName(storage TokenStorage) string {
+	switch v := storage.(type) {
+	case *FileStorage:
+		return "file"
+	case *RedisStorage:
+		return "redis"
+	case *DBStorage:
+		return "db"
+	case *SqliteStorage:
+		return "sqlite"
+	case *InstrumentedStorage:
+		return v.backend
+	default:
+		return fmt.Sprintf("%T", storage)
+	}
+}
+
+// start 开始一次存储操作，返回操作结束时调用的函数
+func (s *InstrumentedStorage) start(ctx context.Context, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, core.SpanStorage,
+		core.Attr(core.AttrStorageBackend, s.backend),
+		core.Attr(core.AttrStorageOperation, operation))
+
+	return ctx, func(err error) {
+		s.metrics.ObserveHistogram(core.MetricStorageDuration, time.Since(start).Seconds(), core.Labels{
+			"backend":   s.backend,
+			"operation": operation,
+			"result":    core.ResultLabel(err),
+		})
+		core.EndSpan(span, err)
+	}
+}
+
+// SaveComponentToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) SaveComponentToken(ctx context.Context, token *ComponentAccessToken) error {
+	ctx, done := s.start(ctx, "save_component_token")
+	err := s.storage.SaveComponentToken(ctx, token)
+	done(err)
+	return err
+}
+
+// GetComponentToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) GetComponentToken(ctx context.Context) (*ComponentAccessToken, error) {
+	ctx, done := s.start(ctx, "get_component_token")
+	token, err := s.storage.GetComponentToken(ctx)
+	done(err)
+	return token, err
+}
+
+// DeleteComponentToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) DeleteComponentToken(ctx context.Context) error {
+	ctx, done := s.start(ctx, "delete_component_token")
+	err := s.storage.DeleteComponentToken(ctx)
+	done(err)
+	return err
+}
+
+// SavePreAuthCode 实现 TokenStorage 接口
+func (s *InstrumentedStorage) SavePreAuthCode(ctx context.Context, code *PreAuthCode) error {
+	ctx, done := s.start(ctx, "save_pre_auth_code")
+	err := s.storage.SavePreAuthCode(ctx, code)
+	done(err)
+	return err
+}
+
+// GetPreAuthCode 实现 TokenStorage 接口
+func (s *InstrumentedStorage) GetPreAuthCode(ctx context.Context) (*PreAuthCode, error) {
+	ctx, done := s.start(ctx, "get_pre_auth_code")
+	code, err := s.storage.GetPreAuthCode(ctx)
+	done(err)
+	return code, err
+}
+
+// DeletePreAuthCode 实现 TokenStorage 接口
+func (s *InstrumentedStorage) DeletePreAuthCode(ctx context.Context) error {
+	ctx, done := s.start(ctx, "delete_pre_auth_code")
+	err := s.storage.DeletePreAuthCode(ctx)
+	done(err)
+	return err
+}
+
+// SaveComponentVerifyTicket 实现 TokenStorage 接口
+func (s *InstrumentedStorage) SaveComponentVerifyTicket(ctx context.Context, ticket string) error {
+	ctx, done := s.start(ctx, "save_component_verify_ticket")
+	err := s.storage.SaveComponentVerifyTicket(ctx, ticket)
+	done(err)
+	return err
+}
+
+// putComponentVerifyTicket 保存完整的验证票据，被包装的存储不支持时按票据内容重新保存
+func (s *InstrumentedStorage) putComponentVerifyTicket(ctx context.Context, ticket *ComponentVerifyTicket) error {
+	ctx, done := s.start(ctx, "save_component_verify_ticket")
+	err := putComponentVerifyTicket(ctx, s.storage, ticket)
+	done(err)
+	return err
+}
+
+// GetComponentVerifyTicket 实现 TokenStorage 接口
+func (s *InstrumentedStorage) GetComponentVerifyTicket(ctx context.Context) (*ComponentVerifyTicket, error) {
+	ctx, done := s.start(ctx, "get_component_verify_ticket")
+	ticket, err := s.storage.GetComponentVerifyTicket(ctx)
+	done(err)
+	return ticket, err
+}
+
+// DeleteComponentVerifyTicket 实现 TokenStorage 接口
+func (s *InstrumentedStorage) DeleteComponentVerifyTicket(ctx context.Context) error {
+	ctx, done := s.start(ctx, "delete_component_verify_ticket")
+	err := s.storage.DeleteComponentVerifyTicket(ctx)
+	done(err)
+	return err
+}
+
+// SaveAuthorizerToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) SaveAuthorizerToken(ctx context.Context, authorizerAppID string, token *AuthorizerAccessToken) error {
+	ctx, done := s.start(ctx, "save_authorizer_token")
+	err := s.storage.SaveAuthorizerToken(ctx, authorizerAppID, token)
+	done(err)
+	return err
+}
+
+// GetAuthorizerToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) GetAuthorizerToken(ctx context.Context, authorizerAppID string) (*AuthorizerAccessToken, error) {
+	ctx, done := s.start(ctx, "get_authorizer_token")
+	token, err := s.storage.GetAuthorizerToken(ctx, authorizerAppID)
+	done(err)
+	return token, err
+}
+
+// DeleteAuthorizerToken 实现 TokenStorage 接口
+func (s *InstrumentedStorage) DeleteAuthorizerToken(ctx context.Context, authorizerAppID string) error {
+	ctx, done := s.start(ctx, "delete_authorizer_token")
+	err := s.storage.DeleteAuthorizerToken(ctx, authorizerAppID)
+	done(err)
+	return err
+}
+
+// ClearAuthorizerTokens 实现 TokenStorage 接口
+func (s *InstrumentedStorage) ClearAuthorizerTokens(ctx context.Context) error {
+	ctx, done := s.start(ctx, "clear_authorizer_tokens")
+	err := s.storage.ClearAuthorizerTokens(ctx)
+	done(err)
+	return err
+}
+
+// ListAuthorizerTokens 实现 TokenStorage 接口
+func (s *InstrumentedStorage) ListAuthorizerTokens(ctx context.Context) ([]string, error) {
+	ctx, done := s.start(ctx, "list_authorizer_tokens")
+	appIDs, err := s.storage.ListAuthorizerTokens(ctx)
+	done(err)
+	return appIDs, err
+}
+
+// SavePrevEncodingAESKey 实现 TokenStorage 接口
+func (s *InstrumentedStorage) SavePrevEncodingAESKey(ctx context.Context, appID string, prevKey string) error {
+	ctx, done := s.start(ctx, "save_prev_encoding_aes_key")
+	err := s.storage.SavePrevEncodingAESKey(ctx, appID, prevKey)
+	done(err)
+	return err
+}
+
+// GetPrevEncodingAESKey 实现 TokenStorage 接口
+func (s *InstrumentedStorage) GetPrevEncodingAESKey(ctx context.Context, appID string) (*PrevEncodingAESKey, error) {
+	ctx, done := s.start(ctx, "get_prev_encoding_aes_key")
+	key, err := s.storage.GetPrevEncodingAESKey(ctx, appID)
+	done(err)
+	return key, err
+}
+
+// DeletePrevEncodingAESKey 实现 TokenStorage 接口
+func (s *InstrumentedStorage) DeletePrevEncodingAESKey(ctx context.Context, appID string) error {
+	ctx, done := s.start(ctx, "delete_prev_encoding_aes_key")
+	err := s.storage.DeletePrevEncodingAESKey(ctx, appID)
+	done(err)
+	return err
+}
+
+// SaveStableToken 实现 StableTokenStorage 接口，被包装的存储不支持时返回 ErrStableTokenNotSupported
+func (s *InstrumentedStorage) SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error {
+	stable, ok := s.storage.(StableTokenStorage)
+	if !ok {
+		return ErrStableTokenNotSupported
+	}
+	ctx, done := s.start(ctx, "save_stable_token")
+	err := stable.SaveStableToken(ctx, appID, token)
+	done(err)
+	return err
+}
+
+// GetStableToken 实现 StableTokenStorage 接口，被包装的存储不支持时返回 ErrStableTokenNotSupported
+func (s *InstrumentedStorage) GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) {
+	stable, ok := s.storage.(StableTokenStorage)
+	if !ok {
+		return nil, ErrStableTokenNotSupported
+	}
+	ctx, done := s.start(ctx, "get_stable_token")
+	token, err := stable.GetStableToken(ctx, appID)
+	done(err)
+	return token, err
+}
+
+// DeleteStableToken 实现 StableTokenStorage 接口，被包装的存储不支持时返回 ErrStableTokenNotSupported
+func (s *InstrumentedStorage) DeleteStableToken(ctx context.Context, appID string) error {
+	stable, ok := s.storage.(StableTokenStorage)
+	if !ok {
+		return ErrStableTokenNotSupported
+	}
+	ctx, done := s.start(ctx, "delete_stable_token")
+	err := stable.DeleteStableToken(ctx, appID)
+	done(err)
+	return err
+}
+
+// SaveAuthorizerInfo 实现 AuthorizerInfoStorage 接口，被包装的存储不支持时返回 ErrAuthorizerInfoNotSupported
+func (s *InstrumentedStorage) SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error {
+	infos, ok := s.storage.(AuthorizerInfoStorage)
+	if !ok {
+		return ErrAuthorizerInfoNotSupported
+	}
+	ctx, done := s.start(ctx, "save_authorizer_info")
+	err := infos.SaveAuthorizerInfo(ctx, authorizerAppID, info)
+	done(err)
+	return err
+}
+
+// GetAuthorizerInfo 实现 AuthorizerInfoStorage 接口，被包装的存储不支持时返回 ErrAuthorizerInfoNotSupported
+func (s *InstrumentedStorage) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) {
+	infos, ok := s.storage.(AuthorizerInfoStorage)
+	if !ok {
+		return nil, ErrAuthorizerInfoNotSupported
+	}
+	ctx, done := s.start(ctx, "get_authorizer_info")
+	info, err := infos.GetAuthorizerInfo(ctx, authorizerAppID)
+	done(err)
+	return info, err
+}
+
+// DeleteAuthorizerInfo 实现 AuthorizerInfoStorage 接口，被包装的存储不支持时返回 ErrAuthorizerInfoNotSupported
+func (s *InstrumentedStorage) DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error {
+	infos, ok := s.storage.(AuthorizerInfoStorage)
+	if !ok {
+		return ErrAuthorizerInfoNotSupported
+	}
+	ctx, done := s.start(ctx, "delete_authorizer_info")
+	err := infos.DeleteAuthorizerInfo(ctx, authorizerAppID)
+	done(err)
+	return err
+}
+
+// Ping 实现 TokenStorage 接口
+func (s *InstrumentedStorage) Ping(ctx context.Context) error {
+	ctx, done := s.start(ctx, "ping")
+	err := s.storage.Ping(ctx)
+	done(err)
+	return err
+}
+
+// Lock 实现 Locker 接口，被包装的存储不支持加锁时返回 ErrLockNotSupported
+func (s *InstrumentedStorage) Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	locker, ok := s.storage.(Locker)
+	if !ok {
+		return nil, ErrLockNotSupported
+	}
+	ctx, done := s.start(ctx, "lock")
+	lock, err := locker.Lock(ctx, name, ttl)
+	if errors.Is(err, ErrLockNotSupported) {
+		done(nil)
+	} else {
+		done(err)
+	}
+	return lock, err
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -42,6 +44,45 @@ type PrevEncodingAESKey struct {
 	UpdatedAt          time.Time `json:"updated_at"`            // 更新时间
 }
 
+// StableAccessToken 公众号稳定版access_token
+type StableAccessToken struct {
+	AppID       string    `json:"appid"`
+	AccessToken string    `json:"access_token"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ErrStableTokenNotSupported 存储不支持稳定版access_token
+var ErrStableTokenNotSupported = errors.New("storage does not support stable access token")
+
+// StableTokenStorage 稳定版access_token存储能力，是存储的可选实现
+// 内置的文件、Redis、数据库、SQLite存储均已实现，自定义存储未实现时稳定版access_token只缓存在进程内
+type StableTokenStorage interface {
+	SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error
+	GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) // 不存在时返回nil
+	DeleteStableToken(ctx context.Context, appID string) error
+}
+
+// AuthorizerInfo 授权方的授权记录
+type AuthorizerInfo struct {
+	AuthorizerAppID string          `json:"authorizer_appid"`
+	FuncScopeIDs    []int           `json:"func_scope_ids"` // 授权给第三方平台的权限集id
+	Info            json.RawMessage `json:"info,omitempty"` // 获取授权方信息接口返回的authorizer_info
+	AuthorizedAt    time.Time       `json:"authorized_at"`  // 首次授权时间
+	UpdatedAt       time.Time       `json:"updated_at"`     // 最近一次授权或更新授权的时间
+}
+
+// ErrAuthorizerInfoNotSupported 存储不支持授权方授权记录
+var ErrAuthorizerInfoNotSupported = errors.New("storage does not support authorizer info")
+
+// AuthorizerInfoStorage 授权方授权记录存储能力，是存储的可选实现
+// 内置的文件、Redis、数据库、SQLite存储均已实现
+type AuthorizerInfoStorage interface {
+	SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error
+	GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) // 不存在时返回nil
+	DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error
+}
+
 // TokenStorage 令牌存储接口
 type TokenStorage interface {
 	// 组件令牌相关方法
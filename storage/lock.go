@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultLockTTL token刷新锁的默认有效期
+// 持有者在刷新期间每隔 1/3 有效期续期一次；持有锁的实例崩溃时，其他实例最多等待该时长后重新获取
+const DefaultLockTTL = 30 * time.Second
+
+// DefaultLockWait 等待获取token刷新锁的最长时间
+// 持有者刷新期间会续期，等待时间需覆盖一次包含重试和故障转移的完整刷新
+const DefaultLockWait = 2 * time.Minute
+
+// lockRetryInterval 锁被占用时重试获取的间隔
+const lockRetryInterval = 50 * time.Millisecond
+
+// ErrLockNotSupported 存储不支持分布式锁
+var ErrLockNotSupported = errors.New("storage does not support locking")
+
+// ErrLockLost 锁的租约已过期，可能已被其他持有者获取
+var ErrLockLost = errors.New("lock lost")
+
+// Locker 分布式锁能力，是存储的可选实现
+// 多个实例共享同一存储时，token刷新先获取锁，获取后重新读取存储，仍需刷新时才调用微信接口，
+// 避免多个实例同时刷新导致彼此的token失效
+type Locker interface {
+	// Lock 获取名为name的锁，锁被占用时等待，直到获取成功或ctx结束
+	// ttl 为锁的有效期，持有者未释放时到期自动失效
+	Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock 已获取的锁
+type Lock interface {
+	// Fence 防护令牌，同一个锁每次被获取时单调递增，可用于识别过期的持有者
+	Fence() int64
+
+	// Unlock 释放锁，锁已过期并被其他持有者获取时不影响新的持有者
+	Unlock(ctx context.Context) error
+}
+
+// RenewableLock 可续期的锁，是 Lock 的可选实现
+// 内置的Redis和数据库锁均已实现；文件锁随文件句柄持有，不会过期，无需续期
+type RenewableLock interface {
+	Lock
+
+	// Renew 将锁的有效期延长为从现在起的ttl，锁已过期或被其他持有者获取时返回 ErrLockLost
+	Renew(ctx context.Context, ttl time.Duration) error
+}
+
+// WithLock 持有分布式锁执行fn，存储不支持加锁时直接执行
+// 锁实现 RenewableLock 时，fn执行期间定期续期，续期失败时取消fn的ctx并返回 ErrLockLost，
+// 避免租约过期后与新的持有者同时写入存储
+// @param ctx context.Context 上下文
+// @param s TokenStorage 存储实例，实现 Locker 时加锁
+// @param name string 锁名称，如 core.RefreshKey 返回的刷新key
+// @param fn func(ctx context.Context) (T, error) 持有锁时执行的函数
+// @return T fn的返回值
+// @return error 获取锁失败或fn返回的错误
+func WithLock[T any](ctx context.Context, s TokenStorage, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	return withLock(ctx, s, name, DefaultLockTTL, DefaultLockWait, fn)
+}
+
+// withLock 按指定的有效期和等待时间持有锁执行fn
+func withLock[T any](ctx context.Context, s TokenStorage, name string, ttl, wait time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	locker, ok := s.(Locker)
+	if !ok {
+		return fn(ctx)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	lock, err := locker.Lock(waitCtx, name, ttl)
+	cancel()
+	if errors.Is(err, ErrLockNotSupported) {
+		return fn(ctx)
+	}
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	defer func() { _ = lock.Unlock(context.WithoutCancel(ctx)) }()
+
+	renewable, ok := lock.(RenewableLock)
+	if !ok {
+		return fn(ctx)
+	}
+	return holdLock(ctx, renewable, ttl, fn)
+}
+
+// holdLock 执行fn期间每隔 ttl/3 续期一次
+// 续期出错时在下一周期重试，租约确认丢失或自上次续期成功起已超过ttl时取消fn
+func holdLock[T any](ctx context.Context, lock RenewableLock, ttl time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		renewed := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			now := time.Now()
+			err := lock.Renew(fnCtx, ttl)
+			if err == nil {
+				renewed = now
+				continue
+			}
+			if errors.Is(err, ErrLockLost) || now.Sub(renewed) >= ttl {
+				cancel(fmt.Errorf("%w: %v", ErrLockLost, err))
+				return
+			}
+		}
+	}()
+
+	result, err := fn(fnCtx)
+	close(done)
+	<-stopped
+	if cause := context.Cause(fnCtx); errors.Is(cause, ErrLockLost) {
+		var zero T
+		return zero, cause
+	}
+	return result, err
+}
+
+// RefreshWithLock 刷新token，进程内并发的刷新合并为一次，存储实现 Locker 时实例间同一时刻只有一个刷新
+// fn 应在获取锁后重新读取存储，其他实例已刷新时直接返回存储中的token
+// @param ctx context.Context 上下文
+// @param group *core.RefreshGroup 进程内的刷新去重组
+// @param s TokenStorage 存储实例
+// @param key string 刷新key，同时作为锁名称，见 core.RefreshKey
+// @param fn func(ctx context.Context) (T, error) 刷新函数
+// @return T 刷新结果
+// @return error 获取锁失败或刷新失败
+func RefreshWithLock[T any](ctx context.Context, group *core.RefreshGroup, s TokenStorage, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	return core.RefreshOnce(ctx, group, key, func(ctx context.Context) (T, error) {
+		return WithLock(ctx, s, key, fn)
+	})
+}
+
+// waitLock 轮询获取锁，直到获取成功或ctx结束
+func waitLock(ctx context.Context, try func() (Lock, bool, error)) (Lock, error) {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, ok, err := try()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return lock, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// newLockOwner 生成锁持有者标识
+func newLockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// leaseLock 基于数据库行的租约锁，MySQL和SQLite存储共用
+// 锁行不存在或租约已过期时获取，每次获取时fence加1；释放时将租约置为过期而不删除行，以保持fence单调递增
+type leaseLock struct {
+	db    *gorm.DB
+	model interface{}
+	name  string
+	owner string
+	fence int64
+}
+
+// Fence 实现 Lock 接口
+func (l *leaseLock) Fence() int64 {
+	return l.fence
+}
+
+// Renew 实现 RenewableLock 接口
+func (l *leaseLock) Renew(ctx context.Context, ttl time.Duration) error {
+	result := l.db.WithContext(ctx).Model(l.model).
+		Where("name = ? AND owner = ? AND fence = ?", l.name, l.owner, l.fence).
+		Update("expires_at", time.Now().Add(ttl))
+	if result.Error != nil {
+		return fmt.Errorf("failed to renew lock: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Unlock 实现 Lock 接口
+func (l *leaseLock) Unlock(ctx context.Context) error {
+	err := l.db.WithContext(ctx).Model(l.model).
+		Where("name = ? AND owner = ? AND fence = ?", l.name, l.owner, l.fence).
+		Update("expires_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// lockName 返回命名空间内的锁名称
+func lockName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + ":" + name
+}
+
+// lockRow 获取数据库行锁
+// @param model interface{} 锁表模型，如 &DBLock{}
+func lockRow(ctx context.Context, db *gorm.DB, model interface{}, name string, ttl time.Duration) (Lock, error) {
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	return waitLock(ctx, func() (Lock, bool, error) {
+		now := time.Now()
+		values := map[string]interface{}{"owner": owner, "expires_at": now.Add(ttl)}
+		acquired := false
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// 租约已过期时接管锁
+			values["fence"] = gorm.Expr("fence + 1")
+			result := tx.Model(model).Where("name = ? AND expires_at < ?", name, now).Updates(values)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				// 锁行不存在时创建，已存在则说明锁被占用
+				values["name"] = name
+				values["fence"] = 1
+				result = tx.Model(model).Clauses(clause.OnConflict{DoNothing: true}).Create(values)
+				if result.Error != nil {
+					return result.Error
+				}
+			}
+			acquired = result.RowsAffected > 0
+			return nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if !acquired {
+			return nil, false, nil
+		}
+
+		lock := &leaseLock{db: db, model: model, name: name, owner: owner}
+		if err := db.WithContext(ctx).Model(model).Select("fence").Where("name = ? AND owner = ?", name, owner).
+			Row().Scan(&lock.fence); err != nil {
+			return nil, false, fmt.Errorf("failed to read lock fence: %w", err)
+		}
+		return lock, true, nil
+	})
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileStorageLock(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	lock, err := store.Lock(ctx, "access_token:wx1", time.Second)
+	if errors.Is(err, ErrLockNotSupported) {
+		t.Skip("flock is not supported on this platform")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 锁被占用时等待直到ctx结束
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(waitCtx, "access_token:wx1", time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := store.Lock(ctx, "access_token:wx1", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer next.Unlock(ctx)
+	if next.Fence() <= lock.Fence() {
+		t.Fatalf("fence should increase: %d -> %d", lock.Fence(), next.Fence())
+	}
+}
+
+func TestWithLockIsExclusive(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	instrumented := NewInstrumentedStorage(store, nil, nil)
+
+	var running, overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := WithLock(context.Background(), instrumented, "component_access_token:wx1", func(ctx context.Context) (bool, error) {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return true, nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps != 0 {
+		t.Fatalf("lock holders overlapped %d times", overlaps)
+	}
+}
+
+// fakeRedisLocks 在内存中执行锁相关的Redis脚本，键按PX到期
+type fakeRedisLocks struct {
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	fences  map[string]int64
+}
+
+func newFakeRedisLocks() *fakeRedisLocks {
+	return &fakeRedisLocks{values: map[string]string{}, expires: map[string]time.Time{}, fences: map[string]int64{}}
+}
+
+func (f *fakeRedisLocks) get(key string) (string, bool) {
+	if time.Now().After(f.expires[key]) {
+		delete(f.values, key)
+	}
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func (f *fakeRedisLocks) eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.get(keys[0])
+	switch script {
+	case lockScript:
+		if ok {
+			return int64(0), nil
+		}
+		f.values[keys[0]] = args[0].(string)
+		f.expires[keys[0]] = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		f.fences[keys[1]]++
+		return f.fences[keys[1]], nil
+	case renewScript:
+		if !ok || value != args[0].(string) {
+			return int64(0), nil
+		}
+		f.expires[keys[0]] = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		return int64(1), nil
+	case unlockScript:
+		if !ok || value != args[0].(string) {
+			return int64(0), nil
+		}
+		delete(f.values, keys[0])
+		return int64(1), nil
+	}
+	return nil, errors.New("unexpected script")
+}
+
+// expire 模拟持有者停顿导致租约过期
+func (f *fakeRedisLocks) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key := range f.expires {
+		f.expires[key] = time.Now()
+	}
+}
+
+func TestWithLockRenewsLeaseDuringRefresh(t *testing.T) {
+	locks := newFakeRedisLocks()
+	store := &RedisStorage{keyPrefix: "wego:", eval: locks.eval}
+	ctx := context.Background()
+	const ttl = 60 * time.Millisecond
+	key := "component_access_token:wx1"
+
+	// 刷新耗时超过租约有效期，续期使其他实例无法获取锁
+	_, err := withLock(ctx, store, key, ttl, time.Second, func(ctx context.Context) (bool, error) {
+		time.Sleep(ttl * 2)
+		waitCtx, cancel := context.WithTimeout(ctx, ttl*2)
+		defer cancel()
+		if _, err := store.Lock(waitCtx, key, ttl); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("lock should still be held after the original lease, got %v", err)
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 租约在刷新中途过期并被其他实例获取，fn被取消并返回 ErrLockLost
+	var taken Lock
+	_, err = withLock(ctx, store, key, ttl, time.Second, func(ctx context.Context) (bool, error) {
+		locks.expire()
+		if taken, err = store.Lock(ctx, key, time.Second); err != nil {
+			return false, err
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Second):
+			t.Error("refresh should be cancelled after the lease is lost")
+			return true, nil
+		}
+	})
+	if !errors.Is(err, ErrLockLost) {
+		t.Fatalf("expected ErrLockLost, got %v", err)
+	}
+	if taken == nil {
+		t.Fatal("expected the lock to be taken over")
+	}
+
+	// 过期持有者的释放不影响新的持有者
+	waitCtx, cancel := context.WithTimeout(ctx, ttl)
+	defer cancel()
+	if _, err := store.Lock(waitCtx, key, ttl); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("new holder should keep the lock, got %v", err)
+	}
+	if err := taken.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
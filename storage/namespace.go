@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OfficialAccountNamespace 公众号自身token的命名空间，与第三方平台的授权方令牌分开存储
+const OfficialAccountNamespace = "official_account"
+
+// ErrNamespaceNotSupported 存储不支持命名空间
+var ErrNamespaceNotSupported = errors.New("storage does not support namespace")
+
+// NamespacedStorage 按命名空间隔离数据的能力，是存储的可选实现
+// 内置的文件、Redis、数据库、SQLite存储均已实现。多个第三方平台共用同一存储时，
+// 每个平台使用 ComponentNamespace 隔离组件令牌、验证票据、预授权码和授权方令牌
+type NamespacedStorage interface {
+	TokenStorage
+
+	// Namespace 返回命名空间内的存储视图，与原存储共享连接，namespace为空或已是该命名空间的视图时返回原存储，
+	// 因此对同一存储重复调用 Scope 不会嵌套命名空间
+	Namespace(namespace string) TokenStorage
+}
+
+// ComponentNamespace 返回第三方平台的命名空间
+// @param componentAppID string 第三方平台appid
+// @return string 命名空间
+func ComponentNamespace(componentAppID string) string {
+	return "component_" + componentAppID
+}
+
+// Scope 返回存储在命名空间内的视图
+// @param storage TokenStorage 存储实例，可以是 *InstrumentedStorage
+// @param namespace string 命名空间，如 ComponentNamespace 的返回值、OfficialAccountNamespace
+// @return TokenStorage 命名空间内的存储
+// @return error 存储未实现 NamespacedStorage 时返回 ErrNamespaceNotSupported
+func Scope(storage TokenStorage, namespace string) (TokenStorage, error) {
+	if instrumented, ok := storage.(*InstrumentedStorage); ok {
+		scoped, err := Scope(instrumented.storage, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return NewInstrumentedStorage(scoped, instrumented.metrics, instrumented.tracer), nil
+	}
+
+	namespaced, ok := storage.(NamespacedStorage)
+	if !ok {
+		return nil, ErrNamespaceNotSupported
+	}
+	return namespaced.Namespace(namespace), nil
+}
+
+// MigrateComponent 将默认命名空间中的第三方平台数据复制到 ComponentNamespace(componentAppID)
+// 用于升级前只运行一个第三方平台、未使用命名空间的存储。复制组件令牌、预授权码、未过期的验证票据、
+// 授权方令牌及授权记录、第三方平台的上一次EncodingAESKey；没有refresh_token的授权方令牌是公众号自身的access_token，不复制。
+// 命名空间中已有的数据不会被覆盖，可以重复执行；默认命名空间中的数据保留，确认无误后可自行删除
+// @param ctx context.Context 上下文
+// @param storage TokenStorage 存储实例，需实现 NamespacedStorage
+// @param componentAppID string 默认命名空间中数据所属的第三方平台appid
+// @return error 错误信息
+func MigrateComponent(ctx context.Context, storage TokenStorage, componentAppID string) error {
+	target, err := Scope(storage, ComponentNamespace(componentAppID))
+	if err != nil {
+		return err
+	}
+
+	if err := migrateComponentToken(ctx, storage, target); err != nil {
+		return fmt.Errorf("迁移component_access_token失败: %w", err)
+	}
+	if err := migratePreAuthCode(ctx, storage, target); err != nil {
+		return fmt.Errorf("迁移预授权码失败: %w", err)
+	}
+	if err := migrateVerifyTicket(ctx, storage, target); err != nil {
+		return fmt.Errorf("迁移验证票据失败: %w", err)
+	}
+	if err := migratePrevEncodingAESKey(ctx, storage, target, componentAppID); err != nil {
+		return fmt.Errorf("迁移上一次EncodingAESKey失败: %w", err)
+	}
+
+	authorizerAppIDs, err := storage.ListAuthorizerTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("获取授权方列表失败: %w", err)
+	}
+	for _, authorizerAppID := range authorizerAppIDs {
+		if err := migrateAuthorizer(ctx, storage, target, authorizerAppID); err != nil {
+			return fmt.Errorf("迁移授权方 %s 失败: %w", authorizerAppID, err)
+		}
+	}
+	return nil
+}
+
+// MigrateOfficialAccount 将默认命名空间中公众号自身的数据复制到 OfficialAccountNamespace
+// 复制access_token、稳定版access_token和上一次EncodingAESKey，规则与 MigrateComponent 相同。
+// 不迁移时公众号客户端读取默认命名空间中的access_token直到过期，稳定版access_token会重新获取
+// @param ctx context.Context 上下文
+// @param storage TokenStorage 存储实例，需实现 NamespacedStorage
+// @param appID string 公众号appid
+// @return error 错误信息
+func MigrateOfficialAccount(ctx context.Context, storage TokenStorage, appID string) error {
+	target, err := Scope(storage, OfficialAccountNamespace)
+	if err != nil {
+		return err
+	}
+
+	token, err := storage.GetAuthorizerToken(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("获取公众号access_token失败: %w", err)
+	}
+	if token != nil && token.AuthorizerRefreshToken == "" {
+		if err := copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+			existing, err := target.GetAuthorizerToken(ctx, appID)
+			return existing != nil, err
+		}, func(ctx context.Context) error {
+			return target.SaveAuthorizerToken(ctx, appID, token)
+		}); err != nil {
+			return fmt.Errorf("迁移公众号access_token失败: %w", err)
+		}
+	}
+
+	if err := migrateStableToken(ctx, storage, target, appID); err != nil {
+		return fmt.Errorf("迁移稳定版access_token失败: %w", err)
+	}
+	if err := migratePrevEncodingAESKey(ctx, storage, target, appID); err != nil {
+		return fmt.Errorf("迁移上一次EncodingAESKey失败: %w", err)
+	}
+	return nil
+}
+
+// copyIfMissing 目标中不存在数据时执行复制
+func copyIfMissing(ctx context.Context, exists func(ctx context.Context) (bool, error), save func(ctx context.Context) error) error {
+	ok, err := exists(ctx)
+	if err != nil || ok {
+		return err
+	}
+	return save(ctx)
+}
+
+// migrateComponentToken 复制组件令牌
+func migrateComponentToken(ctx context.Context, source, target TokenStorage) error {
+	token, err := source.GetComponentToken(ctx)
+	if err != nil || token == nil {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := target.GetComponentToken(ctx)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return target.SaveComponentToken(ctx, token)
+	})
+}
+
+// migratePreAuthCode 复制预授权码
+func migratePreAuthCode(ctx context.Context, source, target TokenStorage) error {
+	code, err := source.GetPreAuthCode(ctx)
+	if err != nil || code == nil {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := target.GetPreAuthCode(ctx)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return target.SavePreAuthCode(ctx, code)
+	})
+}
+
+// verifyTicketPutter 按原有时间保存验证票据，内置存储均已实现
+type verifyTicketPutter interface {
+	putComponentVerifyTicket(ctx context.Context, ticket *ComponentVerifyTicket) error
+}
+
+// putComponentVerifyTicket 保留票据的创建时间和过期时间保存，存储未实现时按票据内容重新保存
+func putComponentVerifyTicket(ctx context.Context, storage TokenStorage, ticket *ComponentVerifyTicket) error {
+	if putter, ok := storage.(verifyTicketPutter); ok {
+		return putter.putComponentVerifyTicket(ctx, ticket)
+	}
+	return storage.SaveComponentVerifyTicket(ctx, ticket.Ticket)
+}
+
+// migrateVerifyTicket 复制未过期的验证票据，保留原有的创建时间和过期时间
+// 已过期的票据不复制，由微信下次推送
+func migrateVerifyTicket(ctx context.Context, source, target TokenStorage) error {
+	ticket, err := source.GetComponentVerifyTicket(ctx)
+	if err != nil || ticket == nil || ticket.Ticket == "" || !time.Now().Before(ticket.ExpiresAt) {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := target.GetComponentVerifyTicket(ctx)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return putComponentVerifyTicket(ctx, target, ticket)
+	})
+}
+
+// migratePrevEncodingAESKey 复制appid的上一次EncodingAESKey
+func migratePrevEncodingAESKey(ctx context.Context, source, target TokenStorage, appID string) error {
+	key, err := source.GetPrevEncodingAESKey(ctx, appID)
+	if err != nil || key == nil {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := target.GetPrevEncodingAESKey(ctx, appID)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return target.SavePrevEncodingAESKey(ctx, appID, key.PrevEncodingAESKey)
+	})
+}
+
+// migrateStableToken 复制稳定版access_token，存储不支持时跳过
+func migrateStableToken(ctx context.Context, source, target TokenStorage, appID string) error {
+	from, ok := source.(StableTokenStorage)
+	if !ok {
+		return nil
+	}
+	to, ok := target.(StableTokenStorage)
+	if !ok {
+		return nil
+	}
+
+	token, err := from.GetStableToken(ctx, appID)
+	if errors.Is(err, ErrStableTokenNotSupported) {
+		return nil
+	}
+	if err != nil || token == nil {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := to.GetStableToken(ctx, appID)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return to.SaveStableToken(ctx, appID, token)
+	})
+}
+
+// migrateAuthorizer 复制授权方令牌和授权记录，授权记录存储不支持时跳过
+func migrateAuthorizer(ctx context.Context, source, target TokenStorage, authorizerAppID string) error {
+	token, err := source.GetAuthorizerToken(ctx, authorizerAppID)
+	if err != nil || token == nil || token.AuthorizerRefreshToken == "" {
+		return err
+	}
+	if err := copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := target.GetAuthorizerToken(ctx, authorizerAppID)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return target.SaveAuthorizerToken(ctx, authorizerAppID, token)
+	}); err != nil {
+		return err
+	}
+
+	from, ok := source.(AuthorizerInfoStorage)
+	if !ok {
+		return nil
+	}
+	to, ok := target.(AuthorizerInfoStorage)
+	if !ok {
+		return nil
+	}
+	info, err := from.GetAuthorizerInfo(ctx, authorizerAppID)
+	if errors.Is(err, ErrAuthorizerInfoNotSupported) {
+		return nil
+	}
+	if err != nil || info == nil {
+		return err
+	}
+	return copyIfMissing(ctx, func(ctx context.Context) (bool, error) {
+		existing, err := to.GetAuthorizerInfo(ctx, authorizerAppID)
+		return existing != nil, err
+	}, func(ctx context.Context) error {
+		return to.SaveAuthorizerInfo(ctx, authorizerAppID, info)
+	})
+}
+
+// inNamespace 判断current是否已是namespace的视图，包括嵌套在其他命名空间内的情况
+func inNamespace(current, namespace string) bool {
+	return current == namespace || strings.HasSuffix(current, "/"+namespace)
+}
+
+// joinNamespace 拼接嵌套的命名空间
+func joinNamespace(parent, namespace string) string {
+	if parent == "" {
+		return namespace
+	}
+	return parent + "/" + namespace
+}
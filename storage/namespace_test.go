@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+func TestFileStorageNamespaceIsolation(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var _ NamespacedStorage = store
+	first, err := Scope(store, ComponentNamespace("wx_component_1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Scope(NewInstrumentedStorage(store, nil, nil), ComponentNamespace("wx_component_2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := second.(*InstrumentedStorage); !ok {
+		t.Fatalf("scoping an instrumented storage should keep instrumentation, got %T", second)
+	}
+	// 重复指定同一命名空间不会嵌套
+	if again, err := Scope(first, ComponentNamespace("wx_component_1")); err != nil || again != first {
+		t.Fatalf("scoping twice should return the same view, got %v, %v", again, err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	if err := first.SaveComponentToken(ctx, &ComponentAccessToken{AccessToken: "component_1", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.SaveAuthorizerToken(ctx, "wx_auth", &AuthorizerAccessToken{AuthorizerAccessToken: "auth_1", AuthorizerRefreshToken: "refresh_1", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.SaveAuthorizerToken(ctx, "wx_auth", &AuthorizerAccessToken{AuthorizerAccessToken: "auth_2", AuthorizerRefreshToken: "refresh_2", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一授权方在两个第三方平台下的令牌互不影响，默认命名空间中没有数据
+	if token, err := first.GetAuthorizerToken(ctx, "wx_auth"); err != nil || token == nil || token.AuthorizerAccessToken != "auth_1" {
+		t.Fatalf("unexpected token in first namespace: %+v, %v", token, err)
+	}
+	if token, err := second.GetAuthorizerToken(ctx, "wx_auth"); err != nil || token == nil || token.AuthorizerAccessToken != "auth_2" {
+		t.Fatalf("unexpected token in second namespace: %+v, %v", token, err)
+	}
+	if token, err := second.GetComponentToken(ctx); err != nil || token != nil {
+		t.Fatalf("expected no component token in second namespace, got %+v, %v", token, err)
+	}
+	if appids, err := store.ListAuthorizerTokens(ctx); err != nil || len(appids) != 0 {
+		t.Fatalf("expected default namespace to be empty, got %v, %v", appids, err)
+	}
+	if err := second.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 命名空间中的锁与默认命名空间互不影响
+	key := core.RefreshKey(core.TokenKindComponentAccessToken, "wx_component_1")
+	lock, err := first.(Locker).Lock(ctx, key, DefaultLockTTL)
+	if errors.Is(err, ErrLockNotSupported) {
+		t.Skip("file locks are not supported on this platform")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock(ctx)
+	other, err := store.Lock(ctx, key, DefaultLockTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = other.Unlock(ctx)
+}
+
+func TestMigrateToNamespace(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	if err := store.SaveComponentToken(ctx, &ComponentAccessToken{AccessToken: "component", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	// 票据在一小时前收到，迁移后仍按原来的时间过期
+	received := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := store.putComponentVerifyTicket(ctx, &ComponentVerifyTicket{Ticket: "ticket", CreatedAt: received, ExpiresAt: received.Add(12 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveAuthorizerToken(ctx, "wx_auth", &AuthorizerAccessToken{AuthorizerAccessToken: "auth", AuthorizerRefreshToken: "refresh", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveAuthorizerInfo(ctx, "wx_auth", &AuthorizerInfo{AuthorizerAppID: "wx_auth", FuncScopeIDs: []int{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	// 旧版本公众号的access_token与授权方令牌保存在一起，没有refresh_token
+	if err := store.SaveAuthorizerToken(ctx, "wx_mp", &AuthorizerAccessToken{AuthorizerAccessToken: "mp", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveStableToken(ctx, "wx_mp", &StableAccessToken{AppID: "wx_mp", AccessToken: "stable", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	component, err := Scope(store, ComponentNamespace("wx_component"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 命名空间中已有的数据不被覆盖
+	if err := component.SaveComponentToken(ctx, &ComponentAccessToken{AccessToken: "newer", ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := MigrateComponent(ctx, store, "wx_component"); err != nil {
+			t.Fatal(err)
+		}
+		if err := MigrateOfficialAccount(ctx, store, "wx_mp"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if token, err := component.GetComponentToken(ctx); err != nil || token == nil || token.AccessToken != "newer" {
+		t.Fatalf("existing component token should be kept, got %+v, %v", token, err)
+	}
+	if ticket, err := component.GetComponentVerifyTicket(ctx); err != nil || ticket == nil || ticket.Ticket != "ticket" ||
+		!ticket.CreatedAt.Equal(received) || !ticket.ExpiresAt.Equal(received.Add(12*time.Hour)) {
+		t.Fatalf("unexpected verify ticket: %+v, %v", ticket, err)
+	}
+	if appids, err := component.ListAuthorizerTokens(ctx); err != nil || len(appids) != 1 || appids[0] != "wx_auth" {
+		t.Fatalf("expected only the authorizer to be migrated, got %v, %v", appids, err)
+	}
+	if info, err := component.(AuthorizerInfoStorage).GetAuthorizerInfo(ctx, "wx_auth"); err != nil || info == nil || len(info.FuncScopeIDs) != 2 {
+		t.Fatalf("unexpected authorizer info: %+v, %v", info, err)
+	}
+
+	official, err := Scope(store, OfficialAccountNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := official.GetAuthorizerToken(ctx, "wx_mp"); err != nil || token == nil || token.AuthorizerAccessToken != "mp" {
+		t.Fatalf("unexpected official account token: %+v, %v", token, err)
+	}
+	if token, err := official.(StableTokenStorage).GetStableToken(ctx, "wx_mp"); err != nil || token == nil || token.AccessToken != "stable" {
+		t.Fatalf("unexpected stable token: %+v, %v", token, err)
+	}
+
+	// 默认命名空间中的数据保留
+	if token, err := store.GetAuthorizerToken(ctx, "wx_auth"); err != nil || token == nil {
+		t.Fatalf("default namespace should be kept, got %+v, %v", token, err)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// RedisEvalFunc 执行Redis Lua脚本的函数
+// 以 go-redis 为例：
+//
+//	func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//	    return rdb.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisEvalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+// RedisRateLimitStore 基于Redis的限流状态存储，实现 core.RateLimitStore 接口
+// 令牌桶和当日计数通过Lua脚本原子更新，可在多个实例间共享限流状态
+//
+// 键命名规则：
+// - ratelimit:{appid}:{接口路径}: 令牌桶和当日计数（Hash）
+type RedisRateLimitStore struct {
+	eval      RedisEvalFunc
+	keyPrefix string
+}
+
+// rateLimitStateTTL 限流状态的过期时间，覆盖当日剩余时间
+const rateLimitStateTTL = 48 * time.Hour
+
+// takeScript 取出令牌并计入当日调用次数
+// 返回-1表示当日调用次数已达上限，0表示已取得令牌，大于0表示需要等待的毫秒数
+const takeScript = `
+local s = redis.call('HMGET', KEYS[1], 'tokens', 'last', 'day', 'used', 'quota')
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local daily = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local day = ARGV[5]
+local tokens = tonumber(s[1])
+local last = tonumber(s[2])
+local used = tonumber(s[4]) or 0
+local quota = tonumber(s[5]) or 0
+if s[3] ~= day then
+	used = 0
+	quota = 0
+end
+if quota <= 0 then
+	quota = daily
+end
+if quota > 0 and used >= quota then
+	return -1
+end
+if rate > 0 then
+	if tokens == nil or last == nil then
+		tokens = burst
+		last = now
+	end
+	tokens = math.min(burst, tokens + (now - last) / 1000 * rate)
+	if tokens < 1 then
+		return math.ceil((1 - tokens) / rate * 1000)
+	end
+	tokens = tokens - 1
+else
+	tokens = 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'last', now, 'day', day, 'used', used + 1)
+if s[3] ~= day then
+	redis.call('HDEL', KEYS[1], 'quota')
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[6])
+return 0
+`
+
+// syncDailyScript 同步当日的调用上限和已调用次数
+const syncDailyScript = `
+redis.call('HSET', KEYS[1], 'day', ARGV[1], 'quota', ARGV[2], 'used', ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 0
+`
+
+// NewRedisRateLimitStore 创建基于Redis的限流状态存储
+//
+// 参数:
+//
+//	eval: 执行Lua脚本的函数
+//	keyPrefix: 键前缀，默认"wego:"
+//
+// 返回:
+//
+//	*RedisRateLimitStore: 限流状态存储
+//	error: 参数无效时返回错误
+func NewRedisRateLimitStore(eval RedisEvalFunc, keyPrefix string) (*RedisRateLimitStore, error) {
+	if eval == nil {
+		return nil, fmt.Errorf("eval func cannot be nil")
+	}
+	if keyPrefix == "" {
+		keyPrefix = "wego:"
+	}
+	return &RedisRateLimitStore{
+		eval:      eval,
+		keyPrefix: keyPrefix,
+	}, nil
+}
+
+// Take 实现 core.RateLimitStore 接口
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit core.RateLimit, now time.Time) (time.Duration, error) {
+	result, err := s.eval(ctx, takeScript, []string{s.keyPrefix + "ratelimit:" + key},
+		limit.Rate, limit.BucketSize(), limit.Daily, now.UnixMilli(), core.QuotaDay(now), rateLimitStateTTL.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	wait, err := toInt64(result)
+	if err != nil {
+		return 0, err
+	}
+	if wait < 0 {
+		return 0, core.ErrDailyLimitReached
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// SyncDaily 实现 core.RateLimitStore 接口
+func (s *RedisRateLimitStore) SyncDaily(ctx context.Context, key string, quota, used int, now time.Time) error {
+	_, err := s.eval(ctx, syncDailyScript, []string{s.keyPrefix + "ratelimit:" + key},
+		core.QuotaDay(now), quota, used, rateLimitStateTTL.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to sync daily quota: %w", err)
+	}
+	return nil
+}
+
+// toInt64 转换Lua脚本的返回值
+func toInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		return int64(n), nil
+	}
+	return 0, fmt.Errorf("unexpected script result type: %T", v)
+}
@@ -20,17 +20,26 @@ import (
 // - verify_ticket: 验证票据
 // - authorizer_token:{appid}: 授权方令牌
 // - prev_aes_key:{appid}: 上一次的EncodingAESKey
+// - stable_token:{appid}: 公众号稳定版access_token
+// - authorizer_info:{appid}: 授权方授权记录
 // - authorizer_appids: 授权方appid集合
+// - lock:{name}: 分布式锁
+// - lock_fence:{name}: 分布式锁的防护令牌计数
+//
+// 命名空间内的键在前缀后加上 {namespace}:，如 wego:component_wx123:component_token
 
 type RedisStorage struct {
 	client    *redis.Instance // jcbaseGo Redis实例
 	keyPrefix string          // 键前缀，用于区分不同应用实例
+	eval      RedisEvalFunc   // 执行Lua脚本的函数，用于分布式锁
+	namespace string          // 命名空间，默认命名空间为空
 }
 
 // RedisConfig Redis存储配置选项
 type RedisConfig struct {
 	RedisInstance *redis.Instance // jcbaseGo Redis实例
 	KeyPrefix     string          // 键前缀，用于区分不同应用实例，默认"wego:"
+	Eval          RedisEvalFunc   // 执行Lua脚本的函数，设置后支持分布式锁，多实例部署时避免重复刷新token
 }
 
 // NewRedisStorage 创建Redis存储实例
@@ -67,6 +76,7 @@ func NewRedisStorage(config *RedisConfig) (*RedisStorage, error) {
 	return &RedisStorage{
 		client:    config.RedisInstance,
 		keyPrefix: config.KeyPrefix,
+		eval:      config.Eval,
 	}, nil
 }
 
@@ -83,6 +93,27 @@ func (s *RedisStorage) buildKey(parts ...string) string {
 	return s.keyPrefix + strings.Join(parts, ":")
 }
 
+// Namespace 实现 NamespacedStorage 接口，返回共享Redis连接、键前缀加上命名空间的存储
+//
+// 参数:
+//
+//	namespace: 命名空间，为空或已是该命名空间的视图时返回原存储
+//
+// 返回:
+//
+//	TokenStorage: 命名空间内的存储
+func (s *RedisStorage) Namespace(namespace string) TokenStorage {
+	if namespace == "" || inNamespace(s.namespace, namespace) {
+		return s
+	}
+	return &RedisStorage{
+		client:    s.client,
+		keyPrefix: s.buildKey(namespace) + ":",
+		eval:      s.eval,
+		namespace: joinNamespace(s.namespace, namespace),
+	}
+}
+
 // Ping 检查Redis连接状态
 //
 // 参数:
@@ -274,6 +305,11 @@ func (s *RedisStorage) SaveComponentVerifyTicket(ctx context.Context, ticket str
 		ExpiresAt: time.Now().Add(12 * time.Hour), // 12小时有效期
 	}
 
+	return s.putComponentVerifyTicket(ctx, ticketData)
+}
+
+// putComponentVerifyTicket 按原有的创建时间和过期时间保存验证票据，键在过期时间自动删除
+func (s *RedisStorage) putComponentVerifyTicket(ctx context.Context, ticketData *ComponentVerifyTicket) error {
 	data, err := json.Marshal(ticketData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal verify ticket: %w", err)
@@ -594,3 +630,284 @@ func (s *RedisStorage) DeletePrevEncodingAESKey(ctx context.Context, appID strin
 
 	return nil
 }
+
+// SaveStableToken 保存稳定版access_token到Redis
+//
+// 参数:
+//
+//	ctx: 上下文
+//	appID: 公众号应用ID
+//	token: 稳定版access_token信息
+//
+// 返回:
+//
+//	error: 保存失败时返回错误
+func (s *RedisStorage) SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error {
+	if appID == "" {
+		return fmt.Errorf("app id cannot be empty")
+	}
+	if token == nil {
+		return fmt.Errorf("token cannot be nil")
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stable access token: %w", err)
+	}
+
+	key := s.buildKey("stable_token", appID)
+	expireAt := time.Until(token.ExpiresAt)
+
+	if err := s.client.Set(key, string(data), expireAt); err != nil {
+		return fmt.Errorf("failed to save stable access token: %w", err)
+	}
+
+	return nil
+}
+
+// GetStableToken 从Redis获取稳定版access_token
+//
+// 参数:
+//
+//	ctx: 上下文
+//	appID: 公众号应用ID
+//
+// 返回:
+//
+//	*StableAccessToken: 稳定版access_token信息，不存在或过期返回nil
+//	error: 获取失败时返回错误
+func (s *RedisStorage) GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) {
+	if appID == "" {
+		return nil, fmt.Errorf("app id cannot be empty")
+	}
+
+	key := s.buildKey("stable_token", appID)
+
+	data, err := s.client.GetString(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stable access token: %w", err)
+	}
+	if data == "" {
+		return nil, nil
+	}
+
+	var token StableAccessToken
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stable access token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// DeleteStableToken 从Redis删除稳定版access_token
+//
+// 参数:
+//
+//	ctx: 上下文
+//	appID: 公众号应用ID
+//
+// 返回:
+//
+//	error: 删除失败时返回错误
+func (s *RedisStorage) DeleteStableToken(ctx context.Context, appID string) error {
+	if appID == "" {
+		return fmt.Errorf("app id cannot be empty")
+	}
+
+	key := s.buildKey("stable_token", appID)
+
+	if err := s.client.Del(key); err != nil {
+		return fmt.Errorf("failed to delete stable access token: %w", err)
+	}
+
+	return nil
+}
+
+// SaveAuthorizerInfo 保存授权方授权记录到Redis
+//
+// 参数:
+//
+//	ctx: 上下文
+//	authorizerAppID: 授权方应用ID
+//	info: 授权记录
+//
+// 返回:
+//
+//	error: 保存失败时返回错误
+func (s *RedisStorage) SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error {
+	if authorizerAppID == "" {
+		return fmt.Errorf("authorizer app id cannot be empty")
+	}
+	if info == nil {
+		return fmt.Errorf("authorizer info cannot be nil")
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorizer info: %w", err)
+	}
+
+	key := s.buildKey("authorizer_info", authorizerAppID)
+
+	// 授权记录在取消授权前一直有效，不设置过期时间
+	if err := s.client.Set(key, string(data), 0); err != nil {
+		return fmt.Errorf("failed to save authorizer info: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuthorizerInfo 从Redis获取授权方授权记录
+//
+// 参数:
+//
+//	ctx: 上下文
+//	authorizerAppID: 授权方应用ID
+//
+// 返回:
+//
+//	*AuthorizerInfo: 授权记录，不存在返回nil
+//	error: 获取失败时返回错误
+func (s *RedisStorage) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) {
+	if authorizerAppID == "" {
+		return nil, fmt.Errorf("authorizer app id cannot be empty")
+	}
+
+	key := s.buildKey("authorizer_info", authorizerAppID)
+
+	data, err := s.client.GetString(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorizer info: %w", err)
+	}
+	if data == "" {
+		return nil, nil
+	}
+
+	var info AuthorizerInfo
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal authorizer info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// DeleteAuthorizerInfo 从Redis删除授权方授权记录
+//
+// 参数:
+//
+//	ctx: 上下文
+//	authorizerAppID: 授权方应用ID
+//
+// 返回:
+//
+//	error: 删除失败时返回错误
+func (s *RedisStorage) DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error {
+	if authorizerAppID == "" {
+		return fmt.Errorf("authorizer app id cannot be empty")
+	}
+
+	key := s.buildKey("authorizer_info", authorizerAppID)
+
+	if err := s.client.Del(key); err != nil {
+		return fmt.Errorf("failed to delete authorizer info: %w", err)
+	}
+
+	return nil
+}
+
+// lockScript 以 SET NX PX 获取锁，获取成功时递增并返回防护令牌，锁被占用时返回0
+const lockScript = `
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+return redis.call('INCR', KEYS[2])
+`
+
+// unlockScript 仅当锁仍由当前持有者持有时删除
+const unlockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// renewScript 仅当锁仍由当前持有者持有时延长有效期，锁已丢失时返回0
+const renewScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// redisLock Redis分布式锁
+type redisLock struct {
+	storage *RedisStorage
+	key     string
+	owner   string
+	fence   int64
+}
+
+// Lock 实现 Locker 接口，需要配置 RedisConfig.Eval，未配置时返回 ErrLockNotSupported
+//
+// 参数:
+//
+//	ctx: 上下文，锁被占用时等待直到ctx结束
+//	name: 锁名称
+//	ttl: 锁的有效期
+//
+// 返回:
+//
+//	Lock: 已获取的锁
+//	error: 获取失败时返回错误
+func (s *RedisStorage) Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	if s.eval == nil {
+		return nil, ErrLockNotSupported
+	}
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.buildKey("lock", name)
+	fenceKey := s.buildKey("lock_fence", name)
+	return waitLock(ctx, func() (Lock, bool, error) {
+		result, err := s.eval(ctx, lockScript, []string{key, fenceKey}, owner, ttl.Milliseconds())
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		fence, err := toInt64(result)
+		if err != nil || fence == 0 {
+			return nil, false, err
+		}
+		return &redisLock{storage: s, key: key, owner: owner, fence: fence}, true, nil
+	})
+}
+
+// Fence 实现 Lock 接口
+func (l *redisLock) Fence() int64 {
+	return l.fence
+}
+
+// Renew 实现 RenewableLock 接口
+func (l *redisLock) Renew(ctx context.Context, ttl time.Duration) error {
+	result, err := l.storage.eval(ctx, renewScript, []string{l.key}, l.owner, ttl.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to renew lock: %w", err)
+	}
+	renewed, err := toInt64(result)
+	if err != nil {
+		return fmt.Errorf("failed to renew lock: %w", err)
+	}
+	if renewed == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Unlock 实现 Lock 接口
+func (l *redisLock) Unlock(ctx context.Context) error {
+	if _, err := l.storage.eval(ctx, unlockScript, []string{l.key}, l.owner); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -12,7 +13,8 @@ import (
 )
 
 type SqliteStorage struct {
-	db *gorm.DB
+	db        *gorm.DB
+	namespace string // 命名空间，为空时是默认命名空间
 }
 
 func NewSqliteStorage(conf jcbaseGo.SqlLiteStruct, opts ...string) (*SqliteStorage, error) {
@@ -27,15 +29,40 @@ func NewSqliteStorage(conf jcbaseGo.SqlLiteStruct, opts ...string) (*SqliteStora
 		&DBAuthorizerTokenSqlite{},
 		&DBPrevEncodingAESKeySqlite{},
 		&DBComponentVerifyTicketSqlite{},
+		&DBLockSqlite{},
+		&DBStableTokenSqlite{},
+		&DBAuthorizerInfoSqlite{},
+	); err != nil {
+		return nil, err
+	}
+	if err := dropAppIDUniqueIndexes(db,
+		&DBAuthorizerTokenSqlite{},
+		&DBPrevEncodingAESKeySqlite{},
+		&DBStableTokenSqlite{},
+		&DBAuthorizerInfoSqlite{},
 	); err != nil {
 		return nil, err
 	}
 	return &SqliteStorage{db: db}, nil
 }
 
+// Namespace 实现 NamespacedStorage 接口，返回共享数据库连接、按namespace列隔离的存储
+func (s *SqliteStorage) Namespace(namespace string) TokenStorage {
+	if namespace == "" || inNamespace(s.namespace, namespace) {
+		return s
+	}
+	return &SqliteStorage{db: s.db, namespace: joinNamespace(s.namespace, namespace)}
+}
+
+// scoped 返回限定在当前命名空间的查询
+func (s *SqliteStorage) scoped() *gorm.DB {
+	return s.db.Where("namespace = ?", s.namespace)
+}
+
 type DBComponentTokenSqlite struct {
 	base.SqliteBaseModel
 	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index" json:"namespace"`
 	AccessToken string    `gorm:"column:access_token;type:varchar(512);not null" json:"access_token"`
 	ExpiresIn   int       `gorm:"column:expires_in;not null" json:"expires_in"`
 	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
@@ -46,6 +73,7 @@ type DBComponentTokenSqlite struct {
 type DBPreAuthCodeSqlite struct {
 	base.SqliteBaseModel
 	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index" json:"namespace"`
 	PreAuthCode string    `gorm:"column:pre_auth_code;type:varchar(256);not null" json:"pre_auth_code"`
 	ExpiresIn   int       `gorm:"column:expires_in;not null" json:"expires_in"`
 	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
@@ -56,7 +84,8 @@ type DBPreAuthCodeSqlite struct {
 type DBAuthorizerTokenSqlite struct {
 	base.SqliteBaseModel
 	ID                     uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	AuthorizerAppID        string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex" json:"authorizer_app_id"`
+	Namespace              string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_authorizer_token_namespace" json:"namespace"`
+	AuthorizerAppID        string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex:idx_authorizer_token_namespace" json:"authorizer_app_id"`
 	AuthorizerAccessToken  string    `gorm:"column:authorizer_access_token;type:varchar(512);not null" json:"authorizer_access_token"`
 	AuthorizerRefreshToken string    `gorm:"column:authorizer_refresh_token;type:varchar(512)" json:"authorizer_refresh_token"`
 	ExpiresIn              int       `gorm:"column:expires_in;not null" json:"expires_in"`
@@ -68,7 +97,8 @@ type DBAuthorizerTokenSqlite struct {
 type DBPrevEncodingAESKeySqlite struct {
 	base.SqliteBaseModel
 	ID              uint      `gorm:"primaryKey"`
-	AppID           string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Namespace       string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_prev_aes_key_namespace" json:"namespace"`
+	AppID           string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_prev_aes_key_namespace"`
 	PrevEncodingKey string    `gorm:"column:prev_encoding_key;type:varchar(256);not null" json:"prev_encoding_key"`
 	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
@@ -77,19 +107,53 @@ type DBPrevEncodingAESKeySqlite struct {
 type DBComponentVerifyTicketSqlite struct {
 	base.SqliteBaseModel
 	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Namespace string    `gorm:"column:namespace;type:varchar(128);not null;default:'';index" json:"namespace"`
 	Ticket    string    `gorm:"column:ticket;type:varchar(512);not null" json:"ticket"`
 	ExpiresAt time.Time `gorm:"column:expires_at" json:"expires_at"`
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 }
 
+type DBLockSqlite struct {
+	base.SqliteBaseModel
+	Name      string    `gorm:"column:name;type:varchar(128);primaryKey" json:"name"`
+	Owner     string    `gorm:"column:owner;type:varchar(64);not null" json:"owner"`
+	Fence     int64     `gorm:"column:fence;not null;default:0" json:"fence"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null" json:"expires_at"`
+}
+
+type DBStableTokenSqlite struct {
+	base.SqliteBaseModel
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Namespace   string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_stable_token_namespace" json:"namespace"`
+	AppID       string    `gorm:"column:app_id;type:varchar(64);not null;uniqueIndex:idx_stable_token_namespace" json:"app_id"`
+	AccessToken string    `gorm:"column:access_token;type:varchar(512);not null" json:"access_token"`
+	ExpiresIn   int       `gorm:"column:expires_in;not null" json:"expires_in"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+type DBAuthorizerInfoSqlite struct {
+	base.SqliteBaseModel
+	ID              uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Namespace       string    `gorm:"column:namespace;type:varchar(128);not null;default:'';uniqueIndex:idx_authorizer_info_namespace" json:"namespace"`
+	AuthorizerAppID string    `gorm:"column:authorizer_app_id;type:varchar(64);not null;uniqueIndex:idx_authorizer_info_namespace" json:"authorizer_app_id"`
+	FuncScopeIDs    string    `gorm:"column:func_scope_ids;type:varchar(1024);not null" json:"func_scope_ids"`
+	Info            string    `gorm:"column:info;type:text" json:"info"`
+	AuthorizedAt    time.Time `gorm:"column:authorized_at;not null" json:"authorized_at"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
 func (s *SqliteStorage) SaveComponentToken(ctx context.Context, token *ComponentAccessToken) error {
 	dbToken := &DBComponentTokenSqlite{
+		Namespace:   s.namespace,
 		AccessToken: token.AccessToken,
 		ExpiresIn:   token.ExpiresIn,
 		ExpiresAt:   token.ExpiresAt,
 	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("1 = 1").Delete(&DBComponentTokenSqlite{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBComponentTokenSqlite{}).Error; err != nil {
 			return err
 		}
 		return tx.Create(dbToken).Error
@@ -98,7 +162,7 @@ func (s *SqliteStorage) SaveComponentToken(ctx context.Context, token *Component
 
 func (s *SqliteStorage) GetComponentToken(ctx context.Context) (*ComponentAccessToken, error) {
 	var dbToken DBComponentTokenSqlite
-	if err := s.db.Order("created_at DESC").First(&dbToken).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbToken).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -112,17 +176,18 @@ func (s *SqliteStorage) GetComponentToken(ctx context.Context) (*ComponentAccess
 }
 
 func (s *SqliteStorage) DeleteComponentToken(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBComponentTokenSqlite{}).Error
+	return s.scoped().Delete(&DBComponentTokenSqlite{}).Error
 }
 
 func (s *SqliteStorage) SavePreAuthCode(ctx context.Context, code *PreAuthCode) error {
 	dbCode := &DBPreAuthCodeSqlite{
+		Namespace:   s.namespace,
 		PreAuthCode: code.PreAuthCode,
 		ExpiresIn:   code.ExpiresIn,
 		ExpiresAt:   code.ExpiresAt,
 	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("1 = 1").Delete(&DBPreAuthCodeSqlite{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBPreAuthCodeSqlite{}).Error; err != nil {
 			return err
 		}
 		return tx.Create(dbCode).Error
@@ -131,7 +196,7 @@ func (s *SqliteStorage) SavePreAuthCode(ctx context.Context, code *PreAuthCode)
 
 func (s *SqliteStorage) GetPreAuthCode(ctx context.Context) (*PreAuthCode, error) {
 	var dbCode DBPreAuthCodeSqlite
-	if err := s.db.Order("created_at DESC").First(&dbCode).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbCode).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -145,23 +210,23 @@ func (s *SqliteStorage) GetPreAuthCode(ctx context.Context) (*PreAuthCode, error
 }
 
 func (s *SqliteStorage) DeletePreAuthCode(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBPreAuthCodeSqlite{}).Error
+	return s.scoped().Delete(&DBPreAuthCodeSqlite{}).Error
 }
 
 func (s *SqliteStorage) SaveAuthorizerToken(ctx context.Context, authorizerAppID string, token *AuthorizerAccessToken) error {
-	return s.db.Where(DBAuthorizerTokenSqlite{AuthorizerAppID: authorizerAppID}).
+	return s.scoped().Where(DBAuthorizerTokenSqlite{AuthorizerAppID: authorizerAppID}).
 		Assign(DBAuthorizerTokenSqlite{
 			AuthorizerAccessToken:  token.AuthorizerAccessToken,
 			AuthorizerRefreshToken: token.AuthorizerRefreshToken,
 			ExpiresIn:              token.ExpiresIn,
 			ExpiresAt:              token.ExpiresAt,
 		}).
-		FirstOrCreate(&DBAuthorizerTokenSqlite{}, DBAuthorizerTokenSqlite{AuthorizerAppID: authorizerAppID}).Error
+		FirstOrCreate(&DBAuthorizerTokenSqlite{}, DBAuthorizerTokenSqlite{Namespace: s.namespace, AuthorizerAppID: authorizerAppID}).Error
 }
 
 func (s *SqliteStorage) GetAuthorizerToken(ctx context.Context, authorizerAppID string) (*AuthorizerAccessToken, error) {
 	var dbToken DBAuthorizerTokenSqlite
-	if err := s.db.Where("authorizer_app_id = ?", authorizerAppID).First(&dbToken).Error; err != nil {
+	if err := s.scoped().Where("authorizer_app_id = ?", authorizerAppID).First(&dbToken).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -177,16 +242,16 @@ func (s *SqliteStorage) GetAuthorizerToken(ctx context.Context, authorizerAppID
 }
 
 func (s *SqliteStorage) DeleteAuthorizerToken(ctx context.Context, authorizerAppID string) error {
-	return s.db.Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerTokenSqlite{}).Error
+	return s.scoped().Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerTokenSqlite{}).Error
 }
 
 func (s *SqliteStorage) ClearAuthorizerTokens(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBAuthorizerTokenSqlite{}).Error
+	return s.scoped().Delete(&DBAuthorizerTokenSqlite{}).Error
 }
 
 func (s *SqliteStorage) ListAuthorizerTokens(ctx context.Context) ([]string, error) {
 	var tokens []DBAuthorizerTokenSqlite
-	if err := s.db.Select("authorizer_app_id").Find(&tokens).Error; err != nil {
+	if err := s.scoped().Select("authorizer_app_id").Find(&tokens).Error; err != nil {
 		return nil, err
 	}
 	appids := make([]string, len(tokens))
@@ -205,14 +270,14 @@ func (s *SqliteStorage) Ping(ctx context.Context) error {
 }
 
 func (s *SqliteStorage) SavePrevEncodingAESKey(ctx context.Context, appID string, prevKey string) error {
-	return s.db.Where(DBPrevEncodingAESKeySqlite{AppID: appID}).
+	return s.scoped().Where(DBPrevEncodingAESKeySqlite{AppID: appID}).
 		Assign(DBPrevEncodingAESKeySqlite{PrevEncodingKey: prevKey}).
-		FirstOrCreate(&DBPrevEncodingAESKeySqlite{}, DBPrevEncodingAESKeySqlite{AppID: appID}).Error
+		FirstOrCreate(&DBPrevEncodingAESKeySqlite{}, DBPrevEncodingAESKeySqlite{Namespace: s.namespace, AppID: appID}).Error
 }
 
 func (s *SqliteStorage) GetPrevEncodingAESKey(ctx context.Context, appID string) (*PrevEncodingAESKey, error) {
 	var dbKey DBPrevEncodingAESKeySqlite
-	if err := s.db.Where("app_id = ?", appID).First(&dbKey).Error; err != nil {
+	if err := s.scoped().Where("app_id = ?", appID).First(&dbKey).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -226,18 +291,86 @@ func (s *SqliteStorage) GetPrevEncodingAESKey(ctx context.Context, appID string)
 }
 
 func (s *SqliteStorage) DeletePrevEncodingAESKey(ctx context.Context, appID string) error {
-	return s.db.Where("app_id = ?", appID).Delete(&DBPrevEncodingAESKeySqlite{}).Error
+	return s.scoped().Where("app_id = ?", appID).Delete(&DBPrevEncodingAESKeySqlite{}).Error
+}
+
+func (s *SqliteStorage) SaveStableToken(ctx context.Context, appID string, token *StableAccessToken) error {
+	return s.scoped().Where(DBStableTokenSqlite{AppID: appID}).
+		Assign(DBStableTokenSqlite{
+			AccessToken: token.AccessToken,
+			ExpiresIn:   token.ExpiresIn,
+			ExpiresAt:   token.ExpiresAt,
+		}).
+		FirstOrCreate(&DBStableTokenSqlite{}, DBStableTokenSqlite{Namespace: s.namespace, AppID: appID}).Error
+}
+
+func (s *SqliteStorage) GetStableToken(ctx context.Context, appID string) (*StableAccessToken, error) {
+	var dbToken DBStableTokenSqlite
+	if err := s.scoped().Where("app_id = ?", appID).First(&dbToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &StableAccessToken{
+		AppID:       appID,
+		AccessToken: dbToken.AccessToken,
+		ExpiresIn:   dbToken.ExpiresIn,
+		ExpiresAt:   dbToken.ExpiresAt,
+	}, nil
+}
+
+func (s *SqliteStorage) DeleteStableToken(ctx context.Context, appID string) error {
+	return s.scoped().Where("app_id = ?", appID).Delete(&DBStableTokenSqlite{}).Error
+}
+
+func (s *SqliteStorage) SaveAuthorizerInfo(ctx context.Context, authorizerAppID string, info *AuthorizerInfo) error {
+	scopeIDs, err := json.Marshal(info.FuncScopeIDs)
+	if err != nil {
+		return err
+	}
+	return s.scoped().Where(DBAuthorizerInfoSqlite{AuthorizerAppID: authorizerAppID}).
+		Assign(DBAuthorizerInfoSqlite{
+			FuncScopeIDs: string(scopeIDs),
+			Info:         string(info.Info),
+			AuthorizedAt: info.AuthorizedAt,
+		}).
+		FirstOrCreate(&DBAuthorizerInfoSqlite{}, DBAuthorizerInfoSqlite{Namespace: s.namespace, AuthorizerAppID: authorizerAppID}).Error
+}
+
+func (s *SqliteStorage) GetAuthorizerInfo(ctx context.Context, authorizerAppID string) (*AuthorizerInfo, error) {
+	var dbInfo DBAuthorizerInfoSqlite
+	if err := s.scoped().Where("authorizer_app_id = ?", authorizerAppID).First(&dbInfo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeAuthorizerInfo(authorizerAppID, dbInfo.FuncScopeIDs, dbInfo.Info, dbInfo.AuthorizedAt, dbInfo.UpdatedAt)
+}
+
+func (s *SqliteStorage) DeleteAuthorizerInfo(ctx context.Context, authorizerAppID string) error {
+	return s.scoped().Where("authorizer_app_id = ?", authorizerAppID).Delete(&DBAuthorizerInfoSqlite{}).Error
 }
 
 func (s *SqliteStorage) SaveComponentVerifyTicket(ctx context.Context, ticket string) error {
 	expiresAt := time.Now().Add(12 * time.Hour)
-	dbTicket := &DBComponentVerifyTicketSqlite{
+	return s.putComponentVerifyTicket(ctx, &ComponentVerifyTicket{
 		Ticket:    ticket,
 		CreatedAt: time.Now(),
 		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *SqliteStorage) putComponentVerifyTicket(ctx context.Context, ticket *ComponentVerifyTicket) error {
+	dbTicket := &DBComponentVerifyTicketSqlite{
+		Namespace: s.namespace,
+		Ticket:    ticket.Ticket,
+		CreatedAt: ticket.CreatedAt,
+		ExpiresAt: ticket.ExpiresAt,
 	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("1 = 1").Delete(&DBComponentVerifyTicketSqlite{}).Error; err != nil {
+		if err := tx.Where("namespace = ?", s.namespace).Delete(&DBComponentVerifyTicketSqlite{}).Error; err != nil {
 			return err
 		}
 		return tx.Create(dbTicket).Error
@@ -246,7 +379,7 @@ func (s *SqliteStorage) SaveComponentVerifyTicket(ctx context.Context, ticket st
 
 func (s *SqliteStorage) GetComponentVerifyTicket(ctx context.Context) (*ComponentVerifyTicket, error) {
 	var dbTicket DBComponentVerifyTicketSqlite
-	if err := s.db.Order("created_at DESC").First(&dbTicket).Error; err != nil {
+	if err := s.scoped().Order("created_at DESC").First(&dbTicket).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -260,5 +393,10 @@ func (s *SqliteStorage) GetComponentVerifyTicket(ctx context.Context) (*Componen
 }
 
 func (s *SqliteStorage) DeleteComponentVerifyTicket(ctx context.Context) error {
-	return s.db.Where("1 = 1").Delete(&DBComponentVerifyTicketSqlite{}).Error
+	return s.scoped().Delete(&DBComponentVerifyTicketSqlite{}).Error
+}
+
+// Lock 实现 Locker 接口，基于 DBLockSqlite 表的行租约
+func (s *SqliteStorage) Lock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	return lockRow(ctx, s.db, &DBLockSqlite{}, lockName(s.namespace, name), ttl)
 }
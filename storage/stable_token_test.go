@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileStorageStableToken(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var _ StableTokenStorage = store
+	if token, err := store.GetStableToken(ctx, "wx1"); err != nil || token != nil {
+		t.Fatalf("expected no token, got %+v, %v", token, err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := store.SaveStableToken(ctx, "wx1", &StableAccessToken{AppID: "wx1", AccessToken: "stable_1", ExpiresIn: 7200, ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := store.GetStableToken(ctx, "wx1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == nil || token.AccessToken != "stable_1" || !token.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	// 稳定版token与授权方token互不影响
+	if authorizer, err := store.GetAuthorizerToken(ctx, "wx1"); err != nil || authorizer != nil {
+		t.Fatalf("expected no authorizer token, got %+v, %v", authorizer, err)
+	}
+
+	if err := store.DeleteStableToken(ctx, "wx1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteStableToken(ctx, "wx1"); err != nil {
+		t.Fatalf("deleting a missing token should not fail: %v", err)
+	}
+	if token, err := store.GetStableToken(ctx, "wx1"); err != nil || token != nil {
+		t.Fatalf("expected token to be deleted, got %+v, %v", token, err)
+	}
+}
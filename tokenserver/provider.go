@@ -0,0 +1,149 @@
+package tokenserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// Provider 从中控服务器获取token，实现 core.TokenProvider 接口
+//
+// 获取的token在本地缓存到服务器建议的时长；微信返回token失效时请求服务器强制刷新，
+// 服务器已刷新过时直接返回新token，客户端不会调用微信接口。
+type Provider struct {
+	serverURL string
+	clientID  string
+	secret    string
+
+	mu         sync.Mutex
+	httpClient core.HTTPClient
+	pullers    map[string]*core.HTTPTokenProvider // token类型 -> 带缓存的拉取器
+}
+
+// NewProvider 创建从中控服务器获取token的来源
+// @param serverURL string 中控服务器地址，即 Server 的挂载路径，如 https://token.example.com/wego
+// @param clientID string 客户端ID
+// @param secret string 客户端密钥
+// @return *Provider token来源
+func NewProvider(serverURL, clientID, secret string) *Provider {
+	return &Provider{
+		serverURL:  strings.TrimRight(serverURL, "/"),
+		clientID:   clientID,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		pullers:    make(map[string]*core.HTTPTokenProvider),
+	}
+}
+
+// SetHTTPClient 设置HTTP客户端，需要在使用前调用
+// @return *Provider token来源，便于链式调用
+func (p *Provider) SetHTTPClient(client core.HTTPClient) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client != nil {
+		p.httpClient = client
+		p.pullers = make(map[string]*core.HTTPTokenProvider)
+	}
+	return p
+}
+
+// AccessToken 实现 core.TokenProvider 接口，返回公众号或授权方的access_token
+func (p *Provider) AccessToken(ctx context.Context, appID string) (string, error) {
+	return p.Token(ctx, TypeAccessToken, appID)
+}
+
+// RefreshAccessToken 实现 core.TokenProvider 接口，请求服务器刷新失效的access_token
+func (p *Provider) RefreshAccessToken(ctx context.Context, appID, staleToken string) (string, error) {
+	return p.Refresh(ctx, TypeAccessToken, appID, staleToken)
+}
+
+// Token 获取token
+// @param ctx context.Context 上下文
+// @param tokenType string token类型，如 TypeAccessToken、TypeJSAPITicket
+// @param appID string 公众号、授权方或第三方平台的appid
+// @return string token或ticket
+// @return error 错误信息
+func (p *Provider) Token(ctx context.Context, tokenType, appID string) (string, error) {
+	return p.puller(tokenType).AccessToken(ctx, appID)
+}
+
+// Refresh 请求服务器刷新失效的token，服务器限流时返回错误
+// @param ctx context.Context 上下文
+// @param tokenType string token类型
+// @param appID string appid
+// @param staleToken string 失效的token
+// @return string 新的token
+// @return error 错误信息
+func (p *Provider) Refresh(ctx context.Context, tokenType, appID, staleToken string) (string, error) {
+	query := url.Values{"appid": {appID}, "type": {tokenType}, "stale": {staleToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.serverURL+"/refresh?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求中控服务器刷新token失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if _, err := decodeTokenResponse(body); err != nil {
+		return "", fmt.Errorf("中控服务器刷新token失败: %w", err)
+	}
+
+	// 清除本地缓存的失效token并重新获取
+	return p.puller(tokenType).RefreshAccessToken(ctx, appID, staleToken)
+}
+
+// client 返回为请求签名的HTTP客户端
+func (p *Provider) client() core.HTTPClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return &signingClient{clientID: p.clientID, secret: p.secret, client: p.httpClient}
+}
+
+// puller 返回token类型对应的拉取器
+func (p *Provider) puller(tokenType string) *core.HTTPTokenProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	puller, ok := p.pullers[tokenType]
+	if !ok {
+		query := url.Values{"type": {tokenType}}
+		puller = core.NewHTTPTokenProvider(p.serverURL + "/token?" + query.Encode()).
+			SetHTTPClient(&signingClient{clientID: p.clientID, secret: p.secret, client: p.httpClient}).
+			SetDecoder(decodeTokenResponse)
+		p.pullers[tokenType] = puller
+	}
+	return puller
+}
+
+// decodeTokenResponse 解析中控服务器的响应，ticket也作为 RemoteToken.AccessToken 返回
+func decodeTokenResponse(body []byte) (*core.RemoteToken, error) {
+	var resp TokenResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, core.NewError(&resp.APIResponse, "", http.StatusOK)
+	}
+
+	token := &core.RemoteToken{AccessToken: resp.AccessToken, ExpiresIn: resp.ExpiresIn}
+	if token.AccessToken == "" {
+		token.AccessToken = resp.Ticket
+	}
+	return token, nil
+}
@@ -0,0 +1,379 @@
+// Package tokenserver 提供中控服务器，集中维护公众号、授权方和第三方平台的token与ticket
+//
+// 持有AppSecret的服务运行 Server，其他服务通过 Provider 获取token，不需要持有AppSecret，
+// 也不会各自调用微信接口刷新而使彼此的token失效。请求使用HMAC签名，每个客户端只能获取允许的appid：
+//
+//	server := tokenserver.NewServer().
+//		AddOfficialAccount(officialAccountClient).
+//		SetOpenPlatform(openPlatformClient).
+//		AddClient("order-service", secret, "wx123")
+//	http.Handle("/wego/", server)
+//
+//	provider := tokenserver.NewProvider("https://token.example.com/wego", "order-service", secret)
+//	client := official_account.NewClient(config, provider)
+package tokenserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/openplatform"
+)
+
+// token类型
+const (
+	TypeAccessToken          = "access_token"           // 公众号或授权方access_token
+	TypeComponentAccessToken = "component_access_token" // 第三方平台component_access_token
+	TypeJSAPITicket          = "jsapi_ticket"           // 授权方jsapi_ticket
+	TypeWxCardTicket         = "wx_card_ticket"         // 授权方卡券ticket
+)
+
+// DefaultMaxAge 客户端缓存token的默认时长
+// 应小于微信新旧token共存的5分钟，服务器刷新token后客户端能在旧token失效前获取新token
+const DefaultMaxAge = 2 * time.Minute
+
+// DefaultRefreshLimit 强制刷新的默认限流规则：同一appid同一类型每分钟1次
+var DefaultRefreshLimit = core.RateLimit{Rate: 1.0 / 60, Burst: 1}
+
+// 获取token失败的原因
+var (
+	ErrAppIDNotAllowed = errors.New("客户端无权获取该appid的token")
+	ErrUnknownAppID    = errors.New("未配置该appid")
+	ErrUnsupportedType = errors.New("不支持的token类型")
+)
+
+// TokenResponse 中控服务器的响应，格式与微信获取token的接口相同
+// 失败时 errcode 为HTTP状态码，微信接口返回错误时为微信的错误码
+type TokenResponse struct {
+	core.APIResponse
+	AccessToken string `json:"access_token,omitempty"` // access_token或component_access_token
+	Ticket      string `json:"ticket,omitempty"`       // jsapi_ticket或卡券ticket
+	ExpiresIn   int    `json:"expires_in,omitempty"`   // 建议的缓存时长，单位秒
+}
+
+// client 允许访问的客户端
+type client struct {
+	id     string
+	secret string
+	appIDs map[string]bool // 允许获取的appid，包含"*"时允许全部
+}
+
+// allowed 检查客户端是否允许获取appid的token
+func (c *client) allowed(appID string) bool {
+	return c.appIDs["*"] || c.appIDs[appID]
+}
+
+// Server 中控服务器，实现 http.Handler
+//
+// 提供两个接口，可挂载在任意路径前缀下（不需要 http.StripPrefix）：
+//   - GET {prefix}/token?appid=APPID&type=TYPE 获取token，type默认为access_token
+//   - POST {prefix}/refresh?appid=APPID&type=TYPE&stale=STALE 报告失效的token并强制刷新，限流
+//
+// token从注册的客户端获取，由客户端的存储、刷新锁和 core.Refresher 维护。
+type Server struct {
+	mu               sync.RWMutex
+	officialAccounts map[string]*official_account.Client
+	openPlatform     *openplatform.Client
+	clients          map[string]*client
+
+	maxAge         time.Duration
+	signatureTTL   time.Duration
+	refreshLimiter *core.RateLimiter
+	nonces         nonceCache
+}
+
+// NewServer 创建中控服务器
+// @return *Server 中控服务器
+func NewServer() *Server {
+	return &Server{
+		officialAccounts: make(map[string]*official_account.Client),
+		clients:          make(map[string]*client),
+		maxAge:           DefaultMaxAge,
+		signatureTTL:     DefaultSignatureTTL,
+		refreshLimiter:   core.NewRateLimiter(nil).SetDefaultLimit(DefaultRefreshLimit),
+	}
+}
+
+// AddOfficialAccount 注册公众号，提供该公众号的access_token
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) AddOfficialAccount(client *official_account.Client) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.officialAccounts[client.GetConfig().AppID] = client
+	return s
+}
+
+// SetOpenPlatform 设置第三方平台，提供component_access_token、授权方access_token和ticket
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) SetOpenPlatform(client *openplatform.Client) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.openPlatform = client
+	return s
+}
+
+// AddClient 允许客户端访问
+// @param id string 客户端ID
+// @param secret string 客户端密钥，用于校验请求签名
+// @param appIDs ...string 允许获取的appid，"*"表示全部
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) AddClient(id, secret string, appIDs ...string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &client{id: id, secret: secret, appIDs: make(map[string]bool, len(appIDs))}
+	for _, appID := range appIDs {
+		c.appIDs[appID] = true
+	}
+	s.clients[id] = c
+	return s
+}
+
+// SetMaxAge 设置客户端缓存token的时长，默认 DefaultMaxAge
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) SetMaxAge(maxAge time.Duration) *Server {
+	if maxAge > 0 {
+		s.maxAge = maxAge
+	}
+	return s
+}
+
+// SetSignatureTTL 设置签名的有效期，默认 DefaultSignatureTTL
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) SetSignatureTTL(ttl time.Duration) *Server {
+	if ttl > 0 {
+		s.signatureTTL = ttl
+	}
+	return s
+}
+
+// SetRefreshLimiter 设置强制刷新的限流器，按appid和 "/refresh/{type}" 限流
+// 多实例部署时可传入使用共享存储的限流器，为nil时不限流
+// @return *Server 中控服务器，便于链式调用
+func (s *Server) SetRefreshLimiter(limiter *core.RateLimiter) *Server {
+	s.refreshLimiter = limiter
+	return s
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := path.Base(r.URL.Path)
+	switch {
+	case action == "token" && r.Method == http.MethodGet:
+	case action == "refresh" && r.Method == http.MethodPost:
+	case action == "token" || action == "refresh":
+		writeError(w, http.StatusMethodNotAllowed, errors.New("请求方法错误"))
+		return
+	default:
+		writeError(w, http.StatusNotFound, errors.New("接口不存在"))
+		return
+	}
+
+	c, err := s.verify(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	query := r.URL.Query()
+	appID := query.Get("appid")
+	tokenType := query.Get("type")
+	if tokenType == "" {
+		tokenType = TypeAccessToken
+	}
+	if appID == "" || !c.allowed(appID) {
+		writeError(w, http.StatusForbidden, ErrAppIDNotAllowed)
+		return
+	}
+
+	var token string
+	if action == "refresh" {
+		token, err = s.refresh(r.Context(), tokenType, appID, query.Get("stale"))
+	} else {
+		token, err = s.token(r.Context(), tokenType, appID)
+	}
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+
+	resp := &TokenResponse{ExpiresIn: int(s.maxAge / time.Second)}
+	if tokenType == TypeJSAPITicket || tokenType == TypeWxCardTicket {
+		resp.Ticket = token
+	} else {
+		resp.AccessToken = token
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// token 获取当前的token
+func (s *Server) token(ctx context.Context, tokenType, appID string) (string, error) {
+	switch tokenType {
+	case TypeAccessToken:
+		if official := s.officialAccount(appID); official != nil {
+			return official.GetAccessToken(ctx)
+		}
+		platform, err := s.platform()
+		if err != nil {
+			return "", err
+		}
+		return platform.GetAuthorizerAccessToken(ctx, appID)
+
+	case TypeComponentAccessToken:
+		platform, err := s.component(appID)
+		if err != nil {
+			return "", err
+		}
+		token, err := platform.GetComponentAccessToken(ctx, "")
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+
+	case TypeJSAPITicket, TypeWxCardTicket:
+		authorizer, ticketType, err := s.authorizer(tokenType, appID)
+		if err != nil {
+			return "", err
+		}
+		return authorizer.GetTicket(ctx, ticketType)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, tokenType)
+}
+
+// refresh 强制刷新token，stale为空时刷新当前的token
+// 当前token已不是stale时直接返回，不占用限流额度
+func (s *Server) refresh(ctx context.Context, tokenType, appID, stale string) (string, error) {
+	current, err := s.token(ctx, tokenType, appID)
+	if err != nil {
+		return "", err
+	}
+	if stale == "" {
+		stale = current
+	} else if current != stale {
+		return current, nil
+	}
+
+	if s.refreshLimiter != nil {
+		if err := s.refreshLimiter.Wait(core.WithRateLimitMode(ctx, core.RateLimitFailFast), appID, "/refresh/"+tokenType); err != nil {
+			return "", err
+		}
+	}
+
+	switch tokenType {
+	case TypeAccessToken:
+		if official := s.officialAccount(appID); official != nil {
+			return official.GetTokenProvider().RefreshAccessToken(ctx, appID, stale)
+		}
+		platform, err := s.platform()
+		if err != nil {
+			return "", err
+		}
+		return platform.GetTokenProvider().RefreshAccessToken(ctx, appID, stale)
+
+	case TypeComponentAccessToken:
+		platform, err := s.component(appID)
+		if err != nil {
+			return "", err
+		}
+		token, err := platform.RefreshComponentAccessToken(ctx, stale)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+
+	case TypeJSAPITicket, TypeWxCardTicket:
+		authorizer, ticketType, err := s.authorizer(tokenType, appID)
+		if err != nil {
+			return "", err
+		}
+		return authorizer.RefreshTicket(ctx, ticketType, stale)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, tokenType)
+}
+
+// officialAccount 返回注册的公众号客户端，未注册时返回nil
+func (s *Server) officialAccount(appID string) *official_account.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.officialAccounts[appID]
+}
+
+// platform 返回第三方平台客户端
+func (s *Server) platform() (*openplatform.Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.openPlatform == nil {
+		return nil, ErrUnknownAppID
+	}
+	return s.openPlatform, nil
+}
+
+// component 返回appid对应的第三方平台客户端
+func (s *Server) component(appID string) (*openplatform.Client, error) {
+	platform, err := s.platform()
+	if err != nil {
+		return nil, err
+	}
+	if platform.GetConfig().ComponentAppID != appID {
+		return nil, ErrUnknownAppID
+	}
+	return platform, nil
+}
+
+// authorizer 返回授权方客户端和微信的ticket类型
+func (s *Server) authorizer(tokenType, appID string) (*openplatform.AuthorizerClient, string, error) {
+	platform, err := s.platform()
+	if err != nil {
+		return nil, "", err
+	}
+	ticketType := openplatform.TicketTypeJSAPI
+	if tokenType == TypeWxCardTicket {
+		ticketType = openplatform.TicketTypeWxCard
+	}
+	return openplatform.NewAuthClient(platform).NewAuthorizerClient(appID), ticketType, nil
+}
+
+// writeTokenError 按错误类型返回响应
+func writeTokenError(w http.ResponseWriter, err error) {
+	var limitErr *core.RateLimitError
+	var apiErr *core.Error
+	switch {
+	case errors.As(err, &limitErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(limitErr.RetryAfter.Seconds()+0.999)))
+		writeError(w, http.StatusTooManyRequests, err)
+	case errors.Is(err, ErrUnknownAppID):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrUnsupportedType):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.As(err, &apiErr):
+		writeJSON(w, http.StatusBadGateway, &TokenResponse{APIResponse: core.APIResponse{ErrCode: apiErr.ErrCode, ErrMsg: apiErr.ErrMsg}})
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// writeError 返回错误响应，errcode为HTTP状态码
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &TokenResponse{APIResponse: core.APIResponse{ErrCode: status, ErrMsg: err.Error()}})
+}
+
+// writeJSON 返回JSON响应
+func writeJSON(w http.ResponseWriter, status int, resp *TokenResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
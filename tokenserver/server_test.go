@@ -0,0 +1,104 @@
+package tokenserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/tokenserver"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestProviderAgainstServer(t *testing.T) {
+	wechat := wegotest.NewServer().AddApp("wx_test", "secret").AddApp("wx_other", "secret")
+	defer wechat.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	central := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_test", AppSecret: "secret"},
+		store, wechat.Resolver())
+	other := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_other", AppSecret: "secret"},
+		store, wechat.Resolver())
+	server := tokenserver.NewServer().
+		AddOfficialAccount(central).
+		AddOfficialAccount(other).
+		AddClient("svc", "s3cret", "wx_test")
+
+	mux := http.NewServeMux()
+	mux.Handle("/wego/", server)
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+
+	// 消费方不持有AppSecret
+	provider := tokenserver.NewProvider(httpServer.URL+"/wego", "svc", "s3cret")
+	consumerStore, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumer := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_test"},
+		consumerStore, wechat.Resolver(), provider)
+	ctx := context.Background()
+
+	menus := official_account.NewMenuClient(consumer)
+	menu := &official_account.Menu{Button: []official_account.Button{{Type: "click", Name: "菜单", Key: "K1"}}}
+	if _, err := menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("create menu: %v", err)
+	}
+
+	// token失效后由中控服务器刷新，消费方重试成功
+	wechat.ExpireTokens()
+	if _, err := menus.GetMenu(ctx); err != nil {
+		t.Fatalf("get menu after expiry: %v", err)
+	}
+	if n := len(wechat.Requests("/cgi-bin/token")); n != 2 {
+		t.Fatalf("expected 2 token requests from the central server, got %d", n)
+	}
+
+	// 强制刷新限流
+	token, err := provider.AccessToken(ctx, "wx_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.RefreshAccessToken(ctx, "wx_test", token); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected refresh to be rate limited, got %v", err)
+	}
+
+	// 不在允许列表中的appid
+	if _, err := provider.AccessToken(ctx, "wx_other"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+
+	// 密钥错误
+	if _, err := tokenserver.NewProvider(httpServer.URL+"/wego", "svc", "wrong").AccessToken(ctx, "wx_test"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestServerRejectsReplayedRequest(t *testing.T) {
+	server := tokenserver.NewServer().AddClient("svc", "s3cret", "*")
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/token?appid=wx_unknown", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenserver.SignRequest(req, "svc", "s3cret")
+
+	for _, want := range []int{http.StatusNotFound, http.StatusUnauthorized} {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("expected status %d, got %d", want, resp.StatusCode)
+		}
+	}
+}
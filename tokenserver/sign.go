@@ -0,0 +1,147 @@
+package tokenserver
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// 签名请求头
+const (
+	HeaderClientID  = "X-Wego-Client-Id"
+	HeaderTimestamp = "X-Wego-Timestamp"
+	HeaderNonce     = "X-Wego-Nonce"
+	HeaderSignature = "X-Wego-Signature"
+)
+
+// DefaultSignatureTTL 签名的有效期，请求时间戳与服务器时间相差超过该时长时拒绝
+const DefaultSignatureTTL = 5 * time.Minute
+
+// 签名校验失败的原因
+var (
+	ErrUnknownClient    = errors.New("未知的客户端")
+	ErrSignatureExpired = errors.New("签名已过期")
+	ErrSignatureInvalid = errors.New("签名错误")
+	ErrNonceReused      = errors.New("nonce已使用")
+)
+
+// Sign 计算请求签名
+//
+// 签名内容为 method、path、按key排序的查询参数、timestamp、nonce 以换行连接，
+// 使用客户端密钥计算 HMAC-SHA256 后以十六进制表示
+// @param secret string 客户端密钥
+// @param method string 请求方法
+// @param path string 请求路径
+// @param query string 查询参数，由 url.Values.Encode 生成
+// @param timestamp string 秒级时间戳
+// @param nonce string 随机字符串
+// @return string 签名
+func Sign(secret, method, path, query, timestamp, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + query + "\n" + timestamp + "\n" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest 为请求添加签名请求头
+// @param req *http.Request 请求
+// @param clientID string 客户端ID
+// @param secret string 客户端密钥
+func SignRequest(req *http.Request, clientID, secret string) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := randomNonce()
+	req.Header.Set(HeaderClientID, clientID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(secret, req.Method, req.URL.Path, req.URL.Query().Encode(), timestamp, nonce))
+}
+
+// signingClient 发送前为请求签名的HTTP客户端
+type signingClient struct {
+	clientID string
+	secret   string
+	client   core.HTTPClient
+}
+
+// Do 实现 core.HTTPClient 接口
+func (c *signingClient) Do(req *http.Request) (*http.Response, error) {
+	SignRequest(req, c.clientID, c.secret)
+	return c.client.Do(req)
+}
+
+// nonceCache 记录签名有效期内使用过的nonce，防止请求重放
+type nonceCache struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// use 记录nonce，有效期内已使用过时返回false
+func (c *nonceCache) use(clientID, nonce string, expiresAt, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seen == nil {
+		c.seen = make(map[string]time.Time)
+	}
+	if now.Sub(c.pruned) > time.Minute {
+		for key, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, key)
+			}
+		}
+		c.pruned = now
+	}
+
+	key := clientID + ":" + nonce
+	if exp, ok := c.seen[key]; ok && !now.After(exp) {
+		return false
+	}
+	c.seen[key] = expiresAt
+	return true
+}
+
+// verify 校验请求签名，返回客户端
+func (s *Server) verify(r *http.Request) (*client, error) {
+	s.mu.RLock()
+	c, ok := s.clients[r.Header.Get(HeaderClientID)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownClient
+	}
+
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || nonce == "" {
+		return nil, ErrSignatureInvalid
+	}
+	now := time.Now()
+	signedAt := time.Unix(unix, 0)
+	if now.Sub(signedAt) > s.signatureTTL || signedAt.Sub(now) > s.signatureTTL {
+		return nil, ErrSignatureExpired
+	}
+
+	expected := Sign(c.secret, r.Method, r.URL.Path, r.URL.Query().Encode(), timestamp, nonce)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(HeaderSignature))) {
+		return nil, ErrSignatureInvalid
+	}
+	if !s.nonces.use(c.id, nonce, signedAt.Add(s.signatureTTL), now) {
+		return nil, ErrNonceReused
+	}
+	return c, nil
+}
+
+// randomNonce 生成随机nonce
+func randomNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
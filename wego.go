@@ -31,6 +31,15 @@ type WeGo struct {
 // @param optParams ...any 可选参数，支持以下类型：
 //   - debugger.LoggerInterface: 自定义日志器
 //   - core.HTTPClient: 自定义HTTP客户端
+//   - core.Middleware、[]core.Middleware: 请求中间件
+//   - *core.RateLimiter: 限流器，按appid和接口限制调用频率和每日调用次数
+//   - *core.RetryPolicy: 重试策略
+//   - *core.ExpiryPolicy: 凭证提前过期策略
+//   - *core.CircuitBreaker: 熔断器
+//   - core.EndpointResolver: 接口域名解析器
+//   - core.Metrics: 指标收集器
+//   - core.Tracer: 链路追踪
+//   - core.TokenProvider: access_token来源，token由外部系统维护时使用
 //   - openplatform.EventHandler: 开放平台事件处理器
 //
 // @return *WeGo WeGo实例
@@ -111,6 +120,15 @@ func isConfigParam(param any) bool {
 // @param optParams ...any 可选参数，支持以下类型：
 //   - debugger.LoggerInterface: 自定义日志器
 //   - core.HTTPClient: 自定义HTTP客户端
+//   - core.Middleware、[]core.Middleware: 请求中间件
+//   - *core.RateLimiter: 限流器，按appid和接口限制调用频率和每日调用次数
+//   - *core.RetryPolicy: 重试策略
+//   - *core.ExpiryPolicy: 凭证提前过期策略
+//   - *core.CircuitBreaker: 熔断器
+//   - core.EndpointResolver: 接口域名解析器
+//   - core.Metrics: 指标收集器
+//   - core.Tracer: 链路追踪
+//   - core.TokenProvider: access_token来源，token由外部系统维护时使用
 //   - openplatform.EventHandler: 开放平台事件处理器
 //
 // @return *WeGo WeGo实例
@@ -187,6 +205,30 @@ func (w *WeGo) SetHTTPClient(client core.HTTPClient) {
 	}
 }
 
+// Use 添加请求中间件
+func (w *WeGo) Use(middlewares ...core.Middleware) {
+	if w.OpenPlatformClient != nil {
+		w.OpenPlatformClient.Use(middlewares...)
+	}
+	if w.OfficialAccountClient != nil {
+		w.OfficialAccountClient.Use(middlewares...)
+	}
+}
+
+// NewRefresher 创建覆盖已初始化客户端的主动刷新服务，调用 Start 后在token过期前主动刷新
+// @param onFailure core.RefreshFailureFunc 刷新失败回调，可为nil
+// @return *core.Refresher 主动刷新服务
+func (w *WeGo) NewRefresher(onFailure core.RefreshFailureFunc) *core.Refresher {
+	var sources []core.RefreshSource
+	if w.OpenPlatformClient != nil {
+		sources = append(sources, w.OpenPlatformClient)
+	}
+	if w.OfficialAccountClient != nil {
+		sources = append(sources, w.OfficialAccountClient)
+	}
+	return core.NewRefresher(onFailure, sources...)
+}
+
 // OpenPlatformAuth 返回开放平台授权相关功能
 func (w *WeGo) OpenPlatformAuth() *openplatform.AuthClient {
 	if w.OpenPlatformClient == nil {
@@ -321,6 +363,7 @@ var (
 // 导出常用结构体类型
 type (
 	APIResponse = core.APIResponse
+	APIError    = core.Error
 
 	// 存储相关类型
 	TokenStorage          = storage.TokenStorage
@@ -0,0 +1,724 @@
+package wegotest
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+// maxUploadSize 上传素材的最大内存占用
+const maxUploadSize = 32 << 20
+
+// expiresIn 有效期秒数
+func expiresIn(ttl time.Duration) int {
+	return int(ttl / time.Second)
+}
+
+// checkSecret 校验公众号appid和secret
+func (s *Server) checkSecret(c *call, appID, secret string) bool {
+	expected, ok := s.apps[appID]
+	if !ok {
+		c.fail(core.ErrCodeInvalidParams, "")
+		return false
+	}
+	if secret != expected {
+		c.fail(40125, "")
+		return false
+	}
+	return true
+}
+
+// checkUser 校验接收消息的用户，已通过 AddUser 添加用户时只接受已添加的用户
+func (s *Server) checkUser(c *call, openID string) bool {
+	if _, ok := s.users[openID]; openID == "" || (len(s.users) > 0 && !ok) {
+		c.fail(core.ErrCodeInvalidOpenID, "")
+		return false
+	}
+	return true
+}
+
+// page 计算分页的起止下标
+func page(total, offset, count int) (int, int) {
+	if offset < 0 || offset > total {
+		offset = total
+	}
+	end := offset + count
+	if count <= 0 || end > total {
+		end = total
+	}
+	return offset, end
+}
+
+// handleToken 获取access_token，每次调用都签发新token，之前的token立即失效
+func (s *Server) handleToken(c *call) {
+	query := c.r.URL.Query()
+	if query.Get("grant_type") != core.GrantTypeClientCredential {
+		c.fail(core.ErrCodeInvalidGrantType, "")
+		return
+	}
+	appID := query.Get("appid")
+	if !s.checkSecret(c, appID, query.Get("secret")) {
+		return
+	}
+
+	c.json(map[string]interface{}{
+		"access_token": s.issue("token", kindAccessToken, appID, s.tokenTTL),
+		"expires_in":   expiresIn(s.tokenTTL),
+	})
+}
+
+// handleStableToken 获取稳定版access_token，普通模式下有效期内返回同一token
+func (s *Server) handleStableToken(c *call) {
+	var req struct {
+		GrantType    string `json:"grant_type"`
+		AppID        string `json:"appid"`
+		Secret       string `json:"secret"`
+		ForceRefresh bool   `json:"force_refresh"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if req.GrantType != core.GrantTypeClientCredential {
+		c.fail(core.ErrCodeInvalidGrantType, "")
+		return
+	}
+	if !s.checkSecret(c, req.AppID, req.Secret) {
+		return
+	}
+
+	value, remaining := s.current("stable_token", req.AppID)
+	if value == "" || req.ForceRefresh {
+		value, remaining = s.issue("stable_token", kindAccessToken, req.AppID, s.tokenTTL), s.tokenTTL
+	}
+	c.json(map[string]interface{}{
+		"access_token": value,
+		"expires_in":   expiresIn(remaining),
+	})
+}
+
+// handleAPIDomainIP 获取微信API服务器IP，用于域名解析器探测主域名
+func (s *Server) handleAPIDomainIP(c *call) {
+	c.ok(map[string]interface{}{"ip_list": []string{"127.0.0.1"}})
+}
+
+// handleClearQuota 清空appid的当日调用次数
+func (s *Server) handleClearQuota(c *call) {
+	var req struct {
+		AppID string `json:"appid"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+
+	for key := range s.usage {
+		if key.appID == req.AppID {
+			delete(s.usage, key)
+		}
+	}
+	c.ok(nil)
+}
+
+// handleComponentToken 获取第三方平台component_access_token
+func (s *Server) handleComponentToken(c *call) {
+	var req struct {
+		ComponentAppID        string `json:"component_appid"`
+		ComponentAppSecret    string `json:"component_appsecret"`
+		ComponentVerifyTicket string `json:"component_verify_ticket"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	comp, ok := s.components[req.ComponentAppID]
+	if !ok {
+		c.fail(core.ErrCodeInvalidParams, "")
+		return
+	}
+	if req.ComponentAppSecret != comp.secret {
+		c.fail(40125, "")
+		return
+	}
+	if req.ComponentVerifyTicket == "" || (comp.ticket != "" && req.ComponentVerifyTicket != comp.ticket) {
+		c.fail(61006, "")
+		return
+	}
+
+	c.json(map[string]interface{}{
+		"component_access_token": s.issue("component_token", kindComponentToken, req.ComponentAppID, s.tokenTTL),
+		"expires_in":             expiresIn(s.tokenTTL),
+	})
+}
+
+// handleStartPushTicket 启动验证票据推送，签发新的ticket并调用推送回调
+func (s *Server) handleStartPushTicket(c *call) {
+	var req struct {
+		ComponentAppID  string `json:"component_appid"`
+		ComponentSecret string `json:"component_secret"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	comp, ok := s.components[req.ComponentAppID]
+	if !ok {
+		c.fail(core.ErrCodeInvalidParams, "")
+		return
+	}
+	if req.ComponentSecret != comp.secret {
+		c.fail(40125, "")
+		return
+	}
+
+	comp.ticket = s.nextValue("ticket")
+	if push := s.ticketPush; push != nil {
+		go push(req.ComponentAppID, comp.ticket)
+	}
+	c.ok(nil)
+}
+
+// handlePreAuthCode 获取预授权码
+func (s *Server) handlePreAuthCode(c *call) {
+	c.json(map[string]interface{}{
+		"pre_auth_code": s.issue("pre_auth_code", kindPreAuthCode, c.appID, PreAuthCodeTTL),
+		"expires_in":    expiresIn(PreAuthCodeTTL),
+	})
+}
+
+// handleQueryAuth 使用授权码换取授权方的access_token和refresh_token
+func (s *Server) handleQueryAuth(c *call) {
+	var req struct {
+		AuthorizationCode string `json:"authorization_code"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	authorizerAppID, ok := s.authCodes[req.AuthorizationCode]
+	if !ok {
+		c.fail(61010, "")
+		return
+	}
+	delete(s.authCodes, req.AuthorizationCode)
+
+	refreshToken := s.nextValue("refreshtoken")
+	s.refreshes[refreshToken] = authorizerAppID
+	c.json(map[string]interface{}{
+		"authorization_info": map[string]interface{}{
+			"authorizer_appid":         authorizerAppID,
+			"authorizer_access_token":  s.issue("authorizer", kindAccessToken, authorizerAppID, s.tokenTTL),
+			"expires_in":               expiresIn(s.tokenTTL),
+			"authorizer_refresh_token": refreshToken,
+			"func_info":                s.funcInfo(authorizerAppID),
+		},
+	})
+}
+
+// handleGetAuthorizerInfo 获取授权方的账号信息和授权信息
+func (s *Server) handleGetAuthorizerInfo(c *call) {
+	var req struct {
+		AuthorizerAppID string `json:"authorizer_appid"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if _, ok := s.grants[req.AuthorizerAppID]; !ok {
+		c.fail(61003, "")
+		return
+	}
+
+	c.ok(map[string]interface{}{
+		"authorizer_info": map[string]interface{}{
+			"nick_name":         "授权方" + req.AuthorizerAppID,
+			"user_name":         "gh_" + req.AuthorizerAppID,
+			"service_type_info": map[string]interface{}{"id": 2},
+			"verify_type_info":  map[string]interface{}{"id": 0},
+		},
+		"authorization_info": map[string]interface{}{
+			"authorizer_appid": req.AuthorizerAppID,
+			"func_info":        s.funcInfo(req.AuthorizerAppID),
+		},
+	})
+}
+
+// funcInfo 返回授权方授权的权限集
+func (s *Server) funcInfo(authorizerAppID string) []interface{} {
+	funcInfo := make([]interface{}, 0, len(s.grants[authorizerAppID]))
+	for _, id := range s.grants[authorizerAppID] {
+		funcInfo = append(funcInfo, map[string]interface{}{"funcscope_category": map[string]interface{}{"id": id}})
+	}
+	return funcInfo
+}
+
+// handleAuthorizerToken 使用refresh_token刷新授权方的access_token
+func (s *Server) handleAuthorizerToken(c *call) {
+	var req struct {
+		AuthorizerAppID        string `json:"authorizer_appid"`
+		AuthorizerRefreshToken string `json:"authorizer_refresh_token"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if appID, ok := s.refreshes[req.AuthorizerRefreshToken]; !ok || appID != req.AuthorizerAppID {
+		c.fail(61023, "")
+		return
+	}
+
+	c.json(map[string]interface{}{
+		"authorizer_access_token":  s.issue("authorizer", kindAccessToken, req.AuthorizerAppID, s.tokenTTL),
+		"expires_in":               expiresIn(s.tokenTTL),
+		"authorizer_refresh_token": req.AuthorizerRefreshToken,
+	})
+}
+
+// handleMenuCreate 创建自定义菜单
+func (s *Server) handleMenuCreate(c *call) {
+	var req struct {
+		Button []json.RawMessage `json:"button"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if len(req.Button) == 0 || len(req.Button) > 3 {
+		c.fail(40016, "")
+		return
+	}
+
+	s.menus[c.appID] = append(json.RawMessage(nil), c.body...)
+	c.ok(nil)
+}
+
+// handleMenuGet 查询自定义菜单
+func (s *Server) handleMenuGet(c *call) {
+	menu, ok := s.menus[c.appID]
+	if !ok {
+		c.fail(46003, "")
+		return
+	}
+	c.ok(map[string]interface{}{"menu": menu})
+}
+
+// handleMenuDelete 删除自定义菜单
+func (s *Server) handleMenuDelete(c *call) {
+	delete(s.menus, c.appID)
+	c.ok(nil)
+}
+
+// handleTemplateSend 发送模板消息
+func (s *Server) handleTemplateSend(c *call) {
+	var req struct {
+		ToUser     string `json:"touser"`
+		TemplateID string `json:"template_id"`
+	}
+	if !c.decode(&req) || !s.checkUser(c, req.ToUser) {
+		return
+	}
+	if req.TemplateID == "" {
+		c.fail(40037, "")
+		return
+	}
+
+	s.msgID++
+	c.ok(map[string]interface{}{"msgid": s.msgID})
+}
+
+// handleCustomSend 发送客服消息
+func (s *Server) handleCustomSend(c *call) {
+	var req struct {
+		ToUser string `json:"touser"`
+	}
+	if !c.decode(&req) || !s.checkUser(c, req.ToUser) {
+		return
+	}
+	c.ok(nil)
+}
+
+// uploadType 校验上传素材的类型
+func uploadType(c *call) (string, bool) {
+	mediaType := c.r.URL.Query().Get("type")
+	switch mediaType {
+	case "image", "voice", "video", "thumb":
+		return mediaType, true
+	default:
+		c.fail(core.ErrCodeInvalidMediaType, "")
+		return "", false
+	}
+}
+
+// readUpload 读取上传的素材文件
+func (s *Server) readUpload(c *call, mediaType string, permanent bool) (*media, string, bool) {
+	if err := c.r.ParseMultipartForm(maxUploadSize); err != nil {
+		c.fail(44002, "")
+		return nil, "", false
+	}
+	file, header, err := c.r.FormFile("media")
+	if err != nil {
+		c.fail(44002, "")
+		return nil, "", false
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.fail(core.ErrCodeSystemBusy, "")
+		return nil, "", false
+	}
+
+	m := &media{
+		appID:     c.appID,
+		mediaType: mediaType,
+		name:      header.Filename,
+		data:      data,
+		permanent: permanent,
+		createdAt: s.now(),
+	}
+	if mediaType == "video" && permanent {
+		var description struct {
+			Title        string `json:"title"`
+			Introduction string `json:"introduction"`
+		}
+		_ = json.Unmarshal([]byte(c.r.FormValue("description")), &description)
+		m.title, m.description = description.Title, description.Introduction
+	}
+
+	mediaID := s.nextValue("media")
+	m.seq = s.seq
+	s.media[mediaID] = m
+	return m, mediaID, true
+}
+
+// handleMediaUpload 上传临时素材
+func (s *Server) handleMediaUpload(c *call) {
+	mediaType, ok := uploadType(c)
+	if !ok {
+		return
+	}
+	m, mediaID, ok := s.readUpload(c, mediaType, false)
+	if !ok {
+		return
+	}
+	c.json(map[string]interface{}{
+		"type":       m.mediaType,
+		"media_id":   mediaID,
+		"created_at": m.createdAt.Unix(),
+	})
+}
+
+// handleMediaUploadImg 上传图文消息内的图片
+func (s *Server) handleMediaUploadImg(c *call) {
+	_, mediaID, ok := s.readUpload(c, "image", false)
+	if !ok {
+		return
+	}
+	c.ok(map[string]interface{}{"url": s.URL + mediaDownloadPath + mediaID})
+}
+
+// handleMaterialAdd 新增永久素材
+func (s *Server) handleMaterialAdd(c *call) {
+	mediaType, ok := uploadType(c)
+	if !ok {
+		return
+	}
+	m, mediaID, ok := s.readUpload(c, mediaType, true)
+	if !ok {
+		return
+	}
+	fields := map[string]interface{}{"media_id": mediaID}
+	if m.mediaType == "image" {
+		fields["url"] = s.URL + mediaDownloadPath + mediaID
+	}
+	c.ok(fields)
+}
+
+// findMedia 查找当前appid的素材
+func (s *Server) findMedia(c *call, mediaID string, permanent bool) (*media, bool) {
+	m, ok := s.media[mediaID]
+	if !ok || m.appID != c.appID || m.permanent != permanent {
+		c.fail(40007, "")
+		return nil, false
+	}
+	return m, true
+}
+
+// writeMedia 返回素材文件内容
+func (s *Server) writeMedia(c *call, m *media) {
+	c.w.Header().Set("Content-Type", http.DetectContentType(m.data))
+	c.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": m.name}))
+	_, _ = c.w.Write(m.data)
+}
+
+// handleMediaGet 获取临时素材，视频素材返回video_url
+func (s *Server) handleMediaGet(c *call) {
+	mediaID := c.r.URL.Query().Get("media_id")
+	m, ok := s.findMedia(c, mediaID, false)
+	if !ok {
+		return
+	}
+	if m.mediaType == "video" {
+		c.json(map[string]interface{}{"video_url": s.URL + mediaDownloadPath + mediaID})
+		return
+	}
+	s.writeMedia(c, m)
+}
+
+// handleMaterialGet 获取永久素材，视频素材返回down_url
+func (s *Server) handleMaterialGet(c *call) {
+	var req struct {
+		MediaID string `json:"media_id"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	m, ok := s.findMedia(c, req.MediaID, true)
+	if !ok {
+		return
+	}
+	if m.mediaType == "video" {
+		c.json(map[string]interface{}{
+			"title":       m.title,
+			"description": m.description,
+			"down_url":    s.URL + mediaDownloadPath + req.MediaID,
+		})
+		return
+	}
+	s.writeMedia(c, m)
+}
+
+// handleMaterialDelete 删除永久素材
+func (s *Server) handleMaterialDelete(c *call) {
+	var req struct {
+		MediaID string `json:"media_id"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if _, ok := s.findMedia(c, req.MediaID, true); !ok {
+		return
+	}
+	delete(s.media, req.MediaID)
+	c.ok(nil)
+}
+
+// permanentMedia 返回当前appid指定类型的永久素材，按上传顺序排列
+func (s *Server) permanentMedia(appID, mediaType string) []string {
+	var ids []string
+	for id, m := range s.media {
+		if m.permanent && m.appID == appID && m.mediaType == mediaType {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.media[ids[i]].seq < s.media[ids[j]].seq })
+	return ids
+}
+
+// handleMaterialCount 获取永久素材总数
+func (s *Server) handleMaterialCount(c *call) {
+	c.ok(map[string]interface{}{
+		"voice_count": len(s.permanentMedia(c.appID, "voice")),
+		"video_count": len(s.permanentMedia(c.appID, "video")),
+		"image_count": len(s.permanentMedia(c.appID, "image")),
+		"news_count":  0,
+	})
+}
+
+// handleMaterialBatchGet 批量获取永久素材列表，不支持图文素材
+func (s *Server) handleMaterialBatchGet(c *call) {
+	var req struct {
+		Type   string `json:"type"`
+		Offset int    `json:"offset"`
+		Count  int    `json:"count"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+
+	ids := s.permanentMedia(c.appID, req.Type)
+	start, end := page(len(ids), req.Offset, req.Count)
+	items := make([]map[string]interface{}, 0, end-start)
+	for _, id := range ids[start:end] {
+		m := s.media[id]
+		item := map[string]interface{}{
+			"media_id":    id,
+			"name":        m.name,
+			"update_time": m.createdAt.Unix(),
+		}
+		if m.mediaType == "image" {
+			item["url"] = s.URL + mediaDownloadPath + id
+		}
+		items = append(items, item)
+	}
+	c.ok(map[string]interface{}{
+		"total_count": len(ids),
+		"item_count":  len(items),
+		"item":        items,
+	})
+}
+
+// findDraft 查找当前appid的草稿
+func (s *Server) findDraft(c *call, mediaID string) (*draft, bool) {
+	d, ok := s.drafts[mediaID]
+	if !ok || d.appID != c.appID {
+		c.fail(40007, "")
+		return nil, false
+	}
+	return d, true
+}
+
+// appDrafts 返回当前appid的草稿，按创建顺序排列
+func (s *Server) appDrafts(appID string) []string {
+	var ids []string
+	for id, d := range s.drafts {
+		if d.appID == appID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.drafts[ids[i]].seq < s.drafts[ids[j]].seq })
+	return ids
+}
+
+// handleDraftAdd 新建草稿
+func (s *Server) handleDraftAdd(c *call) {
+	var req struct {
+		Articles []map[string]interface{} `json:"articles"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if len(req.Articles) == 0 || len(req.Articles) > 8 {
+		c.fail(44003, "")
+		return
+	}
+
+	mediaID := s.nextValue("draft")
+	s.drafts[mediaID] = &draft{appID: c.appID, articles: req.Articles, updatedAt: s.now(), seq: s.seq}
+	c.ok(map[string]interface{}{"media_id": mediaID})
+}
+
+// handleDraftGet 获取草稿
+func (s *Server) handleDraftGet(c *call) {
+	var req struct {
+		MediaID string `json:"media_id"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	d, ok := s.findDraft(c, req.MediaID)
+	if !ok {
+		return
+	}
+	c.ok(map[string]interface{}{"news_item": d.articles})
+}
+
+// handleDraftDelete 删除草稿
+func (s *Server) handleDraftDelete(c *call) {
+	var req struct {
+		MediaID string `json:"media_id"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	if _, ok := s.findDraft(c, req.MediaID); !ok {
+		return
+	}
+	delete(s.drafts, req.MediaID)
+	c.ok(nil)
+}
+
+// handleDraftCount 获取草稿总数
+func (s *Server) handleDraftCount(c *call) {
+	c.ok(map[string]interface{}{"total_count": len(s.appDrafts(c.appID))})
+}
+
+// handleDraftBatchGet 批量获取草稿列表
+func (s *Server) handleDraftBatchGet(c *call) {
+	var req struct {
+		Offset    int `json:"offset"`
+		Count     int `json:"count"`
+		NoContent int `json:"no_content"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+
+	ids := s.appDrafts(c.appID)
+	start, end := page(len(ids), req.Offset, req.Count)
+	items := make([]map[string]interface{}, 0, end-start)
+	for _, id := range ids[start:end] {
+		d := s.drafts[id]
+		articles := d.articles
+		if req.NoContent == 1 {
+			articles = make([]map[string]interface{}, len(d.articles))
+			for i, article := range d.articles {
+				articles[i] = make(map[string]interface{}, len(article))
+				for k, v := range article {
+					if k != "content" {
+						articles[i][k] = v
+					}
+				}
+			}
+		}
+		items = append(items, map[string]interface{}{
+			"media_id":    id,
+			"content":     map[string]interface{}{"news_item": articles},
+			"update_time": d.updatedAt.Unix(),
+		})
+	}
+	c.ok(map[string]interface{}{
+		"total_count": len(ids),
+		"item_count":  len(items),
+		"item":        items,
+	})
+}
+
+// handleDraftUpdate 修改草稿中的一篇文章
+func (s *Server) handleDraftUpdate(c *call) {
+	var req struct {
+		MediaID  string                 `json:"media_id"`
+		Index    int                    `json:"index"`
+		Articles map[string]interface{} `json:"articles"`
+	}
+	if !c.decode(&req) {
+		return
+	}
+	d, ok := s.findDraft(c, req.MediaID)
+	if !ok {
+		return
+	}
+	if req.Index < 0 || req.Index >= len(d.articles) {
+		c.fail(core.ErrCodeInvalidParams, "invalid index")
+		return
+	}
+
+	d.articles[req.Index] = req.Articles
+	d.updatedAt = s.now()
+	c.ok(nil)
+}
+
+// handleUserInfo 获取用户基本信息
+func (s *Server) handleUserInfo(c *call) {
+	user, ok := s.users[c.r.URL.Query().Get("openid")]
+	if !ok {
+		c.fail(core.ErrCodeInvalidOpenID, "")
+		return
+	}
+	c.json(user)
+}
+
+// handleGetTicket 获取jsapi_ticket或卡券api_ticket，有效期内返回同一ticket
+func (s *Server) handleGetTicket(c *call) {
+	ticketType := c.r.URL.Query().Get("type")
+	if ticketType != "jsapi" && ticketType != "wx_card" {
+		c.fail(core.ErrCodeInvalidParams, "invalid ticket type")
+		return
+	}
+
+	channel := "ticket_" + ticketType
+	value, remaining := s.current(channel, c.appID)
+	if value == "" {
+		value, remaining = s.issue(channel, kindJSAPITicket, c.appID, s.tokenTTL), s.tokenTTL
+	}
+	c.ok(map[string]interface{}{
+		"ticket":     value,
+		"expires_in": expiresIn(remaining),
+	})
+}
@@ -0,0 +1,593 @@
+// Package wegotest 提供进程内的微信接口模拟服务器，用于不访问网络的集成测试
+//
+// 模拟服务器是有状态的：签发的token会过期，被新token替换的旧token返回40001，
+// 接口每日调用次数超出额度返回45009，测试还可以为任意接口注入错误码。
+// 配合 core.DomainResolver 将客户端的请求指向模拟服务器：
+//
+//	server := wegotest.NewServer().AddApp("wx123", "secret")
+//	defer server.Close()
+//	client := official_account.NewMPClientWithStorage(config, store, server.Resolver())
+package wegotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcbowen/wego/core"
+)
+
+const (
+	DefaultTokenTTL   = 2 * time.Hour    // access_token、component_access_token和jsapi_ticket的默认有效期
+	PreAuthCodeTTL    = 30 * time.Minute // 预授权码有效期
+	mediaDownloadPath = "/wegotest/media/"
+)
+
+// quotaLocation 微信接口每日调用次数在北京时间0点重置
+var quotaLocation = time.FixedZone("CST", 8*3600)
+
+// 模拟服务器返回的错误信息
+var errMessages = map[int]string{
+	core.ErrCodeSystemBusy:         "system error",
+	core.ErrCodeInvalidCredential:  "invalid credential, access_token is invalid or not latest",
+	core.ErrCodeInvalidGrantType:   "invalid grant_type",
+	core.ErrCodeInvalidOpenID:      "invalid openid",
+	core.ErrCodeInvalidMediaType:   "invalid media type",
+	core.ErrCodeInvalidParams:      "invalid appid",
+	core.ErrCodeAccessTokenExpired: "access_token expired",
+	core.ErrCodeAPIQuotaExceeded:   "reach max api daily quota limit",
+	40007:                          "invalid media_id",
+	40016:                          "invalid button size",
+	40037:                          "invalid template_id",
+	40125:                          "invalid appsecret",
+	41001:                          "access_token missing",
+	44002:                          "empty post data",
+	44003:                          "empty news data",
+	46003:                          "menu no exist",
+	61006:                          "component ticket is invalid",
+	61010:                          "code is expired",
+	61003:                          "component is not authorized by this account",
+	61023:                          "refresh_token is invalid",
+}
+
+// token类型
+const (
+	kindAccessToken    = "access_token"
+	kindComponentToken = "component_access_token"
+	kindPreAuthCode    = "pre_auth_code"
+	kindJSAPITicket    = "jsapi_ticket"
+)
+
+// Request 模拟服务器收到的请求，用于测试断言
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte // multipart请求体不记录
+	AppID  string // 调用方appid，未通过token校验时为空
+}
+
+// Decode 将请求体解析到 v
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server 微信接口模拟服务器
+type Server struct {
+	URL    string // 服务器地址，如 http://127.0.0.1:52341
+	server *httptest.Server
+
+	mu         sync.Mutex
+	offset     time.Duration // Advance 推进的时间
+	tokenTTL   time.Duration
+	seq        int
+	apps       map[string]string                   // appid → secret
+	components map[string]*component               // 第三方平台appid → 第三方平台
+	tokens     map[string]*token                   // token值 → token
+	latest     map[string]string                   // 签发渠道和appid → 最新签发的token值
+	authCodes  map[string]string                   // 授权码 → 授权方appid
+	refreshes  map[string]string                   // authorizer_refresh_token → 授权方appid
+	grants     map[string][]int                    // 授权方appid → 授权的权限集id
+	ticketPush func(componentAppID, ticket string) // 推送验证票据的回调
+	quotas     map[string]int                      // 接口路径 → 每日调用上限
+	usage      map[usageKey]int                    // 已调用次数
+	faults     map[string][]fault                  // 接口路径 → 待返回的错误
+	requests   []Request                           // 收到的请求
+	users      map[string]map[string]interface{}   // openid → 用户信息
+	menus      map[string]json.RawMessage          // appid → 自定义菜单
+	media      map[string]*media                   // media_id → 素材
+	drafts     map[string]*draft                   // media_id → 草稿
+	msgID      int64
+}
+
+// component 第三方平台
+type component struct {
+	secret string
+	ticket string // 为空时接受任意非空的component_verify_ticket
+}
+
+// token 模拟服务器签发的token
+type token struct {
+	kind      string
+	appID     string
+	expiresAt time.Time
+	revoked   bool // 已被新签发的token替换
+}
+
+// fault 注入的错误
+type fault struct {
+	errCode int
+	errMsg  string
+}
+
+// media 素材
+type media struct {
+	appID       string
+	mediaType   string
+	name        string
+	data        []byte
+	title       string
+	description string
+	permanent   bool
+	createdAt   time.Time
+	seq         int
+}
+
+// draft 草稿
+type draft struct {
+	appID     string
+	articles  []map[string]interface{}
+	updatedAt time.Time
+	seq       int
+}
+
+// usageKey 每日调用次数的统计维度
+type usageKey struct {
+	day   string
+	appID string
+	path  string
+}
+
+// NewServer 启动模拟服务器，使用完毕后需调用 Close
+// 各接口的每日调用上限默认取 core.DefaultRateLimits，可通过 SetQuota 修改
+// @return *Server 模拟服务器
+func NewServer() *Server {
+	s := &Server{
+		tokenTTL:   DefaultTokenTTL,
+		apps:       make(map[string]string),
+		components: make(map[string]*component),
+		tokens:     make(map[string]*token),
+		latest:     make(map[string]string),
+		authCodes:  make(map[string]string),
+		refreshes:  make(map[string]string),
+		grants:     make(map[string][]int),
+		quotas:     make(map[string]int),
+		usage:      make(map[usageKey]int),
+		faults:     make(map[string][]fault),
+		users:      make(map[string]map[string]interface{}),
+		menus:      make(map[string]json.RawMessage),
+		media:      make(map[string]*media),
+		drafts:     make(map[string]*draft),
+	}
+	for path, limit := range core.DefaultRateLimits {
+		if limit.Daily > 0 {
+			s.quotas[path] = limit.Daily
+		}
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close 关闭模拟服务器
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Resolver 返回将 core.BaseAPIURL 的请求改写到模拟服务器的域名解析器
+// 作为客户端构造函数的可选参数传入，或通过 SetEndpointResolver 设置
+func (s *Server) Resolver() *core.DomainResolver {
+	return core.NewDomainResolver(s.URL)
+}
+
+// AddApp 注册公众号，注册后可通过 /cgi-bin/token 和 /cgi-bin/stable_token 获取access_token
+func (s *Server) AddApp(appID, secret string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apps[appID] = secret
+	return s
+}
+
+// AddComponent 注册第三方平台
+func (s *Server) AddComponent(componentAppID, secret string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.components[componentAppID] = &component{secret: secret}
+	return s
+}
+
+// SetVerifyTicket 设置第三方平台当前有效的component_verify_ticket
+// 未设置时接受任意非空的ticket
+func (s *Server) SetVerifyTicket(componentAppID, ticket string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.components[componentAppID]; ok {
+		c.ticket = ticket
+	}
+	return s
+}
+
+// OnTicketPush 设置验证票据推送回调，模拟微信推送到第三方平台的消息接收URL
+// 调用 api_start_push_ticket 后在新的goroutine中以新签发的ticket调用回调，新ticket成为当前有效的ticket
+func (s *Server) OnTicketPush(fn func(componentAppID, ticket string)) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ticketPush = fn
+	return s
+}
+
+// Authorize 模拟公众号完成授权，返回可用于 api_query_auth 的授权码
+// 授权码只能使用一次，再次调用时按新的权限集更新授权
+// @param authorizerAppID string 授权方appid
+// @param funcScopeIDs ...int 授权给第三方平台的权限集id
+func (s *Server) Authorize(authorizerAppID string, funcScopeIDs ...int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := s.nextValue("queryauthcode")
+	s.authCodes[code] = authorizerAppID
+	s.grants[authorizerAppID] = append([]int{}, funcScopeIDs...)
+	return code
+}
+
+// Unauthorize 模拟公众号取消授权，授权方的access_token和refresh_token失效
+func (s *Server) Unauthorize(authorizerAppID string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.grants, authorizerAppID)
+	for refreshToken, appID := range s.refreshes {
+		if appID == authorizerAppID {
+			delete(s.refreshes, refreshToken)
+		}
+	}
+	if t, ok := s.tokens[s.latest["authorizer:"+authorizerAppID]]; ok {
+		t.revoked = true
+	}
+	return s
+}
+
+// AddUser 添加用户，info 为 /cgi-bin/user/info 返回的字段，如 nickname、unionid
+func (s *Server) AddUser(openID string, info map[string]interface{}) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := map[string]interface{}{"subscribe": 1, "openid": openID}
+	for k, v := range info {
+		user[k] = v
+	}
+	s.users[openID] = user
+	return s
+}
+
+// SetTokenTTL 设置之后签发的token和ticket的有效期
+func (s *Server) SetTokenTTL(ttl time.Duration) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokenTTL = ttl
+	return s
+}
+
+// SetQuota 设置接口每个appid的每日调用上限，为0表示不限制
+// @param path string 接口路径，如 /cgi-bin/menu/create
+// @param daily int 每日调用上限
+func (s *Server) SetQuota(path string, daily int) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if daily > 0 {
+		s.quotas[path] = daily
+	} else {
+		delete(s.quotas, path)
+	}
+	return s
+}
+
+// ClearQuota 清空所有appid的当日调用次数
+func (s *Server) ClearQuota() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usage = make(map[usageKey]int)
+}
+
+// InjectError 让接口的下一次调用返回指定错误码，多次调用按顺序依次返回
+// 注入的错误在校验token和额度之前返回，不计入调用次数
+// @param path string 接口路径，如 /cgi-bin/message/template/send
+// @param errCode int 错误码
+// @param errMsg string 错误信息，为空时使用默认错误信息
+func (s *Server) InjectError(path string, errCode int, errMsg string) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[path] = append(s.faults[path], fault{errCode: errCode, errMsg: errMsg})
+	return s
+}
+
+// Advance 推进模拟服务器的时钟，用于测试token过期和每日额度重置
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset += d
+}
+
+// ExpireTokens 使所有已签发的token、预授权码和ticket立即过期
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, t := range s.tokens {
+		t.expiresAt = now
+	}
+}
+
+// Requests 返回收到的请求，path 为空时返回全部请求
+func (s *Server) Requests(path string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []Request
+	for _, r := range s.requests {
+		if path == "" || r.Path == path {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// now 返回模拟服务器的当前时间
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// nextValue 生成唯一的token、授权码或media_id
+func (s *Server) nextValue(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%d", prefix, s.seq)
+}
+
+// issue 签发新token，同一签发渠道之前签发的token失效
+// @param channel string 签发渠道，如 token、stable_token，不同渠道的token互不影响
+func (s *Server) issue(channel, kind, appID string, ttl time.Duration) string {
+	key := channel + ":" + appID
+	if old, ok := s.tokens[s.latest[key]]; ok {
+		old.revoked = true
+	}
+
+	value := s.nextValue(kind)
+	s.tokens[value] = &token{kind: kind, appID: appID, expiresAt: s.now().Add(ttl)}
+	s.latest[key] = value
+	return value
+}
+
+// current 返回签发渠道当前有效的token和剩余有效期，没有时返回空字符串
+func (s *Server) current(channel, appID string) (string, time.Duration) {
+	value := s.latest[channel+":"+appID]
+	t, ok := s.tokens[value]
+	if !ok || t.revoked || !s.now().Before(t.expiresAt) {
+		return "", 0
+	}
+	return value, t.expiresAt.Sub(s.now())
+}
+
+// verify 校验token，返回token所属的appid
+func (s *Server) verify(kind, value string) (string, int) {
+	if value == "" {
+		return "", 41001
+	}
+	t, ok := s.tokens[value]
+	if !ok || t.kind != kind || t.revoked {
+		return "", core.ErrCodeInvalidCredential
+	}
+	if !s.now().Before(t.expiresAt) {
+		return "", core.ErrCodeAccessTokenExpired
+	}
+	return t.appID, 0
+}
+
+// call 一次接口调用
+type call struct {
+	w     http.ResponseWriter
+	r     *http.Request
+	body  []byte
+	appID string
+}
+
+// decode 解析JSON请求体，失败时返回44002
+func (c *call) decode(v interface{}) bool {
+	if len(c.body) == 0 || json.Unmarshal(c.body, v) != nil {
+		c.fail(44002, "")
+		return false
+	}
+	return true
+}
+
+// ok 返回成功响应，fields 为除errcode、errmsg外的字段
+func (c *call) ok(fields map[string]interface{}) {
+	resp := map[string]interface{}{"errcode": 0, "errmsg": "ok"}
+	for k, v := range fields {
+		resp[k] = v
+	}
+	c.json(resp)
+}
+
+// fail 返回错误响应
+func (c *call) fail(errCode int, errMsg string) {
+	if errMsg == "" {
+		errMsg = errMessages[errCode]
+	}
+	c.json(map[string]interface{}{
+		"errcode": errCode,
+		"errmsg":  fmt.Sprintf("%s rid: wegotest-%d", errMsg, time.Now().UnixNano()),
+	})
+}
+
+// json 返回JSON响应
+func (c *call) json(v interface{}) {
+	c.w.Header().Set("Content-Type", "application/json; encoding=utf-8")
+	_ = json.NewEncoder(c.w).Encode(v)
+}
+
+// 接口的鉴权方式
+const (
+	authNone      = iota // 无需token
+	authAccess           // 需要access_token，公众号和授权方token均可
+	authComponent        // 需要component_access_token
+)
+
+// route 模拟的接口
+type route struct {
+	auth    int
+	handler func(s *Server, c *call)
+}
+
+// routes 模拟的接口，按路径匹配
+var routes = map[string]route{
+	"/cgi-bin/token":                             {authNone, (*Server).handleToken},
+	"/cgi-bin/stable_token":                      {authNone, (*Server).handleStableToken},
+	"/cgi-bin/get_api_domain_ip":                 {authAccess, (*Server).handleAPIDomainIP},
+	"/cgi-bin/clear_quota":                       {authAccess, (*Server).handleClearQuota},
+	"/cgi-bin/component/api_component_token":     {authNone, (*Server).handleComponentToken},
+	"/cgi-bin/component/api_create_preauthcode":  {authComponent, (*Server).handlePreAuthCode},
+	"/cgi-bin/component/api_query_auth":          {authComponent, (*Server).handleQueryAuth},
+	"/cgi-bin/component/api_authorizer_token":    {authComponent, (*Server).handleAuthorizerToken},
+	"/cgi-bin/component/api_get_authorizer_info": {authComponent, (*Server).handleGetAuthorizerInfo},
+	"/cgi-bin/component/api_start_push_ticket":   {authNone, (*Server).handleStartPushTicket},
+	"/cgi-bin/menu/create":                       {authAccess, (*Server).handleMenuCreate},
+	"/cgi-bin/menu/get":                          {authAccess, (*Server).handleMenuGet},
+	"/cgi-bin/menu/delete":                       {authAccess, (*Server).handleMenuDelete},
+	"/cgi-bin/message/template/send":             {authAccess, (*Server).handleTemplateSend},
+	"/cgi-bin/message/custom/send":               {authAccess, (*Server).handleCustomSend},
+	"/cgi-bin/media/upload":                      {authAccess, (*Server).handleMediaUpload},
+	"/cgi-bin/media/uploadimg":                   {authAccess, (*Server).handleMediaUploadImg},
+	"/cgi-bin/media/get":                         {authAccess, (*Server).handleMediaGet},
+	"/cgi-bin/material/add_material":             {authAccess, (*Server).handleMaterialAdd},
+	"/cgi-bin/material/get_material":             {authAccess, (*Server).handleMaterialGet},
+	"/cgi-bin/material/del_material":             {authAccess, (*Server).handleMaterialDelete},
+	"/cgi-bin/material/get_materialcount":        {authAccess, (*Server).handleMaterialCount},
+	"/cgi-bin/material/batchget_material":        {authAccess, (*Server).handleMaterialBatchGet},
+	"/cgi-bin/draft/add":                         {authAccess, (*Server).handleDraftAdd},
+	"/cgi-bin/draft/get":                         {authAccess, (*Server).handleDraftGet},
+	"/cgi-bin/draft/delete":                      {authAccess, (*Server).handleDraftDelete},
+	"/cgi-bin/draft/count":                       {authAccess, (*Server).handleDraftCount},
+	"/cgi-bin/draft/batchget":                    {authAccess, (*Server).handleDraftBatchGet},
+	"/cgi-bin/draft/update":                      {authAccess, (*Server).handleDraftUpdate},
+	"/cgi-bin/user/info":                         {authAccess, (*Server).handleUserInfo},
+	"/cgi-bin/ticket/getticket":                  {authAccess, (*Server).handleGetTicket},
+}
+
+// serveHTTP 记录请求，依次处理注入的错误、token校验和调用额度，再交给接口处理
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); !strings.HasPrefix(mediaType, "multipart/") {
+		body, _ = io.ReadAll(r.Body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, mediaDownloadPath) {
+		s.serveMedia(w, strings.TrimPrefix(r.URL.Path, mediaDownloadPath))
+		return
+	}
+
+	c := &call{w: w, r: r, body: body}
+	index := len(s.requests)
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	})
+
+	rt, ok := routes[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if faults := s.faults[r.URL.Path]; len(faults) > 0 {
+		s.faults[r.URL.Path] = faults[1:]
+		c.fail(faults[0].errCode, faults[0].errMsg)
+		return
+	}
+
+	var errCode int
+	switch rt.auth {
+	case authNone:
+		c.appID = requestAppID(r, body)
+	case authAccess:
+		c.appID, errCode = s.verify(kindAccessToken, r.URL.Query().Get("access_token"))
+	case authComponent:
+		c.appID, errCode = s.verify(kindComponentToken, r.URL.Query().Get("component_access_token"))
+	}
+	if errCode != 0 {
+		c.fail(errCode, "")
+		return
+	}
+	s.requests[index].AppID = c.appID
+
+	if limit, ok := s.quotas[r.URL.Path]; ok {
+		key := usageKey{day: s.now().In(quotaLocation).Format("20060102"), appID: c.appID, path: r.URL.Path}
+		if s.usage[key] >= limit {
+			c.fail(core.ErrCodeAPIQuotaExceeded, "")
+			return
+		}
+		s.usage[key]++
+	}
+
+	rt.handler(s, c)
+}
+
+// requestAppID 获取无需token的接口的调用方appid
+func requestAppID(r *http.Request, body []byte) string {
+	if appID := r.URL.Query().Get("appid"); appID != "" {
+		return appID
+	}
+	var req struct {
+		AppID          string `json:"appid"`
+		ComponentAppID string `json:"component_appid"`
+	}
+	_ = json.Unmarshal(body, &req)
+	if req.AppID != "" {
+		return req.AppID
+	}
+	return req.ComponentAppID
+}
+
+// serveMedia 下载素材文件，用于视频素材的down_url和图片的url
+func (s *Server) serveMedia(w http.ResponseWriter, mediaID string) {
+	m, ok := s.media[mediaID]
+	if !ok {
+		http.Error(w, "media not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(m.data))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": m.name}))
+	_, _ = io.Copy(w, bytes.NewReader(m.data))
+}
@@ -0,0 +1,73 @@
+package wegotest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jcbowen/wego/core"
+	"github.com/jcbowen/wego/official_account"
+	"github.com/jcbowen/wego/storage"
+	"github.com/jcbowen/wego/wegotest"
+)
+
+func TestOfficialAccountAgainstFakeServer(t *testing.T) {
+	server := wegotest.NewServer().
+		AddApp("wx_test", "secret").
+		AddUser("openid_1", map[string]interface{}{"nickname": "测试用户"})
+	defer server.Close()
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := official_account.NewMPClientWithStorage(&official_account.Config{AppID: "wx_test", AppSecret: "secret"},
+		store, server.Resolver())
+	ctx := context.Background()
+
+	menus := official_account.NewMenuClient(client)
+	menu := &official_account.Menu{Button: []official_account.Button{{Type: "click", Name: "菜单", Key: "K1"}}}
+	if _, err := menus.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("create menu: %v", err)
+	}
+
+	// 其他实例重新获取token后，缓存的token失效，客户端应自动刷新并重试
+	resp, err := http.Get(server.URL + "/cgi-bin/token?grant_type=client_credential&appid=wx_test&secret=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	got, err := menus.GetMenu(ctx)
+	if err != nil {
+		t.Fatalf("get menu with stale token: %v", err)
+	}
+	if len(got.Menu.Button) != 1 || got.Menu.Button[0].Key != "K1" {
+		t.Fatalf("unexpected menu: %+v", got.Menu)
+	}
+	if n := len(server.Requests("/cgi-bin/token")); n != 3 {
+		t.Fatalf("expected 3 token requests, got %d", n)
+	}
+
+	// 注入的错误码和超出额度均返回 *core.Error
+	templates := official_account.NewTemplateClient(client)
+	message := &official_account.TemplateMessageRequest{
+		ToUser:     "openid_1",
+		TemplateID: "tpl",
+		Data:       map[string]official_account.TemplateMessageData{"thing1": {Value: "内容"}},
+	}
+	server.SetQuota("/cgi-bin/message/template/send", 1).
+		InjectError("/cgi-bin/message/template/send", 43004, "require subscribe")
+
+	for _, want := range []int{43004, 0, core.ErrCodeAPIQuotaExceeded} {
+		_, err := templates.SendTemplateMessage(ctx, message)
+		var apiErr *core.Error
+		switch {
+		case want == 0 && err != nil:
+			t.Fatalf("send template: %v", err)
+		case want != 0 && (!errors.As(err, &apiErr) || apiErr.ErrCode != want):
+			t.Fatalf("expected errcode %d, got %v", want, err)
+		}
+	}
+}